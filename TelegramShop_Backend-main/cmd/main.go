@@ -15,6 +15,11 @@
 
 // @securityDefinitions.basic BasicAuth
 
+// @securityDefinitions.apikey TelegramAuth
+// @in header
+// @name Authorization
+// @description Telegram.WebApp.initData in the form "tma <initData>"
+
 // @externalDocs.description OpenAPI
 // @externalDocs.url https://swagger.io/resources/open-api/
 
@@ -22,35 +27,62 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"telegramshop_backend/internal/repository/admins"
 	"telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/repository/catalog"
 	"telegramshop_backend/internal/repository/categories"
 	"telegramshop_backend/internal/repository/comment"
 	"telegramshop_backend/internal/repository/favorites"
 	"telegramshop_backend/internal/repository/firms"
+	"telegramshop_backend/internal/repository/images"
 	"telegramshop_backend/internal/repository/marks"
 	"telegramshop_backend/internal/repository/orders"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/payments"
 	"telegramshop_backend/internal/repository/prices"
 	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/repository/promotions"
+	"telegramshop_backend/internal/repository/reminders"
 	"telegramshop_backend/internal/repository/users"
+	"telegramshop_backend/internal/repository/webhooks"
+	"time"
 
+	adminsService "telegramshop_backend/internal/service/admins"
 	avgMarksService "telegramshop_backend/internal/service/avg_marks"
 	basketService "telegramshop_backend/internal/service/basket"
+	catalogService "telegramshop_backend/internal/service/catalog"
 	categoriesService "telegramshop_backend/internal/service/categories"
 	commentService "telegramshop_backend/internal/service/comment"
 	favoritesService "telegramshop_backend/internal/service/favorites"
 	firmsService "telegramshop_backend/internal/service/firms"
+	imagesService "telegramshop_backend/internal/service/images"
 	marksService "telegramshop_backend/internal/service/marks"
 	ordersService "telegramshop_backend/internal/service/orders"
+	outboxService "telegramshop_backend/internal/service/outbox"
+	paymentsService "telegramshop_backend/internal/service/payments"
 	pricesService "telegramshop_backend/internal/service/prices"
+	pricingService "telegramshop_backend/internal/service/pricing"
 	productsService "telegramshop_backend/internal/service/products"
+	promotionsService "telegramshop_backend/internal/service/promotions"
+	remindersService "telegramshop_backend/internal/service/reminders"
+	suggestService "telegramshop_backend/internal/service/suggest"
 	usersService "telegramshop_backend/internal/service/users"
+	webhooksService "telegramshop_backend/internal/service/webhooks"
 
+	"telegramshop_backend/internal/bot"
 	"telegramshop_backend/internal/handler"
+	"telegramshop_backend/internal/models"
 	"telegramshop_backend/pkg/postgres"
+	"telegramshop_backend/pkg/storage"
+	"telegramshop_backend/pkg/telegram"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -78,11 +110,37 @@ func main() {
 	categoriesRepo := categories.NewRepository(db)
 	firmsRepo := firms.NewRepository(db)
 	commentRepo := comment.NewRepository(db)
+	adminsRepo := admins.NewRepository(db)
+	paymentsRepo := payments.NewRepository(db)
+	outboxRepo := outbox.NewRepository(db)
+	webhooksRepo := webhooks.NewRepository(db)
+	catalogRepo := catalog.NewRepository(db)
+	imagesRepo := images.NewRepository(db)
+	promotionsRepo := promotions.NewRepository(db)
+	remindersRepo := reminders.NewRepository(db)
+
+	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if botToken == "" {
+		log.Fatalf("TELEGRAM_BOT_TOKEN is required")
+	}
+	botClient := telegram.NewBotClient(botToken, getEnvOrDefault("TELEGRAM_API_URL", telegram.DefaultAPIURL))
 
 	userService := usersService.NewService(userRepo)
-	basketService := basketService.NewService(basketRepo)
-	favoritesService := favoritesService.NewService(favoritesRepo)
-	ordersService := ordersService.NewService(ordersRepo)
+	pricingService := pricingService.NewService(pricesRepo, productsRepo)
+	basketMaxQuantity := basketService.DefaultMaxQuantity
+	if raw, ok := os.LookupEnv("BASKET_MAX_QUANTITY"); ok {
+		basketMaxQuantity, err = strconv.Atoi(raw)
+		if err != nil || basketMaxQuantity <= 0 {
+			log.Fatalf("Invalid BASKET_MAX_QUANTITY: %q", raw)
+		}
+	}
+	basketService := basketService.NewService(basketRepo, pricingService, basketMaxQuantity)
+	favoritesService := favoritesService.NewService(favoritesRepo, pricingService)
+	idempotencyTTL, err := time.ParseDuration(getEnvOrDefault("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid IDEMPOTENCY_KEY_TTL: %v", err)
+	}
+	ordersService := ordersService.NewService(ordersRepo, idempotencyTTL)
 	marksService := marksService.NewService(marksRepo)
 	AvgMarksService := avgMarksService.NewService(avgmarksRepo)
 	productsService := productsService.NewService(productsRepo)
@@ -90,24 +148,117 @@ func main() {
 	firmsService := firmsService.NewService(firmsRepo)
 	categoriesService := categoriesService.NewService(categoriesRepo)
 	pricesService := pricesService.NewService(pricesRepo)
+	adminsService := adminsService.NewService(adminsRepo, userRepo)
+	promotionsService := promotionsService.NewService(promotionsRepo)
+
+	imageStorage, err := newImageStorage()
+	if err != nil {
+		log.Fatalf("Failed to configure image storage: %v", err)
+	}
+	imagesConfig := imagesService.DefaultConfig()
+	if raw, ok := os.LookupEnv("IMAGE_MAX_SIZE"); ok {
+		imagesConfig.MaxSize, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid IMAGE_MAX_SIZE: %v", err)
+		}
+	}
+	imagesService := imagesService.NewService(imagesRepo, imageStorage, imagesConfig)
+	catalogService := catalogService.NewService(catalogRepo, imagesService)
+	paymentsService := paymentsService.NewService(paymentsRepo, ordersService, productsRepo, pricingService, botClient, paymentsService.Config{
+		ProviderToken: os.Getenv("TELEGRAM_PAYMENT_PROVIDER_TOKEN"),
+		Currency:      getEnvOrDefault("PAYMENT_CURRENCY", "RUB"),
+	})
+
+	outboxService := outboxService.NewService(outboxRepo, outboxService.DefaultConfig())
+	notifier := bot.NewNotifier(botClient, userRepo, adminsRepo, ordersRepo)
+	outboxService.Register(models.EventOrderCreated, notifier.HandleOrderEvent)
+	outboxService.Register(models.EventOrderStatusChanged, notifier.HandleOrderEvent)
+
+	webhooksConfig := webhooksService.DefaultConfig()
+	if raw, ok := os.LookupEnv("WEBHOOK_LOW_STOCK_THRESHOLD"); ok {
+		webhooksConfig.LowStockThreshold, err = strconv.Atoi(raw)
+		if err != nil {
+			log.Fatalf("Invalid WEBHOOK_LOW_STOCK_THRESHOLD: %v", err)
+		}
+	}
+	webhooksService := webhooksService.NewService(webhooksRepo, &http.Client{}, webhooksConfig)
+	for _, eventType := range []string{
+		models.EventOrderCreated,
+		models.EventOrderStatusChanged,
+		models.EventProductStockChanged,
+		models.EventProductUpdated,
+		models.EventReviewChanged,
+	} {
+		outboxService.Register(eventType, webhooksService.HandleEvent)
+	}
+
+	remindersConfig := remindersService.DefaultConfig()
+	if raw, ok := os.LookupEnv("BASKET_REMINDER_AFTER"); ok {
+		remindersConfig.IdleAfter, err = time.ParseDuration(raw)
+		if err != nil || remindersConfig.IdleAfter <= 0 {
+			log.Fatalf("Invalid BASKET_REMINDER_AFTER: %q", raw)
+		}
+	}
+	var basketReminder remindersService.Notifier = bot.NewBasketReminder(botClient)
+	switch notifierName := getEnvOrDefault("BASKET_REMINDER_NOTIFIER", "telegram"); notifierName {
+	case "telegram":
+	case "log":
+		basketReminder = remindersService.LogNotifier{}
+	default:
+		log.Fatalf("Unknown BASKET_REMINDER_NOTIFIER: %q", notifierName)
+	}
+	remindersService := remindersService.NewService(remindersRepo, basketReminder, remindersConfig)
+
+	suggestService := suggestService.NewService(productsRepo, firmsRepo, categoriesRepo, outboxRepo, suggestService.DefaultConfig())
+	if err := suggestService.Rebuild(context.Background()); err != nil {
+		log.Printf("Suggestion index is empty until the next refresh: %v", err)
+	}
+
+	bootstrapAdmins(context.Background(), userService, adminsService, os.Getenv("ADMIN_TELEGRAM_IDS"))
+
+	authMaxAge, err := time.ParseDuration(getEnvOrDefault("TELEGRAM_AUTH_MAX_AGE", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid TELEGRAM_AUTH_MAX_AGE: %v", err)
+	}
+	authConfig := handler.AuthConfig{
+		BotToken: botToken,
+		MaxAge:   authMaxAge,
+	}
 
-	h := handler.NewHandler(userService, favoritesService, basketService, ordersService, firmsService, pricesService, categoriesService, productsService, marksService, AvgMarksService, commentService)
+	h := handler.NewHandler(userService, favoritesService, basketService, ordersService, firmsService, pricesService, categoriesService, productsService, marksService, AvgMarksService, commentService, adminsService, paymentsService, outboxService, webhooksService, suggestService, catalogService, imagesService, promotionsService, remindersService, authConfig)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// Leave room for the multipart envelope around the largest accepted image.
+		BodyLimit: max(fiber.DefaultBodyLimit, int(imagesConfig.MaxSize)+64*1024),
+	})
 
 	// Middleware
 	app.Use(logger.New())
 	app.Use(cors.New())
 
+	// Uploaded images of the local storage backend
+	if local, ok := imageStorage.(*storage.Local); ok {
+		app.Static("/uploads", local.Dir())
+	}
+
 	// Swagger route
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
 	// API routes
 	h.InitRouter(app)
 
+	telegramBot := bot.New(os.Getenv("TELEGRAM_WEBHOOK_SECRET"), paymentsService)
+	telegramBot.InitRouter(app)
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	go purgeIdempotencyKeys(ctx, ordersService, time.Hour)
+	go outboxService.Run(ctx)
+	go webhooksService.Run(ctx)
+	go suggestService.Run(ctx)
+	go remindersService.Run(ctx)
+
 	go func() {
 		if err := app.Listen(":8080"); err != nil {
 			log.Fatalf("Fiber Listen error: %v", err)
@@ -118,3 +269,69 @@ func main() {
 	log.Println("Shutting down gracefully...")
 	_ = app.Shutdown()
 }
+
+// newImageStorage builds the backend of uploaded images selected by STORAGE_BACKEND.
+func newImageStorage() (storage.Storage, error) {
+	switch backend := getEnvOrDefault("STORAGE_BACKEND", "local"); backend {
+	case "local":
+		return storage.NewLocal(getEnvOrDefault("STORAGE_LOCAL_DIR", "./uploads"), getEnvOrDefault("STORAGE_PUBLIC_URL", "/uploads")), nil
+	case "s3":
+		return storage.NewS3(&http.Client{Timeout: 30 * time.Second}, storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          getEnvOrDefault("S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("STORAGE_PUBLIC_URL"),
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, expected local or s3", backend)
+	}
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// bootstrapAdmins grants admin rights to a comma-separated list of telegram ids,
+// so the first admin does not have to be inserted by hand.
+func bootstrapAdmins(ctx context.Context, users usersService.Service, admins adminsService.Service, ids string) {
+	for _, raw := range strings.Split(ids, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		telegramID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			log.Printf("Invalid admin telegram id %q: %v", raw, err)
+			continue
+		}
+
+		if _, err := users.EnsureUser(ctx, models.CreateUser{TelegramID: telegramID}); err != nil {
+			log.Printf("Failed to register admin %d: %v", telegramID, err)
+			continue
+		}
+		if _, err := admins.GrantAdmin(ctx, telegramID); err != nil {
+			log.Printf("Failed to grant admin rights to %d: %v", telegramID, err)
+		}
+	}
+}
+
+// purgeIdempotencyKeys periodically deletes idempotency keys past their retention window.
+func purgeIdempotencyKeys(ctx context.Context, orders ordersService.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = orders.PurgeIdempotencyKeys(ctx)
+		}
+	}
+}
@@ -24,39 +24,36 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/basket": {
-            "put": {
-                "description": "Updates quantity of product in user's basket",
-                "consumes": [
-                    "application/json"
+        "/api/v1/admins": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Returns all users with admin rights",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
-                ],
-                "summary": "Update basket item",
-                "parameters": [
-                    {
-                        "description": "Updated basket item data",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.BasketItem"
-                        }
-                    }
+                    "admins"
                 ],
+                "summary": "Get all admins",
                 "responses": {
                     "200": {
-                        "description": "Basket item successfully updated",
+                        "description": "All admins retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.BasketResponse"
+                            "$ref": "#/definitions/models.UserListResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -68,39 +65,59 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/admins/{id}": {
             "post": {
-                "description": "Adds a product to user's basket",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Grants admin rights to the user with the given Telegram ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
+                    "admins"
                 ],
-                "summary": "Add item to basket",
+                "summary": "Grant admin rights",
                 "parameters": [
                     {
-                        "description": "Basket item data",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.BasketItem"
-                        }
+                        "type": "integer",
+                        "description": "User Telegram ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Item successfully added to basket",
+                        "description": "Admin rights granted",
                         "schema": {
-                            "$ref": "#/definitions/models.BasketResponse"
+                            "$ref": "#/definitions/models.UserResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -112,32 +129,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/basket/{user_id}": {
-            "get": {
-                "description": "Returns all items in user's basket",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Revokes admin rights from the user with the given Telegram ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
+                    "admins"
                 ],
-                "summary": "Get user's basket",
+                "summary": "Revoke admin rights",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
+                        "description": "User Telegram ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User's basket retrieved",
+                        "description": "Admin rights revoked",
                         "schema": {
-                            "$ref": "#/definitions/models.BasketListResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
@@ -146,6 +166,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -155,69 +193,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/basket/{user_id}/{product_id}": {
-            "delete": {
-                "description": "Removes product from user's basket",
+        "/api/v1/basket": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Sets the quantity of a product and variant in user's basket, adding the line if it is missing. Quantity 0 removes the line. The quantity may not exceed the stock or the per-line limit",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "basket"
                 ],
-                "summary": "Remove item from basket",
+                "summary": "Update basket item",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "product_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Updated basket item data",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketItem"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Item successfully removed from basket",
+                        "description": "Basket item successfully updated",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.BasketResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid parameters",
+                        "description": "Invalid request body or quantity",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/categories": {
-            "get": {
-                "description": "Returns all categories in the system",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "categories"
-                ],
-                "summary": "Get all categories",
-                "responses": {
-                    "200": {
-                        "description": "All categories retrieved successfully",
+                    },
+                    "404": {
+                        "description": "Product not found",
                         "schema": {
-                            "$ref": "#/definitions/models.CategoryListResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Quantity exceeds the stock or the per-line limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketQuantityErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown variant or variant_id missing for a product with variants",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -229,7 +268,12 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a new category with specified details",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Adds a product to user's basket. Adding a product and variant that is already in the basket increases its quantity. The resulting quantity must be positive and may not exceed the stock or the per-line limit",
                 "consumes": [
                     "application/json"
                 ],
@@ -237,29 +281,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "basket"
                 ],
-                "summary": "Create new category",
+                "summary": "Add item to basket",
                 "parameters": [
                     {
-                        "description": "Category creation data",
-                        "name": "category",
+                        "description": "Basket item data",
+                        "name": "item",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Category"
+                            "$ref": "#/definitions/models.BasketItem"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Category successfully created",
+                        "description": "Item successfully added to basket, with the resulting quantity",
                         "schema": {
-                            "$ref": "#/definitions/models.CategoryResponse"
+                            "$ref": "#/definitions/models.BasketResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body or quantity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Quantity exceeds the stock or the per-line limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketQuantityErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown variant or variant_id missing for a product with variants",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -273,34 +341,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/categories/{id}": {
+        "/api/v1/basket/{user_id}": {
             "get": {
-                "description": "Returns category details by its ID",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns all items in user's basket in one request: each line carries the product name, first image and stock, and is priced the way checkout prices it (unit price of the best tier for its quantity, single-item base price, line total and savings). A line whose quantity exceeds the stock gets warning out_of_stock or insufficient_stock; a line without a variant or tier for its quantity has priced=false. Subtotal and savings only count lines checkout would order",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "basket"
                 ],
-                "summary": "Get category by ID",
+                "summary": "Get user's basket",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Category ID",
-                        "name": "id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Category retrieved successfully",
+                        "description": "User's basket retrieved",
                         "schema": {
-                            "$ref": "#/definitions/models.CategoryResponse"
+                            "$ref": "#/definitions/models.BasketListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid category ID",
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access to another user's basket",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -314,7 +399,12 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Updates category details by its ID",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Replaces the whole basket in one transaction, for example to sync a basket kept on the device. Lines of the same product and variant are merged, lines with quantity 0 are skipped. If any line is invalid the basket is left unchanged",
                 "consumes": [
                     "application/json"
                 ],
@@ -322,75 +412,66 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "basket"
                 ],
-                "summary": "Update category",
+                "summary": "Replace basket",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Category ID",
-                        "name": "id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated category data",
-                        "name": "category",
+                        "description": "New basket content",
+                        "name": "basket",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateCategoryInput"
+                            "$ref": "#/definitions/models.ReplaceBasket"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Category successfully updated",
+                        "description": "Basket replaced",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.BasketListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body or quantity",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Deletes a category by its ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "categories"
-                ],
-                "summary": "Delete category",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Category successfully deleted",
+                    },
+                    "403": {
+                        "description": "Access to another user's basket",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid category ID",
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Quantity exceeds the stock or the per-line limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketQuantityErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown variant or variant_id missing for a product with variants",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -404,46 +485,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/categories/{id}/image": {
-            "put": {
-                "description": "Sets or updates the image for a category",
-                "consumes": [
-                    "application/json"
+        "/api/v1/basket/{user_id}/{product_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Removes product from user's basket. Pass variant_id to remove only that variant, otherwise every line of the product is removed",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "basket"
                 ],
-                "summary": "Set category image",
+                "summary": "Remove item from basket",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Category ID",
-                        "name": "id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Image data",
-                        "name": "image",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ImageInput"
-                        }
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Variant ID",
+                        "name": "variant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Category image successfully set",
+                        "description": "Item successfully removed from basket",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access to another user's basket",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -455,40 +554,57 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Removes the image from a category",
+            }
+        },
+        "/api/v1/catalog/export": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Streams every product with its price tiers, images, firm and category in the import format, so the file can be edited and imported back",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "tags": [
-                    "categories"
+                    "catalog"
                 ],
-                "summary": "Remove category image",
+                "summary": "Export catalog",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Category image successfully removed",
+                        "description": "Catalog file",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Invalid category ID",
+                        "description": "Unknown format",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -496,43 +612,83 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/favorites": {
+        "/api/v1/catalog/import": {
             "post": {
-                "description": "Adds a product to user's favorites list",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates and updates products with their price tiers, images, firm and category (by name, created when missing) from a CSV or JSON file. Rows with an id update that product, rows without one create a product. Empty images or prices keep the stored ones. Every row is validated first; if any row is invalid nothing is written and the response lists the problems. With dry_run=true the file is only validated. CSV columns: id, name, description, firm, category, stock, attributes (JSON object), images (URLs separated by |), prices (count:price separated by |)",
                 "consumes": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "favorites"
+                    "catalog"
                 ],
-                "summary": "Add item to favorites",
+                "summary": "Import catalog",
                 "parameters": [
                     {
-                        "description": "Favorite item data",
-                        "name": "favorite",
+                        "description": "CSV or JSON file contents",
+                        "name": "file",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Favorite"
+                            "type": "string"
                         }
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "File format, taken from Content-Type when omitted",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Only validate the file",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Item successfully added to favorites",
+                        "description": "Import result or dry-run report",
                         "schema": {
-                            "$ref": "#/definitions/models.FavoriteResponse"
+                            "$ref": "#/definitions/models.CatalogImportResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Unknown format, unreadable or empty file, too many rows",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "422": {
+                        "description": "Some rows are invalid, nothing was imported",
+                        "schema": {
+                            "$ref": "#/definitions/models.CatalogImportResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -542,36 +698,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/favorites/{user_id}": {
+        "/api/v1/categories": {
             "get": {
-                "description": "Returns all items in user's favorites list",
+                "description": "Returns all categories in the system",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "favorites"
-                ],
-                "summary": "Get user's favorites",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "categories"
                 ],
+                "summary": "Get all categories",
                 "responses": {
                     "200": {
-                        "description": "User's favorites retrieved",
-                        "schema": {
-                            "$ref": "#/definitions/models.FavoriteListResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid user ID",
+                        "description": "All categories retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.CategoryListResponse"
                         }
                     },
                     "500": {
@@ -581,43 +722,56 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/favorites/{user_id}/{product_id}": {
-            "delete": {
-                "description": "Removes product from user's favorites list",
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a new category with specified details. Set parent_id to create a subcategory",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "favorites"
+                    "categories"
                 ],
-                "summary": "Remove item from favorites",
+                "summary": "Create new category",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "product_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Category creation data",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Item successfully removed from favorites",
+                        "description": "Category successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.CategoryResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid parameters",
+                        "description": "Invalid request body or unknown parent category",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -631,21 +785,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/firms": {
+        "/api/v1/categories/tree": {
             "get": {
-                "description": "Returns all firms in the system",
+                "description": "Returns root categories with their subcategories nested in children, each level sorted by name",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "firms"
+                    "categories"
                 ],
-                "summary": "Get all firms",
+                "summary": "Get category tree",
                 "responses": {
                     "200": {
-                        "description": "All firms retrieved successfully",
+                        "description": "Category tree retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.FirmListResponse"
+                            "$ref": "#/definitions/models.CategoryTreeResponse"
                         }
                     },
                     "500": {
@@ -655,39 +809,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Creates a new firm with specified details",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/categories/{id}": {
+            "get": {
+                "description": "Returns category details by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "firms"
+                    "categories"
                 ],
-                "summary": "Create new firm",
+                "summary": "Get category by ID",
                 "parameters": [
                     {
-                        "description": "Firm creation data",
-                        "name": "firm",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Firm"
-                        }
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Firm successfully created",
+                        "description": "Category retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.FirmResponse"
+                            "$ref": "#/definitions/models.CategoryResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid category ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -699,42 +850,63 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/firms/{id}": {
-            "get": {
-                "description": "Returns firm details by its ID",
+            },
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Updates category details by its ID. parent_id moves the category in the tree and null makes it a root category; without parent_id the category stays where it is. A category cannot be moved under itself or its subcategory",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "firms"
+                    "categories"
                 ],
-                "summary": "Get firm by ID",
+                "summary": "Update category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Firm ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
+                    },
+                    {
+                        "description": "Updated category data",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateCategoryInput"
+                        }
+                    }
+                ],
+                "responses": {
                     "200": {
-                        "description": "Firm retrieved successfully",
+                        "description": "Category successfully updated",
                         "schema": {
-                            "$ref": "#/definitions/models.FirmResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid firm ID",
+                        "description": "Invalid request body, unknown parent category or cycle",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Firm not found",
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -747,84 +919,63 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Updates firm details by its ID",
-                "consumes": [
-                    "application/json"
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Deletes a category by its ID. A category with subcategories is only deleted with reparent=true, which moves the subcategories to the parent of the deleted category",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "firms"
+                    "categories"
                 ],
-                "summary": "Update firm",
+                "summary": "Delete category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Firm ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated firm data",
-                        "name": "firm",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateFirmInput"
-                        }
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Move subcategories up instead of rejecting the deletion",
+                        "name": "reparent",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Firm successfully updated",
+                        "description": "Category successfully deleted",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid category ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Deletes a firm by its ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "firms"
-                ],
-                "summary": "Delete firm",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Firm ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Firm successfully deleted",
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid firm ID",
+                    "409": {
+                        "description": "Category has subcategories",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -838,9 +989,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/orders": {
-            "post": {
-                "description": "Creates a new order for a user with specified products",
+        "/api/v1/categories/{id}/image": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Sets or updates the image for a category",
                 "consumes": [
                     "application/json"
                 ],
@@ -848,25 +1004,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "categories"
                 ],
-                "summary": "Create new order",
+                "summary": "Set category image",
                 "parameters": [
                     {
-                        "description": "Order creation data",
-                        "name": "order",
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Image data",
+                        "name": "image",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateOrder"
+                            "$ref": "#/definitions/models.ImageInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Order successfully created",
+                        "description": "Category image successfully set",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
@@ -875,30 +1038,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/orders/all": {
-            "get": {
-                "description": "Returns all orders in the system",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "orders"
-                ],
-                "summary": "Get all orders",
-                "responses": {
-                    "200": {
-                        "description": "All orders retrieved successfully",
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderListResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -908,36 +1057,51 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/orders/user/{user_id}": {
-            "get": {
-                "description": "Returns all orders for a specific user",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Removes the image from a category",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "categories"
                 ],
-                "summary": "Get user's orders",
+                "summary": "Remove category image",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
+                        "description": "Category ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User's orders retrieved successfully",
+                        "description": "Category image successfully removed",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderListResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid category ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -951,34 +1115,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/orders/{id}": {
-            "get": {
-                "description": "Returns order details with all products",
+        "/api/v1/categories/{id}/image/upload": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Stores a JPEG, PNG or GIF file under the hash of its content, generates a JPEG thumbnail next to it (same name with the _thumb.jpg suffix) and makes it the category image. The previous uploaded image is deleted unless something else uses it",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "categories"
                 ],
-                "summary": "Get order by ID",
+                "summary": "Upload category image",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Order ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Order retrieved successfully",
+                        "description": "Image uploaded",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderResponse"
+                            "$ref": "#/definitions/models.UploadedImageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid order ID",
+                        "description": "Invalid category ID, missing or broken image",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Image file or dimensions are too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Image is not a JPEG, PNG or GIF",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -992,9 +1201,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/prices": {
+        "/api/v1/favorites": {
             "post": {
-                "description": "Creates a new price with specified details",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Adds a product to user's favorites list. variant_id must be a variant of the product and is required for products that have variants",
                 "consumes": [
                     "application/json"
                 ],
@@ -1002,29 +1216,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "favorites"
                 ],
-                "summary": "Create new price",
+                "summary": "Add item to favorites",
                 "parameters": [
                     {
-                        "description": "Price creation data",
-                        "name": "price",
+                        "description": "Favorite item data",
+                        "name": "favorite",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Price"
+                            "$ref": "#/definitions/models.Favorite"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Price successfully created",
+                        "description": "Item successfully added to favorites",
                         "schema": {
-                            "$ref": "#/definitions/models.PriceResponse"
+                            "$ref": "#/definitions/models.FavoriteResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body or variant",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1038,73 +1264,110 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/prices/product/{product_id}": {
+        "/api/v1/favorites/{user_id}": {
             "get": {
-                "description": "Returns all prices for a specific product",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns all items in user's favorites list, newest first, with the product name, first image, stock and current single-item price. Breaking change: data used to be a plain array of product IDs; read product_id from each object instead",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "favorites"
                 ],
-                "summary": "Get prices by product ID",
+                "summary": "Get user's favorites",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
-                        "name": "product_id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Prices retrieved successfully",
+                        "description": "User's favorites retrieved",
                         "schema": {
-                            "$ref": "#/definitions/models.PriceListResponse"
+                            "$ref": "#/definitions/models.FavoriteListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Invalid user ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
                 }
-            },
+            }
+        },
+        "/api/v1/favorites/{user_id}/{product_id}": {
             "delete": {
-                "description": "Deletes all prices associated with a specific product",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Removes product from user's favorites list. Pass variant_id to remove a favorite variant of the product",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "favorites"
                 ],
-                "summary": "Delete prices by product ID",
+                "summary": "Remove item from favorites",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "Product ID",
                         "name": "product_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Variant ID",
+                        "name": "variant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Prices successfully deleted",
+                        "description": "Item successfully removed from favorites",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1118,36 +1381,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/prices/{id}": {
+        "/api/v1/firms": {
             "get": {
-                "description": "Returns price details by its ID",
+                "description": "Returns all firms in the system",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
-                ],
-                "summary": "Get price by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Price ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "firms"
                 ],
+                "summary": "Get all firms",
                 "responses": {
                     "200": {
-                        "description": "Price retrieved successfully",
-                        "schema": {
-                            "$ref": "#/definitions/models.PriceResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid price ID",
+                        "description": "All firms retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.FirmListResponse"
                         }
                     },
                     "500": {
@@ -1158,8 +1406,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Updates price details by its ID",
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a new firm with specified details",
                 "consumes": [
                     "application/json"
                 ],
@@ -1167,32 +1420,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "firms"
                 ],
-                "summary": "Update price",
+                "summary": "Create new firm",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Price ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated price data",
-                        "name": "price",
+                        "description": "Firm creation data",
+                        "name": "firm",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdatePriceInput"
+                            "$ref": "#/definitions/models.Firm"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Price successfully updated",
+                        "description": "Firm successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.FirmResponse"
                         }
                     },
                     "400": {
@@ -1201,6 +1447,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1208,20 +1466,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a price by its ID",
+            }
+        },
+        "/api/v1/firms/{id}": {
+            "get": {
+                "description": "Returns firm details by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "firms"
                 ],
-                "summary": "Delete price",
+                "summary": "Get firm by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Price ID",
+                        "description": "Firm ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1229,13 +1489,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Price successfully deleted",
+                        "description": "Firm retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.FirmResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid price ID",
+                        "description": "Invalid firm ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Firm not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1247,11 +1513,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/prices/{id}/count": {
+            },
             "put": {
-                "description": "Updates the count for a specific price",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Updates firm details by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1259,30 +1528,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "firms"
                 ],
-                "summary": "Update price count",
+                "summary": "Update firm",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Price ID",
+                        "description": "Firm ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "New count value",
-                        "name": "count",
+                        "description": "Updated firm data",
+                        "name": "firm",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdatePriceCount"
+                            "$ref": "#/definitions/models.UpdateFirmInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Price count successfully updated",
+                        "description": "Firm successfully updated",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
@@ -1293,6 +1562,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1300,23 +1581,53 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/products": {
-            "get": {
-                "description": "Returns all products in the system",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes a firm by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "firms"
+                ],
+                "summary": "Delete firm",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Firm ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all products",
                 "responses": {
                     "200": {
-                        "description": "All products retrieved successfully",
+                        "description": "Firm successfully deleted",
                         "schema": {
-                            "$ref": "#/definitions/models.ProductListResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid firm ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -1326,9 +1637,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/orders": {
             "post": {
-                "description": "Creates a new product with specified details",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a new order for a user with specified products",
                 "consumes": [
                     "application/json"
                 ],
@@ -1336,33 +1654,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Create new product",
+                "summary": "Create new order",
                 "parameters": [
                     {
-                        "description": "Product creation data",
-                        "name": "product",
+                        "description": "Order creation data",
+                        "name": "order",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.CreateOrder"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-generated key; retries with the same key and body return the original order",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product successfully created",
+                        "description": "Order successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.ProductResponse"
+                            "$ref": "#/definitions/models.OrderResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body, order items or idempotency key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Not enough stock for some products or idempotency key reused with a different body",
+                        "schema": {
+                            "$ref": "#/definitions/models.InsufficientStockResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown product, no matching price tier or promo code that cannot be applied",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1372,48 +1714,78 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/{id}": {
+        "/api/v1/orders/all": {
             "get": {
-                "description": "Returns product details by its ID",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns one page of orders, newest first. Pass next_cursor from the response as cursor to get the following page",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Get product by ID",
+                "summary": "Get all orders",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product retrieved successfully",
+                        "description": "All orders retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.OrderListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Invalid cursor or limit",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
                 }
-            },
-            "put": {
-                "description": "Updates product details by its ID",
+            }
+        },
+        "/api/v1/orders/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Builds an order from the current basket, prices it, applies the promo code if one is given and reserves stock, then clears the basket. Lines that are out of stock, whose product no longer exists or whose quantity has no price tier are left out and listed in \"dropped\"",
                 "consumes": [
                     "application/json"
                 ],
@@ -1421,77 +1793,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Update product",
+                "summary": "Checkout basket",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated product data",
-                        "name": "product",
+                        "description": "Promo code",
+                        "name": "promo",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateProductInput"
+                            "$ref": "#/definitions/models.ApplyPromoCode"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product successfully updated",
+                        "description": "Order created from basket",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.CheckoutResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Basket is empty or invalid request body",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Deletes a product by its ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "products"
-                ],
-                "summary": "Delete product",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Product successfully deleted",
+                    },
+                    "409": {
+                        "description": "None of the basket items can be ordered",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.UnavailableBasketResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid product ID",
+                    "422": {
+                        "description": "Promo code that cannot be applied",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.PromoCodeErrorResponse"
                         }
                     },
                     "500": {
@@ -1503,9 +1846,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/{id}/images": {
-            "put": {
-                "description": "Sets all images for a product",
+        "/api/v1/orders/preview": {
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Prices the current basket the way checkout would, with the promo code applied if one is given, without reserving stock or creating an order. Lines that could not be ordered are listed in \"dropped\"",
                 "consumes": [
                     "application/json"
                 ],
@@ -1513,40 +1861,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Set product images",
+                "summary": "Preview basket price",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Array of image data",
-                        "name": "images",
+                        "description": "Promo code",
+                        "name": "promo",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ImagesInput"
+                            "$ref": "#/definitions/models.ApplyPromoCode"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product images successfully set",
+                        "description": "Priced basket",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.OrderQuoteResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Basket is empty or invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "None of the basket items can be ordered",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnavailableBasketResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Promo code that cannot be applied",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1554,46 +1912,66 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Adds a new image to a product",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/api/v1/orders/user/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Returns one page of the user's orders, newest first. Pass next_cursor from the response as cursor to get the following page",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Add product image",
+                "summary": "Get user's orders",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Image data",
-                        "name": "image",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ImagesInput"
-                        }
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product image successfully added",
+                        "description": "User's orders retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.OrderListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid user ID, cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access to another user's data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1605,46 +1983,59 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Removes an image from a product",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/api/v1/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Returns order details with all products",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Remove product image",
+                "summary": "Get order by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Order ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Image data to remove",
-                        "name": "image",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ImagesInput"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product image successfully removed",
+                        "description": "Order retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.OrderResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid order ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access to another user's data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1658,9 +2049,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/{id}/sell-count": {
-            "put": {
-                "description": "Increments the sell count for a product",
+        "/api/v1/orders/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Cancels the user's own order while it is still pending or paid and returns reserved items to stock",
                 "consumes": [
                     "application/json"
                 ],
@@ -1668,36 +2064,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "orders"
                 ],
-                "summary": "Increment sell count",
+                "summary": "Cancel order",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Order ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Count to increment",
-                        "name": "count",
+                        "description": "Cancellation reason",
+                        "name": "reason",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CountInput"
+                            "$ref": "#/definitions/models.CancelOrder"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Sell count successfully incremented",
+                        "description": "Order cancelled",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.OrderResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid order ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access to another user's order",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be cancelled",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1711,74 +2130,216 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/{id}/stock": {
-            "put": {
-                "description": "Updates the stock count for a product",
-                "consumes": [
-                    "application/json"
+        "/api/v1/orders/{id}/invoice": {
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
                 ],
+                "description": "Creates a Telegram Payments invoice link for a pending order. The Mini App opens it with Telegram.WebApp.openInvoice",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "payments"
                 ],
-                "summary": "Update stock",
+                "summary": "Create invoice link",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Order ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "New stock value",
-                        "name": "stock",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.StockInput"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Stock successfully updated",
+                        "description": "Invoice link created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.InvoiceLinkResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid order ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid Telegram init data",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
+                    },
+                    "403": {
+                        "description": "Access to another user's order",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Order is not awaiting payment",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Telegram API error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
         },
-        "/api/v1/users": {
+        "/api/v1/orders/{id}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Moves an order along its lifecycle (pending → paid → assembling → shipped → delivered, with cancelled and refunded branches) and records the change in the order history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateOrderStatus"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order status updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid order ID or unknown status",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition is not allowed or status changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbox": {
             "get": {
-                "description": "Returns all users in the system",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns the latest outbox events, optionally filtered by status (pending, done, dead)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "outbox"
+                ],
+                "summary": "List outbox events",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "done",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Event status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of events",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all users",
                 "responses": {
                     "200": {
-                        "description": "All users retrieved successfully",
+                        "description": "Outbox events retrieved",
                         "schema": {
-                            "$ref": "#/definitions/models.UserListResponse"
+                            "$ref": "#/definitions/models.OutboxEventListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status or limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -1788,9 +2349,80 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/outbox/{id}/replay": {
             "post": {
-                "description": "Creates a new user in the system",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Resets a dead outbox event so the dispatcher delivers it again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "Replay outbox event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event scheduled for replay",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No dead event with this ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/prices": {
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a new price with specified details",
                 "consumes": [
                     "application/json"
                 ],
@@ -1798,25 +2430,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "prices"
                 ],
-                "summary": "Create new user",
+                "summary": "Create new price",
                 "parameters": [
                     {
-                        "description": "User creation data",
-                        "name": "user",
+                        "description": "Price creation data",
+                        "name": "price",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateUser"
+                            "$ref": "#/definitions/models.Price"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User successfully created",
+                        "description": "Price successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "$ref": "#/definitions/models.PriceResponse"
                         }
                     },
                     "400": {
@@ -1825,6 +2457,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1834,34 +2478,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/{id}": {
+        "/api/v1/prices/product/{product_id}": {
             "get": {
-                "description": "Returns user details by ID",
+                "description": "Returns all prices for a specific product",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "prices"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Get prices by product ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Product ID",
+                        "name": "product_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User retrieved successfully",
+                        "description": "Prices retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "$ref": "#/definitions/models.PriceListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid product ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1875,32 +2519,49 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes a user from the system",
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes all prices associated with a specific product",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "prices"
                 ],
-                "summary": "Delete user",
+                "summary": "Delete prices by product ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Product ID",
+                        "name": "product_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User successfully deleted",
+                        "description": "Prices successfully deleted",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1913,442 +2574,3882 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.BasketItem": {
-            "type": "object",
-            "properties": {
-                "added_at": {
+        },
+        "/api/v1/prices/{id}": {
+            "get": {
+                "description": "Returns price details by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get price by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid price ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Updates price details by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Update price",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated price data",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePriceInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price successfully updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes a price by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Delete price",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price successfully deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid price ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/prices/{id}/count": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Updates the count for a specific price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Update price count",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New count value",
+                        "name": "count",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePriceCount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price count successfully updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products": {
+            "get": {
+                "description": "Returns one page of products matching the filters. Pass next_cursor from the response as cursor to get the following page; it is absent on the last page. Price filters and sorting use the single-item price tier",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Firm ID",
+                        "name": "firm_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only products in stock",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attribute filter: any attr.\u003ckey\u003e=\u003cvalue\u003e keeps products whose attribute \u003ckey\u003e equals \u003cvalue\u003e",
+                        "name": "attr.color",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "newest",
+                            "price_asc",
+                            "price_desc",
+                            "popularity",
+                            "rating"
+                        ],
+                        "type": "string",
+                        "default": "newest",
+                        "description": "Sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Products retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a new product with specified details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create new product",
+                "parameters": [
+                    {
+                        "description": "Product creation data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/facets": {
+            "get": {
+                "description": "Returns every attribute key with its distinct values and the number of products having each, for rendering catalog filter chips. Use the values as attr.\u003ckey\u003e=\u003cvalue\u003e filters of GET /products",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Product attribute facets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit facets to one category",
+                        "name": "category_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Facets retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductFacetListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid category ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/search": {
+            "get": {
+                "description": "Full-text search over product name, description, firm and category name with Russian morphology, ranked by relevance. Name matches weigh most. When nothing matches, products with a similar name are returned instead (fuzzy is true). Snippets are HTML with matches wrapped in \u003cmark\u003e",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Search products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, supports quotes, OR and -exclusion",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of results, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Search results",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Empty or too long query, invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/suggest": {
+            "get": {
+                "description": "Autocomplete for the search box. Returns products, firms and categories with a word starting with the prefix, best sellers first; a firm or category ranks by the total sales of its products. Served from memory, so catalog changes show up after a short delay",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Suggest products, firms and categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Beginning of the typed text",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of suggestions, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Suggestions",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuggestionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Empty prefix or invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}": {
+            "get": {
+                "description": "Returns product details by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Updates product details by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated product data",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProductInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product successfully updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes a product by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product successfully deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}/images": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Sets all images for a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Set product images",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Array of image data",
+                        "name": "images",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImagesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product images successfully set",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Adds a new image to a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Add product image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Image data",
+                        "name": "image",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImagesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product image successfully added",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Removes an image from a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Remove product image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Image data to remove",
+                        "name": "image",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImagesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product image successfully removed",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}/images/upload": {
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Stores a JPEG, PNG or GIF file under the hash of its content, generates a JPEG thumbnail next to it (same name with the _thumb.jpg suffix) and appends the image URL to the product images. Uploading an image the product already has does not add it twice",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Upload product image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image uploaded",
+                        "schema": {
+                            "$ref": "#/definitions/models.UploadedImageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID, missing or broken image",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Image file or dimensions are too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Image is not a JPEG, PNG or GIF",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}/sell-count": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Increments the sell count for a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Increment sell count",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Count to increment",
+                        "name": "count",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CountInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sell count successfully incremented",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}/stock": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Updates the stock count for a product. The stock of a product with variants is the sum of its variants' stock and is changed through them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New stock value",
+                        "name": "stock",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock successfully updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Product has variants",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}/variants": {
+            "get": {
+                "description": "Returns the variants (SKUs) of a product with their attributes, stock and price override",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "variants"
+                ],
+                "summary": "Get product variants",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Variants retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariantListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Adds a variant with its own SKU, attributes and stock. The product stock becomes the sum of its variants' stock. price, when set, replaces the product's price tiers for this variant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "variants"
+                ],
+                "summary": "Create product variant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variant data",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariantInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Variant successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or variant data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU already used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{id}/variants/{variant_id}": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Replaces the SKU, attributes, stock and price override of a variant and recomputes the product stock",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "variants"
+                ],
+                "summary": "Update product variant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Variant ID",
+                        "name": "variant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variant data",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariantInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Variant successfully updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or variant data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Variant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU already used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes a variant, removes it from baskets and favorites and recomputes the product stock. Past order lines keep their price but lose the variant reference",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "variants"
+                ],
+                "summary": "Delete product variant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Variant ID",
+                        "name": "variant_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Variant successfully deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Variant not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/promotions": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns all promo codes, newest first, with the number of times each was used",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "List promo codes",
+                "responses": {
+                    "200": {
+                        "description": "Promo codes retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a promo code. kind is percent (value in (0, 100]), fixed (value in currency) or free_item (product_id given away once). percent and fixed may be limited to a category subtree and/or a firm. Codes are stored upper-case",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Create promo code",
+                "parameters": [
+                    {
+                        "description": "Promo code",
+                        "name": "promo_code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Promo code created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid promo code or unknown product, category or firm",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Code already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/promotions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns the promo code with the number of times it was used",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Get promo code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promo code retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Promo code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Replaces all fields of the promo code; omitted active defaults to true. Orders already placed keep their discounts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Update promo code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Promo code",
+                        "name": "promo_code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promo code updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromoCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, promo code or unknown product, category or firm",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Promo code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Code already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes the promo code. Orders that used it keep their discount lines and the code text",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Delete promo code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promo code deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Promo code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/telegram/webhook": {
+            "post": {
+                "description": "Receives bot updates and dispatches them to the bot handlers (payments: pre_checkout_query and successful_payment). Requests must carry the secret token configured with setWebhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telegram"
+                ],
+                "summary": "Telegram webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook secret token",
+                        "name": "X-Telegram-Bot-Api-Secret-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Telegram update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update processed",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid update",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid secret token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Update could not be processed, Telegram will redeliver it",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns one page of users ordered by ID. Pass next_cursor from the response as cursor to get the following page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All users retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Creates a new user in the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create new user",
+                "parameters": [
+                    {
+                        "description": "User creation data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns the user identified by Telegram init data, registering it on first request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "User retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/basket-reminders": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Opts the authenticated user in or out of bot reminders about a basket left untouched. Reminders are on by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set basket reminders",
+                "parameters": [
+                    {
+                        "description": "Reminder settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketReminderSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settings updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketReminderSettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns user details by ID. Customers may only get themselves; admins may get anyone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access to another user's data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes a user from the system",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User successfully deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns all webhook subscriptions. Secrets are never returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "Subscriptions retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Subscribes an external URL to shop events: order.created, order.status_changed, product.stock_low, review.created. Deliveries are JSON POSTs signed with HMAC-SHA256 of \"\u003cX-Webhook-Timestamp\u003e.\u003cbody\u003e\" keyed by the secret, sent in X-Webhook-Signature",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookSubscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Subscription created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid URL, secret or event types",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Changes the URL, secret, event types or active flag; omitted fields are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changed fields",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWebhookSubscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, URL, secret or event types",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Deletes the subscription together with its delivery log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "TelegramAuth": []
+                    }
+                ],
+                "description": "Returns the latest deliveries of the subscription with their status, attempts, last response status and error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of deliveries",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid Telegram init data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin rights required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.ApplyPromoCode": {
+            "type": "object",
+            "properties": {
+                "promo_code": {
+                    "type": "string",
+                    "example": "SUMMER10"
+                }
+            }
+        },
+        "models.Basket": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BasketLine"
+                    }
+                },
+                "savings": {
+                    "type": "number",
+                    "example": 102.5
+                },
+                "subtotal": {
+                    "description": "Subtotal and Savings only count the lines checkout would order: priced and without a warning.",
+                    "type": "number",
+                    "example": 890
+                }
+            }
+        },
+        "models.BasketItem": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BasketLine": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "base_price": {
+                    "description": "BasePrice is the single-item price the savings are measured against.",
+                    "type": "number",
+                    "example": 90.25
+                },
+                "image": {
+                    "description": "Image is the first image of the product.",
+                    "type": "string",
+                    "example": "https://example.com/1.jpg"
+                },
+                "in_stock": {
+                    "type": "boolean"
+                },
+                "line_total": {
+                    "type": "number",
+                    "example": 800
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Green tea"
+                },
+                "priced": {
+                    "type": "boolean"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "savings": {
+                    "type": "number",
+                    "example": 102.5
+                },
+                "stock": {
+                    "description": "Stock is the variant's stock when the line has a variant.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "unit_price": {
+                    "type": "number",
+                    "example": 80
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                },
+                "warning": {
+                    "description": "Warning is out_of_stock or insufficient_stock when the quantity exceeds the stock.",
+                    "type": "string",
+                    "example": "insufficient_stock"
+                }
+            }
+        },
+        "models.BasketListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Basket"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_user_basket_retrieved"
+                }
+            }
+        },
+        "models.BasketQuantityErrorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "properties": {
+                        "limit": {
+                            "type": "integer",
+                            "example": 99
+                        },
+                        "product_id": {
+                            "type": "integer",
+                            "example": 3
+                        },
+                        "quantity": {
+                            "type": "integer",
+                            "example": 12
+                        },
+                        "stock": {
+                            "type": "integer",
+                            "example": 5
+                        },
+                        "variant_id": {
+                            "type": "integer",
+                            "example": 70
+                        }
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error_quantity_unavailable"
+                }
+            }
+        },
+        "models.BasketReminderSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "models.BasketReminderSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.BasketReminderSettings"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_basket_reminders_updated"
+                }
+            }
+        },
+        "models.BasketResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.BasketItem"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_item_added_to_basket"
+                }
+            }
+        },
+        "models.CancelOrder": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Ordered by mistake"
+                }
+            }
+        },
+        "models.CatalogImportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.CatalogImportResult"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_catalog_imported"
+                }
+            }
+        },
+        "models.CatalogImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CatalogRowError"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CatalogRowError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategoryListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_all_categories_retrieved"
+                }
+            }
+        },
+        "models.CategoryNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CategoryNode"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Category"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_category_created"
+                }
+            }
+        },
+        "models.CategoryTreeResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CategoryNode"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_category_tree_retrieved"
+                }
+            }
+        },
+        "models.Checkout": {
+            "type": "object",
+            "properties": {
+                "dropped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DroppedBasketItem"
+                    }
+                },
+                "order": {
+                    "$ref": "#/definitions/models.OrderWithProducts"
+                }
+            }
+        },
+        "models.CheckoutResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Checkout"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_checkout"
+                }
+            }
+        },
+        "models.CountInput": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateBasketItem": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateOrder": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateOrderItem"
+                    }
+                },
+                "promo_code": {
+                    "type": "string",
+                    "example": "SUMMER10"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateOrderItem": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "description": "VariantID is required for a product that has variants.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateUser": {
+            "type": "object",
+            "properties": {
+                "telegram_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateWebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order.created",
+                        "order.status_changed"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/shop"
+                }
+            }
+        },
+        "models.DroppedBasketItem": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "out_of_stock"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "string",
+                    "example": "Invalid request body"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error_invalid_request_body"
+                }
+            }
+        },
+        "models.FacetValue": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "value": {
+                    "type": "string",
+                    "example": "black"
+                }
+            }
+        },
+        "models.Favorite": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FavoriteLine": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "image": {
+                    "description": "Image is the first image of the product.",
+                    "type": "string",
+                    "example": "https://example.com/1.jpg"
+                },
+                "in_stock": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Green tea"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 90.25
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "stock": {
+                    "description": "Stock is the variant's stock when the line has a variant.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FavoriteListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FavoriteLine"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_user_favorites_retrieved"
+                }
+            }
+        },
+        "models.FavoriteResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Favorite"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_item_added_to_favorites"
+                }
+            }
+        },
+        "models.Firm": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FirmListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Firm"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_all_firms_retrieved"
+                }
+            }
+        },
+        "models.FirmResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Firm"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_firm_created"
+                }
+            }
+        },
+        "models.ImageInput": {
+            "type": "object",
+            "properties": {
+                "image": {
+                    "type": "string",
+                    "example": "https://example.com/image.jpg"
+                }
+            }
+        },
+        "models.ImagesInput": {
+            "type": "object",
+            "properties": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.InsufficientStockResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "properties": {
+                        "product_ids": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            },
+                            "example": [
+                                3,
+                                7
+                            ]
+                        }
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error_insufficient_stock"
+                }
+            }
+        },
+        "models.InvoiceLink": {
+            "type": "object",
+            "properties": {
+                "invoice_link": {
+                    "type": "string",
+                    "example": "https://t.me/$AbCdEf"
+                },
+                "order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InvoiceLinkResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.InvoiceLink"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_invoice_created"
+                }
+            }
+        },
+        "models.OrderDiscount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is positive and is subtracted from the order total.",
+                    "type": "number"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER10"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "promo_code_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderWithProducts"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6NDJ9"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_all_orders_retrieved"
+                }
+            }
+        },
+        "models.OrderProduct": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderQuote": {
+            "type": "object",
+            "properties": {
+                "discount_amount": {
+                    "type": "number"
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderDiscount"
+                    }
+                },
+                "dropped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DroppedBasketItem"
+                    }
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderProduct"
+                    }
+                },
+                "promo_code": {
+                    "type": "string"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "total_amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.OrderQuoteResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.OrderQuote"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_basket_priced"
+                }
+            }
+        },
+        "models.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.OrderWithProducts"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_order_created"
+                }
+            }
+        },
+        "models.OrderStatusChange": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderWithProducts": {
+            "type": "object",
+            "properties": {
+                "cancel_reason": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "discount_amount": {
+                    "type": "number"
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderDiscount"
+                    }
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderStatusChange"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderProduct"
+                    }
+                },
+                "promo_code": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "description": "TotalAmount is the amount to pay: the lines minus DiscountAmount.",
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OutboxEvent": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "available_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
                     "type": "string"
                 },
-                "product_id": {
-                    "type": "integer"
+                "payload": {
+                    "type": "object"
                 },
-                "quantity": {
-                    "type": "integer"
+                "processed_at": {
+                    "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "models.BasketListResponse": {
+        "models.OutboxEventListResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.BasketItem"
+                        "$ref": "#/definitions/models.OutboxEvent"
                     }
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_user_basket_retrieved"
+                    "example": "success_outbox_events_retrieved"
                 }
             }
         },
-        "models.BasketResponse": {
+        "models.Price": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.BasketItem"
+                "count": {
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string",
-                    "example": "success_item_added_to_basket"
-                }
-            }
-        },
-        "models.Category": {
-            "type": "object",
-            "properties": {
                 "id": {
                     "type": "integer"
                 },
-                "image": {
-                    "type": "string"
+                "price": {
+                    "type": "number"
                 },
-                "name": {
-                    "type": "string"
+                "product_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.CategoryListResponse": {
+        "models.PriceListResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Category"
+                        "$ref": "#/definitions/models.Price"
                     }
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_all_categories_retrieved"
+                    "example": "success_prices_retrieved"
                 }
             }
         },
-        "models.CategoryResponse": {
+        "models.PriceResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/models.Category"
+                    "$ref": "#/definitions/models.Price"
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_category_created"
+                    "example": "success_price_created"
                 }
             }
         },
-        "models.CountInput": {
+        "models.Product": {
             "type": "object",
             "properties": {
-                "count": {
+                "attributes": {
+                    "type": "object"
+                },
+                "category_id": {
                     "type": "integer"
-                }
-            }
-        },
-        "models.CreateOrder": {
-            "type": "object",
-            "properties": {
-                "items": {
+                },
+                "description": {
+                    "type": "string"
+                },
+                "firm_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
                     "type": "array",
                     "items": {
-                        "type": "object",
-                        "properties": {
-                            "product_id": {
-                                "type": "integer"
-                            },
-                            "quantity": {
-                                "type": "integer"
-                            }
-                        }
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"https://example.com/1.jpg\"",
+                        "\"https://example.com/2.jpg\"]"
+                    ]
                 },
-                "user_id": {
+                "name": {
+                    "type": "string"
+                },
+                "sell_count": {
+                    "type": "integer"
+                },
+                "stock": {
                     "type": "integer"
                 }
             }
         },
-        "models.CreateUser": {
+        "models.ProductFacet": {
             "type": "object",
             "properties": {
-                "telegram_id": {
-                    "type": "integer"
+                "key": {
+                    "type": "string",
+                    "example": "color"
                 },
-                "username": {
-                    "type": "string"
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FacetValue"
+                    }
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.ProductFacetListResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "string",
-                    "example": "Invalid request body"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductFacet"
+                    }
                 },
                 "status": {
                     "type": "string",
-                    "example": "error_invalid_request_body"
+                    "example": "success_facets_retrieved"
                 }
             }
         },
-        "models.Favorite": {
+        "models.ProductListResponse": {
             "type": "object",
             "properties": {
-                "added_at": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
                 },
-                "product_id": {
-                    "type": "integer"
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6NDJ9"
                 },
-                "user_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string",
+                    "example": "success_all_products_retrieved"
                 }
             }
         },
-        "models.FavoriteListResponse": {
+        "models.ProductResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Favorite"
-                    }
+                    "$ref": "#/definitions/models.Product"
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_user_favorites_retrieved"
+                    "example": "success_product_created"
                 }
             }
         },
-        "models.FavoriteResponse": {
+        "models.ProductSearchResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/models.Favorite"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductSearchResult"
+                    }
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_item_added_to_favorites"
+                    "example": "success_products_found"
                 }
             }
         },
-        "models.Firm": {
+        "models.ProductSearchResult": {
             "type": "object",
             "properties": {
-                "id": {
+                "attributes": {
+                    "type": "object"
+                },
+                "category_id": {
                     "type": "integer"
                 },
-                "name": {
+                "description": {
                     "type": "string"
-                }
-            }
-        },
-        "models.FirmListResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
+                },
+                "firm_id": {
+                    "type": "integer"
+                },
+                "fuzzy": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Firm"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"https://example.com/1.jpg\"",
+                        "\"https://example.com/2.jpg\"]"
+                    ]
                 },
-                "status": {
+                "name": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.6
+                },
+                "sell_count": {
+                    "type": "integer"
+                },
+                "snippet": {
                     "type": "string",
-                    "example": "success_all_firms_retrieved"
+                    "example": "\u003cmark\u003eСмартфон\u003c/mark\u003e Apple"
+                },
+                "stock": {
+                    "type": "integer"
                 }
             }
         },
-        "models.FirmResponse": {
+        "models.ProductVariant": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.Firm"
+                "attributes": {
+                    "type": "object"
                 },
-                "status": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "Price overrides the product's price tiers when set.",
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "sku": {
                     "type": "string",
-                    "example": "success_firm_created"
+                    "example": "AIRMAX-42-BLK"
+                },
+                "stock": {
+                    "type": "integer"
                 }
             }
         },
-        "models.ImageInput": {
+        "models.ProductVariantInput": {
             "type": "object",
             "properties": {
-                "image": {
+                "attributes": {
+                    "type": "object"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "sku": {
                     "type": "string",
-                    "example": "https://example.com/image.jpg"
+                    "example": "AIRMAX-42-BLK"
+                },
+                "stock": {
+                    "type": "integer"
                 }
             }
         },
-        "models.ImagesInput": {
+        "models.ProductVariantListResponse": {
             "type": "object",
             "properties": {
-                "images": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.ProductVariant"
                     }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_variants_retrieved"
                 }
             }
         },
-        "models.OrderListResponse": {
+        "models.ProductVariantResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.OrderWithProducts"
-                    }
+                    "$ref": "#/definitions/models.ProductVariant"
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_all_orders_retrieved"
+                    "example": "success_variant_created"
                 }
             }
         },
-        "models.OrderProduct": {
+        "models.PromoCode": {
             "type": "object",
             "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "category_id": {
+                    "description": "CategoryID and FirmID limit a percent or fixed code to products of the category subtree or the firm.",
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER10"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "firm_id": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "order_id": {
+                "kind": {
+                    "type": "string",
+                    "example": "percent"
+                },
+                "max_uses": {
                     "type": "integer"
                 },
-                "price": {
+                "max_uses_per_user": {
+                    "type": "integer"
+                },
+                "min_order_total": {
                     "type": "number"
                 },
-                "product_id": {
+                "product_id": {
+                    "description": "ProductID is the product a free_item code gives away.",
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "uses": {
+                    "description": "Uses counts the orders placed with the code, cancelled and refunded ones excluded.",
                     "type": "integer"
                 },
-                "quantity": {
-                    "type": "integer"
+                "value": {
+                    "type": "number",
+                    "example": 10
                 }
             }
         },
-        "models.OrderResponse": {
+        "models.PromoCodeErrorResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/models.OrderWithProducts"
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "string",
+                            "example": "SUMMER10"
+                        },
+                        "reason": {
+                            "type": "string",
+                            "example": "expired"
+                        }
+                    }
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_order_created"
+                    "example": "error_invalid_promo_code"
                 }
             }
         },
-        "models.OrderWithProducts": {
+        "models.PromoCodeInput": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "active": {
+                    "description": "Active defaults to true.",
+                    "type": "boolean"
                 },
-                "id": {
+                "category_id": {
                     "type": "integer"
                 },
-                "products": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.OrderProduct"
-                    }
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER10"
                 },
-                "status": {
+                "ends_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "firm_id": {
                     "type": "integer"
-                }
-            }
-        },
-        "models.Price": {
-            "type": "object",
-            "properties": {
-                "count": {
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "percent"
+                },
+                "max_uses": {
                     "type": "integer"
                 },
-                "id": {
+                "max_uses_per_user": {
                     "type": "integer"
                 },
-                "price": {
+                "min_order_total": {
                     "type": "number"
                 },
                 "product_id": {
                     "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 10
                 }
             }
         },
-        "models.PriceListResponse": {
+        "models.PromoCodeListResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Price"
+                        "$ref": "#/definitions/models.PromoCode"
                     }
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_prices_retrieved"
+                    "example": "success_promo_codes_retrieved"
                 }
             }
         },
-        "models.PriceResponse": {
+        "models.PromoCodeResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/models.Price"
+                    "$ref": "#/definitions/models.PromoCode"
                 },
                 "status": {
                     "type": "string",
-                    "example": "success_price_created"
+                    "example": "success_promo_code_created"
                 }
             }
         },
-        "models.Product": {
+        "models.ReplaceBasket": {
             "type": "object",
             "properties": {
-                "attributes": {
-                    "type": "object"
-                },
-                "category_id": {
-                    "type": "integer"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "firm_id": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "image": {
+                "items": {
                     "type": "array",
                     "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "[\"https://example.com/1.jpg\"",
-                        "\"https://example.com/2.jpg\"]"
-                    ]
-                },
-                "name": {
-                    "type": "string"
-                },
-                "sell_count": {
-                    "type": "integer"
-                },
+                        "$ref": "#/definitions/models.CreateBasketItem"
+                    }
+                }
+            }
+        },
+        "models.StockInput": {
+            "type": "object",
+            "properties": {
                 "stock": {
                     "type": "integer"
                 }
             }
         },
-        "models.ProductListResponse": {
+        "models.SuccessResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Product"
-                    }
-                },
+                "data": {},
                 "status": {
                     "type": "string",
-                    "example": "success_all_products_retrieved"
+                    "example": "success_operation_completed"
                 }
             }
         },
-        "models.ProductResponse": {
+        "models.Suggestion": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.Product"
+                "id": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "status": {
+                "name": {
                     "type": "string",
-                    "example": "success_product_created"
+                    "example": "iPhone 13"
+                },
+                "sell_count": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "type": {
+                    "type": "string",
+                    "example": "product"
                 }
             }
         },
-        "models.StockInput": {
+        "models.SuggestionListResponse": {
             "type": "object",
             "properties": {
-                "stock": {
-                    "type": "integer"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_suggestions_found"
                 }
             }
         },
-        "models.SuccessResponse": {
+        "models.UnavailableBasketResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "type": "object",
+                    "properties": {
+                        "dropped": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DroppedBasketItem"
+                            }
+                        }
+                    }
+                },
                 "status": {
                     "type": "string",
-                    "example": "success_operation_completed"
+                    "example": "error_basket_unavailable"
                 }
             }
         },
@@ -2360,6 +6461,9 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -2371,6 +6475,19 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateOrderStatus": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Payment confirmed by manager"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "paid"
+                }
+            }
+        },
         "models.UpdatePriceCount": {
             "type": "object",
             "properties": {
@@ -2425,6 +6542,57 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateWebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "product.stock_low"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "n3w-s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/shop"
+                }
+            }
+        },
+        "models.UploadedImage": {
+            "type": "object",
+            "properties": {
+                "thumbnail_url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/uploads/images/3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b_thumb.jpg"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/uploads/images/3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b.jpg"
+                }
+            }
+        },
+        "models.UploadedImageResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.UploadedImage"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_product_image_uploaded"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -2451,6 +6619,10 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.User"
                     }
                 },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6NDJ9"
+                },
                 "status": {
                     "type": "string",
                     "example": "success_all_users_retrieved"
@@ -2468,11 +6640,128 @@ const docTemplate = `{
                     "example": "success_user_created"
                 }
             }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDeliveryListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_webhook_deliveries_retrieved"
+                }
+            }
+        },
+        "models.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order.created",
+                        "order.status_changed"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/shop"
+                }
+            }
+        },
+        "models.WebhookSubscriptionListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookSubscription"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_webhooks_retrieved"
+                }
+            }
+        },
+        "models.WebhookSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.WebhookSubscription"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success_webhook_created"
+                }
+            }
         }
     },
     "securityDefinitions": {
         "BasicAuth": {
             "type": "basic"
+        },
+        "TelegramAuth": {
+            "description": "Telegram.WebApp.initData in the form \"tma \u003cinitData\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     },
     "externalDocs": {
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/telegram"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// UpdateHandler processes the parts of an update it is interested in and ignores the rest.
+type UpdateHandler interface {
+	HandleUpdate(ctx context.Context, update telegram.Update) error
+}
+
+// Bot receives webhook updates and passes each one to every registered handler.
+type Bot struct {
+	secretToken string
+	handlers    []UpdateHandler
+}
+
+// New creates the webhook receiver. secretToken must match the secret_token passed to setWebhook;
+// an empty token rejects every request.
+func New(secretToken string, handlers ...UpdateHandler) *Bot {
+	return &Bot{secretToken: secretToken, handlers: handlers}
+}
+
+func (b *Bot) InitRouter(app *fiber.App) {
+	app.Post("/api/v1/telegram/webhook", b.Webhook)
+}
+
+// Webhook receives updates from the Telegram Bot API
+// @Summary Telegram webhook
+// @Description Receives bot updates and dispatches them to the bot handlers (payments: pre_checkout_query and successful_payment). Requests must carry the secret token configured with setWebhook
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param X-Telegram-Bot-Api-Secret-Token header string true "Webhook secret token"
+// @Param update body object true "Telegram update"
+// @Success 200 {object} models.SuccessResponse "Update processed"
+// @Failure 400 {object} models.ErrorResponse "Invalid update"
+// @Failure 401 {object} models.ErrorResponse "Invalid secret token"
+// @Failure 500 {object} models.ErrorResponse "Update could not be processed, Telegram will redeliver it"
+// @Router /api/v1/telegram/webhook [post]
+func (b *Bot) Webhook(c *fiber.Ctx) error {
+	secret := c.Get(secretTokenHeader)
+	if b.secretToken == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(b.secretToken)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(web.ErrorResp("error_unauthorized", "Invalid webhook secret token"))
+	}
+
+	var update telegram.Update
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid update"))
+	}
+
+	for _, handler := range b.handlers {
+		if err := handler.HandleUpdate(c.Context(), update); err != nil {
+			logger.Errorf("[Webhook] Error handling update %d: %v", update.UpdateID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_handle_update", err.Error()))
+		}
+	}
+
+	return c.JSON(web.OkResp("success_update_processed", nil))
+}
@@ -0,0 +1,48 @@
+package bot_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/bot"
+	"telegramshop_backend/pkg/telegram"
+)
+
+type recordingHandler struct {
+	updates []telegram.Update
+}
+
+func (h *recordingHandler) HandleUpdate(ctx context.Context, update telegram.Update) error {
+	h.updates = append(h.updates, update)
+	return nil
+}
+
+func TestWebhook(t *testing.T) {
+	handler := &recordingHandler{}
+	app := fiber.New()
+	bot.New("s3cret", handler).InitRouter(app)
+
+	post := func(secret string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/api/v1/telegram/webhook", strings.NewReader(`{"update_id":5,"pre_checkout_query":{"id":"q"}}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if secret != "" {
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	require.Equal(t, fiber.StatusUnauthorized, post(""))
+	require.Equal(t, fiber.StatusUnauthorized, post("wrong"))
+	require.Empty(t, handler.updates)
+
+	require.Equal(t, fiber.StatusOK, post("s3cret"))
+	require.Len(t, handler.updates, 1)
+	require.Equal(t, "q", handler.updates[0].PreCheckoutQuery.ID)
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/admins"
+	"telegramshop_backend/internal/repository/orders"
+	"telegramshop_backend/internal/repository/users"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/telegram"
+)
+
+// Notifier sends order notifications to the customer and to every admin. It is an outbox
+// handler: messages are sent while the event is processed, and a failed send fails the event so
+// the outbox retries it with backoff and dead-letters it in the end. Chats Telegram refuses for
+// good, such as a user who blocked the bot, are skipped instead, since retrying cannot reach them.
+type Notifier struct {
+	api    telegram.BotAPI
+	users  users.Repository
+	admins admins.Repository
+	orders orders.Repository
+}
+
+func NewNotifier(api telegram.BotAPI, usersRepo users.Repository, adminsRepo admins.Repository, ordersRepo orders.Repository) *Notifier {
+	return &Notifier{
+		api:    api,
+		users:  usersRepo,
+		admins: adminsRepo,
+		orders: ordersRepo,
+	}
+}
+
+// HandleOrderEvent is the outbox handler for order.created and order.status_changed.
+func (n *Notifier) HandleOrderEvent(ctx context.Context, outboxEvent models.OutboxEvent) error {
+	var payload models.OrderEvent
+	if err := json.Unmarshal(outboxEvent.Payload, &payload); err != nil {
+		return fmt.Errorf("decode %s payload: %w", outboxEvent.EventType, err)
+	}
+
+	order, err := n.orders.GetOrderByID(ctx, int(payload.OrderID))
+	if err != nil {
+		return err
+	}
+	if order.ID == 0 {
+		logger.Infof("[Notifier] Order %d no longer exists, skipping notification", payload.OrderID)
+		return nil
+	}
+	// Describe the change itself, not whatever status the order has reached since.
+	order.Status = payload.To
+
+	from := payload.From
+	if outboxEvent.EventType == models.EventOrderCreated {
+		from = ""
+	}
+	return n.notify(ctx, order, from)
+}
+
+// notify messages the customer and the admins; from is empty for a new order. Every recipient is
+// tried even if an earlier send failed, so a retried event may repeat messages already delivered.
+func (n *Notifier) notify(ctx context.Context, order models.OrderWithProducts, from string) error {
+	customer, err := n.users.GetUserByInternalID(ctx, order.UserID)
+	if err != nil {
+		return fmt.Errorf("get customer of order %d: %w", order.ID, err)
+	}
+	staff, err := n.admins.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("get admins: %w", err)
+	}
+
+	customerTemplate, adminTemplate := "customer_order_created", "admin_order_created"
+	if from != "" {
+		customerTemplate, adminTemplate = "customer_status_changed", "admin_status_changed"
+	}
+	data := templateData{Order: order, From: from, Customer: customer}
+
+	var errs []error
+	if customer.TelegramID != 0 {
+		errs = append(errs, n.send(ctx, customer.TelegramID, customerTemplate, data))
+	}
+	for _, admin := range staff {
+		if admin.ID == customer.ID {
+			continue
+		}
+		errs = append(errs, n.send(ctx, admin.TelegramID, adminTemplate, data))
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) send(ctx context.Context, chatID int64, name string, data templateData) error {
+	text, err := render(name, data)
+	if err != nil {
+		return fmt.Errorf("render %s: %w", name, err)
+	}
+	err = n.api.SendMessage(ctx, chatID, text)
+	var apiErr *telegram.APIError
+	if errors.As(err, &apiErr) && apiErr.Permanent() {
+		logger.Infof("[Notifier] Chat %d cannot receive %s, skipping: %v", chatID, name, err)
+		return nil
+	}
+	if err != nil {
+		logger.Errorf("[Notifier] Error sending %s to chat %d: %v", name, chatID, err)
+		return fmt.Errorf("send %s to chat %d: %w", name, chatID, err)
+	}
+	return nil
+}
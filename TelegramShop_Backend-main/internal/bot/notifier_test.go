@@ -0,0 +1,105 @@
+package bot_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/bot"
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/admins"
+	"telegramshop_backend/internal/repository/orders"
+	"telegramshop_backend/internal/repository/users"
+	"telegramshop_backend/pkg/telegram"
+)
+
+// flakyBot fails the first failures calls of every chat and records delivered messages.
+type flakyBot struct {
+	telegram.BotAPI
+	mu        sync.Mutex
+	failures  int
+	attempts  map[int64]int
+	delivered map[int64][]string
+}
+
+func (b *flakyBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts[chatID]++
+	if b.attempts[chatID] <= b.failures {
+		return errors.New("temporary failure")
+	}
+	b.delivered[chatID] = append(b.delivered[chatID], text)
+	return nil
+}
+
+type fakeUsers struct {
+	users.Repository
+}
+
+func (fakeUsers) GetUserByInternalID(ctx context.Context, id int64) (models.User, error) {
+	return models.User{ID: id, TelegramID: 1000 + id, Username: "<buyer>"}, nil
+}
+
+type fakeAdmins struct {
+	admins.Repository
+}
+
+func (fakeAdmins) GetAll(ctx context.Context) ([]models.User, error) {
+	return []models.User{{ID: 2, TelegramID: 2002}, {ID: 3, TelegramID: 2003}}, nil
+}
+
+type fakeOrders struct {
+	orders.Repository
+}
+
+func (fakeOrders) GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error) {
+	if id != 9 {
+		return models.OrderWithProducts{}, nil
+	}
+	return models.OrderWithProducts{ID: 9, UserID: 1, Status: models.OrderStatusPaid, TotalAmount: 150}, nil
+}
+
+func orderEvent(t *testing.T, eventType string, payload models.OrderEvent) models.OutboxEvent {
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return models.OutboxEvent{EventType: eventType, Payload: raw}
+}
+
+func TestNotifier(t *testing.T) {
+	api := &flakyBot{failures: 1, attempts: map[int64]int{}, delivered: map[int64][]string{}}
+	notifier := bot.NewNotifier(api, fakeUsers{}, fakeAdmins{}, fakeOrders{})
+	ctx := context.Background()
+	created := orderEvent(t, models.EventOrderCreated, models.OrderEvent{OrderID: 9, UserID: 1, To: models.OrderStatusPending})
+
+	// A failed send fails the event, so the outbox retries it.
+	require.Error(t, notifier.HandleOrderEvent(ctx, created))
+	require.NoError(t, notifier.HandleOrderEvent(ctx, created))
+
+	require.Equal(t, 2, api.attempts[1001])
+	require.Contains(t, api.delivered[1001][0], "Заказ #9 оформлен")
+	require.Contains(t, api.delivered[1001][0], "ожидает оплаты", "the status of the event is reported, not the current one")
+	require.Contains(t, api.delivered[2002][0], "Новый заказ #9")
+	require.True(t, strings.Contains(api.delivered[2003][0], "&lt;buyer&gt;"), "username must be escaped")
+
+	t.Run("StatusChanged", func(t *testing.T) {
+		changed := orderEvent(t, models.EventOrderStatusChanged, models.OrderEvent{OrderID: 9, UserID: 1, From: models.OrderStatusPending, To: models.OrderStatusPaid})
+		require.NoError(t, notifier.HandleOrderEvent(ctx, changed))
+		require.Contains(t, api.delivered[2002][1], "ожидает оплаты → оплачен")
+	})
+
+	t.Run("BlockedChat", func(t *testing.T) {
+		blocked := bot.NewNotifier(blockedBot{}, fakeUsers{}, fakeAdmins{}, fakeOrders{})
+		require.NoError(t, blocked.HandleOrderEvent(ctx, created), "chats that refuse the bot are not retried")
+	})
+
+	t.Run("DeletedOrder", func(t *testing.T) {
+		require.NoError(t, notifier.HandleOrderEvent(ctx, orderEvent(t, models.EventOrderCreated, models.OrderEvent{OrderID: 10, To: models.OrderStatusPending})))
+	})
+}
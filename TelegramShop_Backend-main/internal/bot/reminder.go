@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/reminders"
+	"telegramshop_backend/pkg/telegram"
+)
+
+// BasketReminder reminds users about abandoned baskets with a bot message. It does not retry:
+// the reminders job retries on its next run unless Telegram refused the message for good.
+type BasketReminder struct {
+	api telegram.BotAPI
+}
+
+func NewBasketReminder(api telegram.BotAPI) *BasketReminder {
+	return &BasketReminder{api: api}
+}
+
+func (r *BasketReminder) RemindBasket(ctx context.Context, basket models.IdleBasket) error {
+	text, err := render("basket_reminder", templateData{Basket: basket})
+	if err != nil {
+		return err
+	}
+	err = r.api.SendMessage(ctx, basket.TelegramID, text)
+	var apiErr *telegram.APIError
+	if errors.As(err, &apiErr) && apiErr.Permanent() {
+		return fmt.Errorf("%w: %v", reminders.ErrUndeliverable, err)
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/bot"
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/reminders"
+	"telegramshop_backend/pkg/telegram"
+)
+
+// blockedBot answers like Telegram does for a user who blocked the bot.
+type blockedBot struct {
+	telegram.BotAPI
+}
+
+func (blockedBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return &telegram.APIError{Code: 403, Description: "Forbidden: bot was blocked by the user"}
+}
+
+func TestBasketReminder(t *testing.T) {
+	api := &flakyBot{attempts: map[int64]int{}, delivered: map[int64][]string{}}
+
+	err := bot.NewBasketReminder(api).RemindBasket(context.Background(), models.IdleBasket{UserID: 1, TelegramID: 1001, Lines: 2, Quantity: 3})
+	require.NoError(t, err)
+	require.Len(t, api.delivered[1001], 1)
+	require.Contains(t, api.delivered[1001][0], "Товаров: 3")
+
+	err = bot.NewBasketReminder(blockedBot{}).RemindBasket(context.Background(), models.IdleBasket{UserID: 1, TelegramID: 1001})
+	require.ErrorIs(t, err, reminders.ErrUndeliverable)
+
+	err = bot.NewBasketReminder(&flakyBot{failures: 1, attempts: map[int64]int{}}).RemindBasket(context.Background(), models.IdleBasket{UserID: 1, TelegramID: 1001})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, reminders.ErrUndeliverable)
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"bytes"
+	"html/template"
+
+	"telegramshop_backend/internal/models"
+)
+
+var statusTitles = map[string]string{
+	models.OrderStatusPending:    "ожидает оплаты",
+	models.OrderStatusPaid:       "оплачен",
+	models.OrderStatusAssembling: "собирается",
+	models.OrderStatusShipped:    "отправлен",
+	models.OrderStatusDelivered:  "доставлен",
+	models.OrderStatusCancelled:  "отменён",
+	models.OrderStatusRefunded:   "возвращён",
+}
+
+// html/template escapes user-provided values for Telegram's HTML parse mode.
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"status": func(status string) string {
+		if title, ok := statusTitles[status]; ok {
+			return title
+		}
+		return status
+	},
+}).Parse(`
+{{define "customer_order_created"}}<b>Заказ #{{.Order.ID}} оформлен</b>
+Товаров: {{len .Order.Products}}, сумма: {{printf "%.2f" .Order.TotalAmount}} ₽
+Статус: {{status .Order.Status}}{{end}}
+
+{{define "admin_order_created"}}<b>Новый заказ #{{.Order.ID}}</b>
+Покупатель: {{.Customer.Username}} (id {{.Customer.TelegramID}})
+Товаров: {{len .Order.Products}}, сумма: {{printf "%.2f" .Order.TotalAmount}} ₽{{end}}
+
+{{define "customer_status_changed"}}<b>Заказ #{{.Order.ID}}</b>: {{status .Order.Status}}{{if .Order.CancelReason}}
+Причина: {{.Order.CancelReason}}{{end}}{{end}}
+
+{{define "admin_status_changed"}}<b>Заказ #{{.Order.ID}}</b>: {{status .From}} → {{status .Order.Status}}
+Покупатель: {{.Customer.Username}} (id {{.Customer.TelegramID}}){{end}}
+
+{{define "basket_reminder"}}<b>Вы забыли товары в корзине</b>
+Товаров: {{.Basket.Quantity}}. Загляните в магазин, чтобы оформить заказ.{{end}}
+`))
+
+type templateData struct {
+	Order    models.OrderWithProducts
+	From     string
+	Customer models.User
+	Basket   models.IdleBasket
+}
+
+func render(name string, data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
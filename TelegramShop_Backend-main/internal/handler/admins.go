@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegramshop_backend/internal/service/admins"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAdmin rejects requests from users missing in the admins table.
+// Must be mounted after TelegramAuth.
+func (h *Handler) RequireAdmin(c *fiber.Ctx) error {
+	isAdmin, err := h.adminService.IsAdmin(c.Context(), currentUser(c).ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_check_admin", err.Error()))
+	}
+	if !isAdmin {
+		return c.Status(fiber.StatusForbidden).JSON(web.ErrorResp("error_forbidden", "Admin rights required"))
+	}
+
+	return c.Next()
+}
+
+// RequireSelfOrAdmin rejects requests whose :id path parameter is not the authenticated user,
+// unless the user is an admin. Must be mounted after TelegramAuth.
+func (h *Handler) RequireSelfOrAdmin(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
+	}
+	if userID == currentUser(c).ID {
+		return c.Next()
+	}
+
+	isAdmin, err := h.adminService.IsAdmin(c.Context(), currentUser(c).ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_check_admin", err.Error()))
+	}
+	if !isAdmin {
+		return c.Status(fiber.StatusForbidden).JSON(web.ErrorResp("error_forbidden", "Access to another user's data is not allowed"))
+	}
+
+	return c.Next()
+}
+
+// GetAdmins retrieves all admins
+// @Summary Get all admins
+// @Description Returns all users with admin rights
+// @Tags admins
+// @Produce json
+// @Success 200 {object} models.UserListResponse "All admins retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/admins [get]
+func (h *Handler) GetAdmins(c *fiber.Ctx) error {
+	admins, err := h.adminService.GetAdmins(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_admins", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_admins_retrieved", admins))
+}
+
+// GrantAdmin grants admin rights to a user
+// @Summary Grant admin rights
+// @Description Grants admin rights to the user with the given Telegram ID
+// @Tags admins
+// @Produce json
+// @Param id path int true "User Telegram ID"
+// @Success 200 {object} models.UserResponse "Admin rights granted"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/admins/{id} [post]
+func (h *Handler) GrantAdmin(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
+	}
+
+	user, err := h.adminService.GrantAdmin(c.Context(), id)
+	if errors.Is(err, admins.ErrUserNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_user_not_found", err.Error()))
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_grant_admin", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_admin_granted", user))
+}
+
+// RevokeAdmin revokes admin rights from a user
+// @Summary Revoke admin rights
+// @Description Revokes admin rights from the user with the given Telegram ID
+// @Tags admins
+// @Produce json
+// @Param id path int true "User Telegram ID"
+// @Success 200 {object} models.SuccessResponse "Admin rights revoked"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/admins/{id} [delete]
+func (h *Handler) RevokeAdmin(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
+	}
+
+	if id == currentUser(c).TelegramID {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_revoke_self", "Admins cannot revoke their own rights"))
+	}
+
+	err = h.adminService.RevokeAdmin(c.Context(), id)
+	if errors.Is(err, admins.ErrUserNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_user_not_found", err.Error()))
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_revoke_admin", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_admin_revoked", nil))
+}
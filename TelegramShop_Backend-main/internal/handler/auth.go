@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/telegram"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	initDataScheme = "tma "
+	userLocalsKey  = "user"
+)
+
+type AuthConfig struct {
+	BotToken string
+	// MaxAge limits how old initData's auth_date may be. Zero disables the check.
+	MaxAge time.Duration
+}
+
+// TelegramAuth validates Telegram.WebApp.initData passed as "Authorization: tma <initData>"
+// and stores the verified user in the request context.
+func (h *Handler) TelegramAuth(c *fiber.Ctx) error {
+	// Without a token anyone could sign initData with the empty key, so nothing is trusted.
+	if h.auth.BotToken == "" {
+		logger.Errorf("[TelegramAuth] Bot token is not configured, rejecting request")
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_auth_not_configured", "Telegram authentication is not configured"))
+	}
+
+	header := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(header, initDataScheme) {
+		return c.Status(fiber.StatusUnauthorized).JSON(web.ErrorResp("error_unauthorized", "Missing Telegram init data"))
+	}
+
+	data, err := telegram.ValidateInitData(strings.TrimPrefix(header, initDataScheme), h.auth.BotToken, h.auth.MaxAge)
+	if err != nil {
+		logger.Errorf("[TelegramAuth] Invalid init data: %v", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(web.ErrorResp("error_unauthorized", err.Error()))
+	}
+
+	user, err := h.userService.EnsureUser(c.Context(), models.CreateUser{
+		TelegramID: data.User.ID,
+		Username:   data.User.Username,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_user", err.Error()))
+	}
+
+	c.Locals(userLocalsKey, user)
+	return c.Next()
+}
+
+// RequireSelf rejects requests whose :user_id path parameter is not the authenticated user.
+// Must be mounted after TelegramAuth.
+func (h *Handler) RequireSelf(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
+	}
+
+	if userID != currentUser(c).ID {
+		return c.Status(fiber.StatusForbidden).JSON(web.ErrorResp("error_forbidden", "Access to another user's data is not allowed"))
+	}
+
+	return c.Next()
+}
+
+func currentUser(c *fiber.Ctx) models.User {
+	user, _ := c.Locals(userLocalsKey).(models.User)
+	return user
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/users"
+	"telegramshop_backend/pkg/pagination"
+	"telegramshop_backend/pkg/telegram"
+	"telegramshop_backend/pkg/web"
+)
+
+const testBotToken = "123456:TEST-bot-token"
+
+type stubUserService struct {
+	users map[int64]models.User
+}
+
+func (s *stubUserService) GetUserByID(ctx context.Context, id int64) (models.User, error) {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return models.User{}, users.ErrUserNotFound
+}
+
+func (s *stubUserService) CreateUser(ctx context.Context, input models.CreateUser) (models.User, error) {
+	return s.EnsureUser(ctx, input)
+}
+
+func (s *stubUserService) GetAll(ctx context.Context, page pagination.Page) ([]models.User, string, error) {
+	return nil, "", nil
+}
+
+func (s *stubUserService) DeleteUser(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (s *stubUserService) EnsureUser(ctx context.Context, input models.CreateUser) (models.User, error) {
+	if user, ok := s.users[input.TelegramID]; ok {
+		return user, nil
+	}
+	user := models.User{ID: int64(len(s.users) + 1), TelegramID: input.TelegramID, Username: input.Username}
+	s.users[input.TelegramID] = user
+	return user, nil
+}
+
+type stubAdminService struct {
+	admins map[int64]bool
+}
+
+func (s *stubAdminService) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	return s.admins[userID], nil
+}
+
+func (s *stubAdminService) GetAdmins(ctx context.Context) ([]models.User, error) {
+	return nil, nil
+}
+
+func (s *stubAdminService) GrantAdmin(ctx context.Context, telegramID int64) (models.User, error) {
+	return models.User{}, nil
+}
+
+func (s *stubAdminService) RevokeAdmin(ctx context.Context, telegramID int64) error {
+	return nil
+}
+
+func initDataHeader(telegramID int64, authDate time.Time) string {
+	values := url.Values{}
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("user", `{"id":`+strconv.FormatInt(telegramID, 10)+`,"username":"u`+strconv.FormatInt(telegramID, 10)+`"}`)
+	values.Set("hash", telegram.SignInitData(values, testBotToken))
+	return "tma " + values.Encode()
+}
+
+func newAuthTestApp() *fiber.App {
+	h := &Handler{
+		userService: &stubUserService{users: map[int64]models.User{
+			111: {ID: 1, TelegramID: 111, Username: "alice"},
+			222: {ID: 2, TelegramID: 222, Username: "bob"},
+		}},
+		adminService: &stubAdminService{admins: map[int64]bool{2: true}},
+		auth:         AuthConfig{BotToken: testBotToken, MaxAge: time.Hour},
+	}
+
+	app := fiber.New()
+	app.Get("/me", h.TelegramAuth, h.GetCurrentUser)
+	app.Get("/basket/:user_id", h.TelegramAuth, h.RequireSelf, func(c *fiber.Ctx) error {
+		return c.JSON(web.OkResp("ok", currentUser(c).ID))
+	})
+	app.Get("/users/:id", h.TelegramAuth, h.RequireSelfOrAdmin, h.GetUser)
+	app.Delete("/firms/:id", h.TelegramAuth, h.RequireAdmin, func(c *fiber.Ctx) error {
+		return c.JSON(web.OkResp("ok", nil))
+	})
+	return app
+}
+
+func TestTelegramAuth(t *testing.T) {
+	app := newAuthTestApp()
+
+	t.Run("MissingHeader", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/me", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("ForgedSignature", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "tma auth_date=1&user=%7B%22id%22%3A111%7D&hash=deadbeef")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(111, time.Now().Add(-2*time.Hour)))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("KnownUser", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(111, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data models.User `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Equal(t, int64(1), body.Data.ID)
+	})
+
+	t.Run("NewUserRegistered", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(333, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("OwnBasket", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/basket/1", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(111, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("ForeignBasket", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/basket/2", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(111, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestTelegramAuthWithoutBotToken(t *testing.T) {
+	h := &Handler{
+		userService: &stubUserService{users: map[int64]models.User{}},
+		auth:        AuthConfig{MaxAge: time.Hour},
+	}
+	app := fiber.New()
+	app.Get("/me", h.TelegramAuth, h.GetCurrentUser)
+
+	values := url.Values{}
+	values.Set("auth_date", strconv.FormatInt(time.Now().Unix(), 10))
+	values.Set("user", `{"id":111,"username":"alice"}`)
+	values.Set("hash", telegram.SignInitData(values, ""))
+
+	req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "tma "+values.Encode())
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode, "init data signed with an empty token must not be trusted")
+}
+
+func TestRequireAdmin(t *testing.T) {
+	app := newAuthTestApp()
+
+	t.Run("Customer", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodDelete, "/firms/1", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(111, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+		var body web.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Equal(t, "error_forbidden", body.Status)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodDelete, "/firms/1", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(222, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestRequireSelfOrAdmin(t *testing.T) {
+	app := newAuthTestApp()
+
+	for name, tc := range map[string]struct {
+		telegramID int64
+		path       string
+		status     int
+	}{
+		"Self":         {telegramID: 111, path: "/users/1", status: fiber.StatusOK},
+		"AnotherUser":  {telegramID: 111, path: "/users/2", status: fiber.StatusForbidden},
+		"Admin":        {telegramID: 222, path: "/users/1", status: fiber.StatusOK},
+		"UnknownUser":  {telegramID: 222, path: "/users/9", status: fiber.StatusNotFound},
+		"InvalidID":    {telegramID: 111, path: "/users/me2", status: fiber.StatusBadRequest},
+		"Unauthorized": {path: "/users/1", status: fiber.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+			if tc.telegramID != 0 {
+				req.Header.Set(fiber.HeaderAuthorization, initDataHeader(tc.telegramID, time.Now()))
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+
+	t.Run("ReturnsUserByInternalID", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/users/1", nil)
+		req.Header.Set(fiber.HeaderAuthorization, initDataHeader(111, time.Now()))
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var body struct {
+			Data models.User `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Equal(t, int64(111), body.Data.TelegramID)
+	})
+}
@@ -1,34 +1,61 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/basket"
 	"telegramshop_backend/pkg/web"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// basketErrorResp maps basket service errors to a status code and response.
+func basketErrorResp(c *fiber.Ctx, err error, fallbackStatus string) error {
+	var quantityErr *basket.QuantityError
+	if errors.As(err, &quantityErr) {
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_quantity_unavailable", quantityErr))
+	}
+
+	switch {
+	case errors.Is(err, basket.ErrInvalidQuantity):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_quantity", err.Error()))
+	case errors.Is(err, basket.ErrProductNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_product_not_found", err.Error()))
+	case errors.Is(err, basket.ErrVariantNotFound), errors.Is(err, basket.ErrVariantRequired):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(web.ErrorResp("error_invalid_variant", err.Error()))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp(fallbackStatus, err.Error()))
+	}
+}
+
 // AddToBasket adds item to user's basket
 // @Summary Add item to basket
-// @Description Adds a product to user's basket
+// @Description Adds a product to user's basket. Adding a product and variant that is already in the basket increases its quantity. The resulting quantity must be positive and may not exceed the stock or the per-line limit
 // @Tags basket
 // @Accept json
 // @Produce json
 // @Param item body models.BasketItem true "Basket item data"
-// @Success 200 {object} models.BasketResponse "Item successfully added to basket"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Success 200 {object} models.BasketResponse "Item successfully added to basket, with the resulting quantity"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or quantity"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 404 {object} models.ErrorResponse "Product not found"
+// @Failure 409 {object} models.BasketQuantityErrorResponse "Quantity exceeds the stock or the per-line limit"
+// @Failure 422 {object} models.ErrorResponse "Unknown variant or variant_id missing for a product with variants"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/basket [post]
 func (h *Handler) AddToBasket(c *fiber.Ctx) error {
 	var input models.BasketItem
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
 	}
+	input.UserID = currentUser(c).ID
 
 	item, err := h.basketService.AddToBasket(c.Context(), input)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_add_to_basket", err.Error()))
+		return basketErrorResp(c, err, "error_add_to_basket")
 	}
 
 	return c.JSON(web.OkResp("success_item_added_to_basket", item))
@@ -36,49 +63,84 @@ func (h *Handler) AddToBasket(c *fiber.Ctx) error {
 
 // GetUserBasket retrieves user's basket
 // @Summary Get user's basket
-// @Description Returns all items in user's basket
+// @Description Returns all items in user's basket in one request: each line carries the product name, first image and stock, and is priced the way checkout prices it (unit price of the best tier for its quantity, single-item base price, line total and savings). A line whose quantity exceeds the stock gets warning out_of_stock or insufficient_stock; a line without a variant or tier for its quantity has priced=false. Subtotal and savings only count lines checkout would order
 // @Tags basket
 // @Produce json
 // @Param user_id path int true "User ID"
 // @Success 200 {object} models.BasketListResponse "User's basket retrieved"
 // @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's basket"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/basket/{user_id} [get]
 func (h *Handler) GetUserBasket(c *fiber.Ctx) error {
-	userIDStr := c.Params("user_id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	basket, err := h.basketService.GetUserBasket(c.Context(), currentUser(c).ID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_user_basket", err.Error()))
 	}
 
-	items, err := h.basketService.GetUserBasket(c.Context(), userID)
+	return c.JSON(web.OkResp("success_user_basket_retrieved", basket))
+}
+
+// ReplaceBasket replaces user's basket
+// @Summary Replace basket
+// @Description Replaces the whole basket in one transaction, for example to sync a basket kept on the device. Lines of the same product and variant are merged, lines with quantity 0 are skipped. If any line is invalid the basket is left unchanged
+// @Tags basket
+// @Accept json
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param basket body models.ReplaceBasket true "New basket content"
+// @Success 200 {object} models.BasketListResponse "Basket replaced"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or quantity"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's basket"
+// @Failure 404 {object} models.ErrorResponse "Product not found"
+// @Failure 409 {object} models.BasketQuantityErrorResponse "Quantity exceeds the stock or the per-line limit"
+// @Failure 422 {object} models.ErrorResponse "Unknown variant or variant_id missing for a product with variants"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/basket/{user_id} [put]
+func (h *Handler) ReplaceBasket(c *fiber.Ctx) error {
+	var input models.ReplaceBasket
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	basket, err := h.basketService.ReplaceBasket(c.Context(), currentUser(c).ID, input.Items)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_user_basket", err.Error()))
+		return basketErrorResp(c, err, "error_replace_basket")
 	}
 
-	return c.JSON(web.OkResp("success_user_basket_retrieved", items))
+	return c.JSON(web.OkResp("success_basket_replaced", basket))
 }
 
 // UpdateBasketItem updates item in user's basket
 // @Summary Update basket item
-// @Description Updates quantity of product in user's basket
+// @Description Sets the quantity of a product and variant in user's basket, adding the line if it is missing. Quantity 0 removes the line. The quantity may not exceed the stock or the per-line limit
 // @Tags basket
 // @Accept json
 // @Produce json
 // @Param item body models.BasketItem true "Updated basket item data"
 // @Success 200 {object} models.BasketResponse "Basket item successfully updated"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or quantity"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 404 {object} models.ErrorResponse "Product not found"
+// @Failure 409 {object} models.BasketQuantityErrorResponse "Quantity exceeds the stock or the per-line limit"
+// @Failure 422 {object} models.ErrorResponse "Unknown variant or variant_id missing for a product with variants"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/basket [put]
 func (h *Handler) UpdateBasketItem(c *fiber.Ctx) error {
 	var input models.BasketItem
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
 	}
+	input.UserID = currentUser(c).ID
 
 	item, err := h.basketService.UpdateBasketItem(c.Context(), input)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_update_basket_item", err.Error()))
+		return basketErrorResp(c, err, "error_update_basket_item")
 	}
 
 	return c.JSON(web.OkResp("success_basket_item_updated", item))
@@ -86,30 +148,37 @@ func (h *Handler) UpdateBasketItem(c *fiber.Ctx) error {
 
 // RemoveFromBasket removes item from user's basket
 // @Summary Remove item from basket
-// @Description Removes product from user's basket
+// @Description Removes product from user's basket. Pass variant_id to remove only that variant, otherwise every line of the product is removed
 // @Tags basket
 // @Produce json
 // @Param user_id path int true "User ID"
 // @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID"
 // @Success 200 {object} models.SuccessResponse "Item successfully removed from basket"
 // @Failure 400 {object} models.ErrorResponse "Invalid parameters"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's basket"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/basket/{user_id}/{product_id} [delete]
 func (h *Handler) RemoveFromBasket(c *fiber.Ctx) error {
-	userIDStr := c.Params("user_id")
 	productIDStr := c.Params("product_id")
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
-	}
-
 	productID, err := strconv.Atoi(productIDStr)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_product_id", "Invalid product ID"))
 	}
 
-	if err := h.basketService.RemoveFromBasket(c.Context(), userID, productID); err != nil {
+	var variantID *int
+	if raw := c.Query("variant_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_variant_id", "Invalid variant ID"))
+		}
+		variantID = &id
+	}
+
+	if err := h.basketService.RemoveFromBasket(c.Context(), currentUser(c).ID, productID, variantID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_remove_from_basket", err.Error()))
 	}
 
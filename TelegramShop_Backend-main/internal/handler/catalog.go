@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	catalogService "telegramshop_backend/internal/service/catalog"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportCatalog imports products from a CSV or JSON file
+// @Summary Import catalog
+// @Description Creates and updates products with their price tiers, images, firm and category (by name, created when missing) from a CSV or JSON file. Rows with an id update that product, rows without one create a product. Empty images or prices keep the stored ones. Every row is validated first; if any row is invalid nothing is written and the response lists the problems. With dry_run=true the file is only validated. CSV columns: id, name, description, firm, category, stock, attributes (JSON object), images (URLs separated by |), prices (count:price separated by |)
+// @Tags catalog
+// @Accept plain
+// @Produce json
+// @Param file body string true "CSV or JSON file contents"
+// @Param format query string false "File format, taken from Content-Type when omitted" Enums(csv, json)
+// @Param dry_run query bool false "Only validate the file" default(false)
+// @Success 200 {object} models.CatalogImportResponse "Import result or dry-run report"
+// @Failure 400 {object} models.ErrorResponse "Unknown format, unreadable or empty file, too many rows"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 422 {object} models.CatalogImportResponse "Some rows are invalid, nothing was imported"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/catalog/import [post]
+func (h *Handler) ImportCatalog(c *fiber.Ctx) error {
+	format := c.Query("format")
+	if format == "" {
+		format = formatFromContentType(c.Get(fiber.HeaderContentType))
+	}
+
+	result, err := h.catalogService.Import(c.Context(), format, bytes.NewReader(c.Body()), c.QueryBool("dry_run"))
+	switch {
+	case errors.Is(err, catalogService.ErrInvalidRows):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(web.ErrorResp("error_invalid_rows", result))
+	case errors.Is(err, catalogService.ErrUnknownFormat),
+		errors.Is(err, catalogService.ErrInvalidFile),
+		errors.Is(err, catalogService.ErrEmptyFile),
+		errors.Is(err, catalogService.ErrTooManyRows):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_import_file", err.Error()))
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_import_catalog", err.Error()))
+	}
+
+	if result.DryRun {
+		return c.JSON(web.OkResp("success_catalog_validated", result))
+	}
+	return c.JSON(web.OkResp("success_catalog_imported", result))
+}
+
+// ExportCatalog streams the catalog as CSV or JSON
+// @Summary Export catalog
+// @Description Streams every product with its price tiers, images, firm and category in the import format, so the file can be edited and imported back
+// @Tags catalog
+// @Produce plain
+// @Param format query string false "File format" Enums(csv, json) default(csv)
+// @Success 200 {string} string "Catalog file"
+// @Failure 400 {object} models.ErrorResponse "Unknown format"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Security TelegramAuth
+// @Router /api/v1/catalog/export [get]
+func (h *Handler) ExportCatalog(c *fiber.Ctx) error {
+	format := c.Query("format", catalogService.FormatCSV)
+	switch format {
+	case catalogService.FormatCSV:
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	case catalogService.FormatJSON:
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_unknown_format", catalogService.ErrUnknownFormat.Error()))
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="catalog.`+format+`"`)
+
+	// The writer runs after the handler returns, when the request context is no longer valid.
+	// A failure midway can only be logged: the status line has already been sent.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.catalogService.Export(context.Background(), format, w); err != nil {
+			logger.Errorf("[ExportCatalog] Export interrupted: %v", err)
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
+
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return catalogService.FormatCSV
+	case strings.Contains(contentType, "json"):
+		return catalogService.FormatJSON
+	}
+	return ""
+}
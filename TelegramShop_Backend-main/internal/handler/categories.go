@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"telegramshop_backend/internal/models"
+	categoriesService "telegramshop_backend/internal/service/categories"
 	"telegramshop_backend/pkg/web"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,14 +13,17 @@ import (
 
 // CreateCategory creates a new category
 // @Summary Create new category
-// @Description Creates a new category with specified details
+// @Description Creates a new category with specified details. Set parent_id to create a subcategory
 // @Tags categories
 // @Accept json
 // @Produce json
 // @Param category body models.Category true "Category creation data"
 // @Success 200 {object} models.CategoryResponse "Category successfully created"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or unknown parent category"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/categories [post]
 func (h *Handler) CreateCategory(c *fiber.Ctx) error {
 	var input models.Category
@@ -27,6 +32,9 @@ func (h *Handler) CreateCategory(c *fiber.Ctx) error {
 	}
 
 	category, err := h.categoryService.CreateCategory(c.Context(), input)
+	if errors.Is(err, categoriesService.ErrParentNotFound) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_parent", err.Error()))
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_create_category", err.Error()))
 	}
@@ -76,17 +84,37 @@ func (h *Handler) GetAllCategories(c *fiber.Ctx) error {
 	return c.JSON(web.OkResp("success_categories_retrieved", categories))
 }
 
+// GetCategoryTree retrieves the category hierarchy
+// @Summary Get category tree
+// @Description Returns root categories with their subcategories nested in children, each level sorted by name
+// @Tags categories
+// @Produce json
+// @Success 200 {object} models.CategoryTreeResponse "Category tree retrieved successfully"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/v1/categories/tree [get]
+func (h *Handler) GetCategoryTree(c *fiber.Ctx) error {
+	tree, err := h.categoryService.GetCategoryTree(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_category_tree", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_category_tree_retrieved", tree))
+}
+
 // UpdateCategory updates category details
 // @Summary Update category
-// @Description Updates category details by its ID
+// @Description Updates category details by its ID. parent_id moves the category in the tree and null makes it a root category; without parent_id the category stays where it is. A category cannot be moved under itself or its subcategory
 // @Tags categories
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
 // @Param category body models.UpdateCategoryInput true "Updated category data"
 // @Success 200 {object} models.SuccessResponse "Category successfully updated"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body, unknown parent category or cycle"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/categories/{id} [put]
 func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -99,7 +127,11 @@ func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
 	}
 
-	if err := h.categoryService.UpdateCategory(c.Context(), id, input); err != nil {
+	err = h.categoryService.UpdateCategory(c.Context(), id, input)
+	if errors.Is(err, categoriesService.ErrParentNotFound) || errors.Is(err, categoriesService.ErrCategoryCycle) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_parent", err.Error()))
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_update_category", err.Error()))
 	}
 
@@ -108,13 +140,18 @@ func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
 
 // DeleteCategory deletes a category
 // @Summary Delete category
-// @Description Deletes a category by its ID
+// @Description Deletes a category by its ID. A category with subcategories is only deleted with reparent=true, which moves the subcategories to the parent of the deleted category
 // @Tags categories
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param reparent query bool false "Move subcategories up instead of rejecting the deletion" default(false)
 // @Success 200 {object} models.SuccessResponse "Category successfully deleted"
 // @Failure 400 {object} models.ErrorResponse "Invalid category ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 409 {object} models.ErrorResponse "Category has subcategories"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/categories/{id} [delete]
 func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -123,9 +160,17 @@ func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid category ID"))
 	}
 
-	if err := h.categoryService.DeleteCategory(c.Context(), id); err != nil {
+	before, _ := h.categoryService.GetCategoryByID(c.Context(), id)
+	err = h.categoryService.DeleteCategory(c.Context(), id, c.QueryBool("reparent"))
+	if errors.Is(err, categoriesService.ErrCategoryHasChildren) {
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_category_has_children", err.Error()))
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_delete_category", err.Error()))
 	}
+	if before.Image != nil {
+		h.cleanupImages(c.Context(), *before.Image)
+	}
 
 	return c.JSON(web.OkResp("success_category_deleted", nil))
 }
@@ -140,7 +185,10 @@ func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
 // @Param image body models.ImageInput true "Image data"
 // @Success 200 {object} models.SuccessResponse "Category image successfully set"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/categories/{id}/image [put]
 func (h *Handler) SetCategoryImage(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -154,10 +202,14 @@ func (h *Handler) SetCategoryImage(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_body", "Invalid body"))
 	}
 
+	before, _ := h.categoryService.GetCategoryByID(c.Context(), id)
 	err = h.categoryService.SetImage(c.Context(), id, input.Image)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_set_image", err.Error()))
 	}
+	if before.Image != nil && *before.Image != input.Image {
+		h.cleanupImages(c.Context(), *before.Image)
+	}
 
 	return c.JSON(web.OkResp("success_category_image_set", nil))
 }
@@ -170,7 +222,10 @@ func (h *Handler) SetCategoryImage(c *fiber.Ctx) error {
 // @Param id path int true "Category ID"
 // @Success 200 {object} models.SuccessResponse "Category image successfully removed"
 // @Failure 400 {object} models.ErrorResponse "Invalid category ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/categories/{id}/image [delete]
 func (h *Handler) RemoveCategoryImage(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -179,10 +234,14 @@ func (h *Handler) RemoveCategoryImage(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid category ID"))
 	}
 
+	before, _ := h.categoryService.GetCategoryByID(c.Context(), id)
 	err = h.categoryService.RemoveImage(c.Context(), id)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_remove_image", err.Error()))
 	}
+	if before.Image != nil {
+		h.cleanupImages(c.Context(), *before.Image)
+	}
 
 	return c.JSON(web.OkResp("success_category_image_removed", nil))
 }
@@ -9,11 +9,7 @@ import (
 )
 
 func (h *Handler) AddComment(c *fiber.Ctx) error {
-	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
-	if err != nil {
-		logger.Errorf("[AddComment] Error parsing user_id: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error", "Invalid user_id"))
-	}
+	userID := currentUser(c).ID
 
 	productID, err := strconv.Atoi(c.Params("product_id"))
 	if err != nil {
@@ -40,11 +36,7 @@ func (h *Handler) AddComment(c *fiber.Ctx) error {
 }
 
 func (h *Handler) EditComment(c *fiber.Ctx) error {
-	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
-	if err != nil {
-		logger.Errorf("[EditComment] Error parsing user_id: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error", "Invalid user_id"))
-	}
+	userID := currentUser(c).ID
 
 	productID, err := strconv.Atoi(c.Params("product_id"))
 	if err != nil {
@@ -71,11 +63,7 @@ func (h *Handler) EditComment(c *fiber.Ctx) error {
 }
 
 func (h *Handler) DeleteComment(c *fiber.Ctx) error {
-	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
-	if err != nil {
-		logger.Errorf("[DeleteComment] Error parsing user_id: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error", "Invalid user_id"))
-	}
+	userID := currentUser(c).ID
 
 	productID, err := strconv.Atoi(c.Params("product_id"))
 	if err != nil {
@@ -99,11 +87,19 @@ func (h *Handler) GetCommentsByProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error", "Invalid product_id"))
 	}
 
-	comments, err := h.commentService.GetCommentsByProduct(c.Context(), productID)
+	page, err := parsePage(c)
+	if err != nil {
+		return pageErrorResp(c, err)
+	}
+
+	comments, next, err := h.commentService.GetCommentsByProduct(c.Context(), productID, page)
+	if isPageError(err) {
+		return pageErrorResp(c, err)
+	}
 	if err != nil {
 		logger.Errorf("[GetCommentsByProduct] Error getting comments: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error", "Failed to get comments"))
 	}
 
-	return c.JSON(web.OkResp("success_get_comments_by_product", comments))
+	return c.JSON(web.PageResp("success_get_comments_by_product", comments, next))
 }
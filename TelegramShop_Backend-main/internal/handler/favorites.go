@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/favorites"
 	"telegramshop_backend/pkg/web"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,22 +13,32 @@ import (
 
 // AddToFavorites adds item to user's favorites
 // @Summary Add item to favorites
-// @Description Adds a product to user's favorites list
+// @Description Adds a product to user's favorites list. variant_id must be a variant of the product and is required for products that have variants
 // @Tags favorites
 // @Accept json
 // @Produce json
 // @Param favorite body models.Favorite true "Favorite item data"
 // @Success 200 {object} models.FavoriteResponse "Item successfully added to favorites"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or variant"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 404 {object} models.ErrorResponse "Product not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/favorites [post]
 func (h *Handler) AddToFavorites(c *fiber.Ctx) error {
 	var input models.Favorite
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
 	}
+	input.UserID = currentUser(c).ID
 
 	favorite, err := h.favoriteService.AddToFavorites(c.Context(), input)
+	if errors.Is(err, favorites.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_product_not_found", err.Error()))
+	}
+	if errors.Is(err, favorites.ErrVariantNotFound) || errors.Is(err, favorites.ErrVariantRequired) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_variant", err.Error()))
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_add_to_favorites", err.Error()))
 	}
@@ -36,22 +48,18 @@ func (h *Handler) AddToFavorites(c *fiber.Ctx) error {
 
 // GetUserFavorites retrieves user's favorites
 // @Summary Get user's favorites
-// @Description Returns all items in user's favorites list
+// @Description Returns all items in user's favorites list, newest first, with the product name, first image, stock and current single-item price. Breaking change: data used to be a plain array of product IDs; read product_id from each object instead
 // @Tags favorites
 // @Produce json
 // @Param user_id path int true "User ID"
 // @Success 200 {object} models.FavoriteListResponse "User's favorites retrieved"
 // @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/favorites/{user_id} [get]
 func (h *Handler) GetUserFavorites(c *fiber.Ctx) error {
-	userIDStr := c.Params("user_id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
-	}
-
-	favorites, err := h.favoriteService.GetUserFavorites(c.Context(), userID)
+	favorites, err := h.favoriteService.GetUserFavorites(c.Context(), currentUser(c).ID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_user_favorites", err.Error()))
 	}
@@ -61,30 +69,36 @@ func (h *Handler) GetUserFavorites(c *fiber.Ctx) error {
 
 // RemoveFromFavorites removes item from user's favorites
 // @Summary Remove item from favorites
-// @Description Removes product from user's favorites list
+// @Description Removes product from user's favorites list. Pass variant_id to remove a favorite variant of the product
 // @Tags favorites
 // @Produce json
 // @Param user_id path int true "User ID"
 // @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID"
 // @Success 200 {object} models.SuccessResponse "Item successfully removed from favorites"
 // @Failure 400 {object} models.ErrorResponse "Invalid parameters"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/favorites/{user_id}/{product_id} [delete]
 func (h *Handler) RemoveFromFavorites(c *fiber.Ctx) error {
-	userIDStr := c.Params("user_id")
 	productIDStr := c.Params("product_id")
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
-	}
-
 	productID, err := strconv.Atoi(productIDStr)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_product_id", "Invalid product ID"))
 	}
 
-	if err := h.favoriteService.RemoveFromFavorites(c.Context(), userID, productID); err != nil {
+	var variantID *int
+	if raw := c.Query("variant_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_variant_id", "Invalid variant ID"))
+		}
+		variantID = &id
+	}
+
+	if err := h.favoriteService.RemoveFromFavorites(c.Context(), currentUser(c).ID, productID, variantID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_remove_from_favorites", err.Error()))
 	}
 
@@ -18,7 +18,10 @@ import (
 // @Param firm body models.Firm true "Firm creation data"
 // @Success 200 {object} models.FirmResponse "Firm successfully created"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/firms [post]
 func (h *Handler) CreateFirm(c *fiber.Ctx) error {
 	var input models.Firm
@@ -90,7 +93,10 @@ func (h *Handler) GetAllFirms(c *fiber.Ctx) error {
 // @Param firm body models.UpdateFirmInput true "Updated firm data"
 // @Success 200 {object} models.SuccessResponse "Firm successfully updated"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/firms/{id} [put]
 func (h *Handler) UpdateFirm(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -118,7 +124,10 @@ func (h *Handler) UpdateFirm(c *fiber.Ctx) error {
 // @Param id path int true "Firm ID"
 // @Success 200 {object} models.SuccessResponse "Firm successfully deleted"
 // @Failure 400 {object} models.ErrorResponse "Invalid firm ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/firms/{id} [delete]
 func (h *Handler) DeleteFirm(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -1,17 +1,26 @@
 package handler
 
 import (
+	"telegramshop_backend/internal/service/admins"
 	"telegramshop_backend/internal/service/avg_marks"
 	"telegramshop_backend/internal/service/basket"
+	"telegramshop_backend/internal/service/catalog"
 	"telegramshop_backend/internal/service/categories"
 	"telegramshop_backend/internal/service/comment"
 	"telegramshop_backend/internal/service/favorites"
 	"telegramshop_backend/internal/service/firms"
+	"telegramshop_backend/internal/service/images"
 	"telegramshop_backend/internal/service/marks"
 	"telegramshop_backend/internal/service/orders"
+	"telegramshop_backend/internal/service/outbox"
+	"telegramshop_backend/internal/service/payments"
 	"telegramshop_backend/internal/service/prices"
 	"telegramshop_backend/internal/service/products"
+	"telegramshop_backend/internal/service/promotions"
+	"telegramshop_backend/internal/service/reminders"
+	"telegramshop_backend/internal/service/suggest"
 	"telegramshop_backend/internal/service/users"
+	"telegramshop_backend/internal/service/webhooks"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -23,17 +32,27 @@ var (
 )
 
 type Handler struct {
-	userService     users.Service
-	favoriteService favorites.Service
-	basketService   basket.Service
-	orderService    orders.Service
-	firmsService    firms.Service
-	priceService    prices.Service
-	categoryService categories.Service
-	productService  products.Service
-	marksService    marks.MarksService
-	avgMarksService avg_marks.AvgMarksService
-	commentService  comment.CommentService
+	userService      users.Service
+	favoriteService  favorites.Service
+	basketService    basket.Service
+	orderService     orders.Service
+	firmsService     firms.Service
+	priceService     prices.Service
+	categoryService  categories.Service
+	productService   products.Service
+	marksService     marks.MarksService
+	avgMarksService  avg_marks.AvgMarksService
+	commentService   comment.CommentService
+	adminService     admins.Service
+	paymentService   payments.Service
+	outboxService    outbox.Service
+	webhookService   webhooks.Service
+	suggestService   suggest.Service
+	catalogService   catalog.Service
+	imageService     images.Service
+	promotionService promotions.Service
+	reminderService  reminders.Service
+	auth             AuthConfig
 }
 
 func NewHandler(
@@ -48,19 +67,39 @@ func NewHandler(
 	marksService marks.MarksService,
 	avgMarksService avg_marks.AvgMarksService,
 	commentService comment.CommentService,
+	adminService admins.Service,
+	paymentService payments.Service,
+	outboxService outbox.Service,
+	webhookService webhooks.Service,
+	suggestService suggest.Service,
+	catalogService catalog.Service,
+	imageService images.Service,
+	promotionService promotions.Service,
+	reminderService reminders.Service,
+	auth AuthConfig,
 ) *Handler {
 	return &Handler{
-		userService:     userService,
-		favoriteService: favoriteService,
-		basketService:   basketService,
-		orderService:    orderService,
-		firmsService:    firmsService,
-		priceService:    priceService,
-		categoryService: categoryService,
-		productService:  productService,
-		marksService:    marksService,
-		avgMarksService: avgMarksService,
-		commentService:  commentService,
+		userService:      userService,
+		favoriteService:  favoriteService,
+		basketService:    basketService,
+		orderService:     orderService,
+		firmsService:     firmsService,
+		priceService:     priceService,
+		categoryService:  categoryService,
+		productService:   productService,
+		marksService:     marksService,
+		avgMarksService:  avgMarksService,
+		commentService:   commentService,
+		adminService:     adminService,
+		paymentService:   paymentService,
+		outboxService:    outboxService,
+		webhookService:   webhookService,
+		suggestService:   suggestService,
+		catalogService:   catalogService,
+		imageService:     imageService,
+		promotionService: promotionService,
+		reminderService:  reminderService,
+		auth:             auth,
 	}
 }
 
@@ -68,79 +107,126 @@ func (h *Handler) InitRouter(app *fiber.App) {
 	api := app.Group(basePath)
 
 	// User routes
-	api.Post("/users", h.CreateUser)
-	api.Get("/users", h.GetAllUsers)
-	api.Get("/users/:id", h.GetUser)
-	api.Delete("/users/:id", h.DeleteUser)
+	api.Post("/users", h.TelegramAuth, h.RequireAdmin, h.CreateUser)
+	api.Get("/users", h.TelegramAuth, h.RequireAdmin, h.GetAllUsers)
+	api.Get("/users/me", h.TelegramAuth, h.GetCurrentUser)
+	api.Put("/users/me/basket-reminders", h.TelegramAuth, h.SetBasketReminders)
+	api.Get("/users/:id", h.TelegramAuth, h.RequireSelfOrAdmin, h.GetUser)
+	api.Delete("/users/:id", h.TelegramAuth, h.RequireAdmin, h.DeleteUser)
+
+	// Admin routes
+	api.Get("/admins", h.TelegramAuth, h.RequireAdmin, h.GetAdmins)
+	api.Post("/admins/:id", h.TelegramAuth, h.RequireAdmin, h.GrantAdmin)
+	api.Delete("/admins/:id", h.TelegramAuth, h.RequireAdmin, h.RevokeAdmin)
+
+	// Outbox routes
+	api.Get("/outbox", h.TelegramAuth, h.RequireAdmin, h.GetOutboxEvents)
+	api.Post("/outbox/:id/replay", h.TelegramAuth, h.RequireAdmin, h.ReplayOutboxEvent)
+
+	// Catalog import and export
+	api.Post("/catalog/import", h.TelegramAuth, h.RequireAdmin, h.ImportCatalog)
+	api.Get("/catalog/export", h.TelegramAuth, h.RequireAdmin, h.ExportCatalog)
+
+	// Promo code routes
+	api.Post("/promotions", h.TelegramAuth, h.RequireAdmin, h.CreatePromoCode)
+	api.Get("/promotions", h.TelegramAuth, h.RequireAdmin, h.GetPromoCodes)
+	api.Get("/promotions/:id", h.TelegramAuth, h.RequireAdmin, h.GetPromoCode)
+	api.Put("/promotions/:id", h.TelegramAuth, h.RequireAdmin, h.UpdatePromoCode)
+	api.Delete("/promotions/:id", h.TelegramAuth, h.RequireAdmin, h.DeletePromoCode)
+
+	// Webhook routes
+	api.Post("/webhooks", h.TelegramAuth, h.RequireAdmin, h.CreateWebhook)
+	api.Get("/webhooks", h.TelegramAuth, h.RequireAdmin, h.GetWebhooks)
+	api.Put("/webhooks/:id", h.TelegramAuth, h.RequireAdmin, h.UpdateWebhook)
+	api.Delete("/webhooks/:id", h.TelegramAuth, h.RequireAdmin, h.DeleteWebhook)
+	api.Get("/webhooks/:id/deliveries", h.TelegramAuth, h.RequireAdmin, h.GetWebhookDeliveries)
 
 	// Favorites routes
-	api.Post("/favorites", h.AddToFavorites)
-	api.Get("/favorites/:user_id", h.GetUserFavorites)
-	api.Delete("/favorites/:user_id/:product_id", h.RemoveFromFavorites)
+	api.Post("/favorites", h.TelegramAuth, h.AddToFavorites)
+	api.Get("/favorites/:user_id", h.TelegramAuth, h.RequireSelf, h.GetUserFavorites)
+	api.Delete("/favorites/:user_id/:product_id", h.TelegramAuth, h.RequireSelf, h.RemoveFromFavorites)
 
 	// Basket routes
-	api.Post("/basket", h.AddToBasket)
-	api.Get("/basket/:user_id", h.GetUserBasket)
-	api.Put("/basket", h.UpdateBasketItem)
-	api.Delete("/basket/:user_id/:product_id", h.RemoveFromBasket)
+	api.Post("/basket", h.TelegramAuth, h.AddToBasket)
+	api.Get("/basket/:user_id", h.TelegramAuth, h.RequireSelf, h.GetUserBasket)
+	api.Put("/basket/:user_id", h.TelegramAuth, h.RequireSelf, h.ReplaceBasket)
+	api.Put("/basket", h.TelegramAuth, h.UpdateBasketItem)
+	api.Delete("/basket/:user_id/:product_id", h.TelegramAuth, h.RequireSelf, h.RemoveFromBasket)
 
 	// Orders routes
-	api.Post("/orders", h.CreateOrder)
-	api.Get("/orders/:id", h.GetOrder)
-	api.Get("/orders/user/:user_id", h.GetUserOrders)
-	api.Get("/orders/all", h.GetAllOrders)
+	api.Post("/orders", h.TelegramAuth, h.CreateOrder)
+	api.Post("/orders/checkout", h.TelegramAuth, h.Checkout)
+	api.Post("/orders/preview", h.TelegramAuth, h.PreviewBasket)
+	api.Get("/orders/all", h.TelegramAuth, h.RequireAdmin, h.GetAllOrders)
+	api.Get("/orders/:id", h.TelegramAuth, h.GetOrder)
+	api.Patch("/orders/:id/status", h.TelegramAuth, h.RequireAdmin, h.UpdateOrderStatus)
+	api.Post("/orders/:id/cancel", h.TelegramAuth, h.CancelOrder)
+	api.Post("/orders/:id/invoice", h.TelegramAuth, h.CreateInvoiceLink)
+	api.Get("/orders/user/:user_id", h.TelegramAuth, h.RequireSelf, h.GetUserOrders)
 
 	//firms
-	api.Post("/firms", h.CreateFirm)       //work
-	api.Get("/firms/:id", h.GetFirmByID)   //work
-	api.Get("/firms", h.GetAllFirms)       //work
-	api.Put("/firms/:id", h.UpdateFirm)    //work
-	api.Delete("/firms/:id", h.DeleteFirm) //work
+	api.Post("/firms", h.TelegramAuth, h.RequireAdmin, h.CreateFirm)       //work
+	api.Get("/firms/:id", h.GetFirmByID)                                   //work
+	api.Get("/firms", h.GetAllFirms)                                       //work
+	api.Put("/firms/:id", h.TelegramAuth, h.RequireAdmin, h.UpdateFirm)    //work
+	api.Delete("/firms/:id", h.TelegramAuth, h.RequireAdmin, h.DeleteFirm) //work
 
 	//price
-	api.Post("/prices", h.CreatePrice)                                   //work
-	api.Get("/prices/:id", h.GetPriceByID)                               //work
-	api.Get("/prices/product/:product_id", h.GetPricesByProductID)       //work
-	api.Put("/prices/:id", h.UpdatePrice)                                //work
-	api.Delete("/prices/:id", h.DeletePrice)                             //work
-	api.Delete("/prices/product/:product_id", h.DeletePricesByProductID) //work
-	api.Patch("/prices/:id/count", h.UpdatePriceCount)
+	api.Post("/prices", h.TelegramAuth, h.RequireAdmin, h.CreatePrice)                                   //work
+	api.Get("/prices/:id", h.GetPriceByID)                                                               //work
+	api.Get("/prices/product/:product_id", h.GetPricesByProductID)                                       //work
+	api.Put("/prices/:id", h.TelegramAuth, h.RequireAdmin, h.UpdatePrice)                                //work
+	api.Delete("/prices/:id", h.TelegramAuth, h.RequireAdmin, h.DeletePrice)                             //work
+	api.Delete("/prices/product/:product_id", h.TelegramAuth, h.RequireAdmin, h.DeletePricesByProductID) //work
+	api.Patch("/prices/:id/count", h.TelegramAuth, h.RequireAdmin, h.UpdatePriceCount)
 
 	// category
-	api.Post("/categories", h.CreateCategory)                  //work
-	api.Get("/categories/:id", h.GetCategoryByID)              //work
-	api.Get("/categories", h.GetAllCategories)                 //work
-	api.Put("/categories/:id", h.UpdateCategory)               //work
-	api.Delete("/categories/:id", h.DeleteCategory)            //work
-	api.Put("/categories/:id/image", h.SetCategoryImage)       //work
-	api.Delete("/categories/:id/image", h.RemoveCategoryImage) //work
+	api.Get("/categories/tree", h.GetCategoryTree)
+	api.Post("/categories", h.TelegramAuth, h.RequireAdmin, h.CreateCategory)                  //work
+	api.Get("/categories/:id", h.GetCategoryByID)                                              //work
+	api.Get("/categories", h.GetAllCategories)                                                 //work
+	api.Put("/categories/:id", h.TelegramAuth, h.RequireAdmin, h.UpdateCategory)               //work
+	api.Delete("/categories/:id", h.TelegramAuth, h.RequireAdmin, h.DeleteCategory)            //work
+	api.Put("/categories/:id/image", h.TelegramAuth, h.RequireAdmin, h.SetCategoryImage)       //work
+	api.Delete("/categories/:id/image", h.TelegramAuth, h.RequireAdmin, h.RemoveCategoryImage) //work
+	api.Put("/categories/:id/image/upload", h.TelegramAuth, h.RequireAdmin, h.UploadCategoryImage)
 
 	// product
-	api.Post("/products", h.CreateProduct)       //work
-	api.Get("/products/:id", h.GetProductByID)   //work
-	api.Get("/products", h.GetAllProducts)       //work
-	api.Put("/products/:id", h.UpdateProduct)    //work
-	api.Delete("/products/:id", h.DeleteProduct) //work
+	api.Get("/products/facets", h.GetProductFacets)
+	api.Get("/products/search", h.SearchProducts)
+	api.Get("/products/suggest", h.SuggestProducts)
+	api.Post("/products", h.TelegramAuth, h.RequireAdmin, h.CreateProduct)       //work
+	api.Get("/products/:id", h.GetProductByID)                                   //work
+	api.Get("/products", h.GetAllProducts)                                       //work
+	api.Put("/products/:id", h.TelegramAuth, h.RequireAdmin, h.UpdateProduct)    //work
+	api.Delete("/products/:id", h.TelegramAuth, h.RequireAdmin, h.DeleteProduct) //work
 
 	// product images
-	api.Put("/products/:id/image", h.AddProductImage)       //work
-	api.Delete("/products/:id/image", h.RemoveProductImage) //work в теле запроса нужно указать адрес удаляемой img
-	api.Put("/products/:id/images", h.SetProductImages)     //work
+	api.Put("/products/:id/image", h.TelegramAuth, h.RequireAdmin, h.AddProductImage)       //work
+	api.Delete("/products/:id/image", h.TelegramAuth, h.RequireAdmin, h.RemoveProductImage) //work в теле запроса нужно указать адрес удаляемой img
+	api.Put("/products/:id/images", h.TelegramAuth, h.RequireAdmin, h.SetProductImages)     //work
+	api.Post("/products/:id/images/upload", h.TelegramAuth, h.RequireAdmin, h.UploadProductImage)
+
+	// product variants
+	api.Get("/products/:id/variants", h.GetProductVariants)
+	api.Post("/products/:id/variants", h.TelegramAuth, h.RequireAdmin, h.CreateProductVariant)
+	api.Put("/products/:id/variants/:variant_id", h.TelegramAuth, h.RequireAdmin, h.UpdateProductVariant)
+	api.Delete("/products/:id/variants/:variant_id", h.TelegramAuth, h.RequireAdmin, h.DeleteProductVariant)
 
 	// product stats
-	api.Patch("/products/:id/sell", h.IncrementSellCount) //work
-	api.Patch("/products/:id/stock", h.UpdateStock)       //work
+	api.Patch("/products/:id/sell", h.TelegramAuth, h.RequireAdmin, h.IncrementSellCount) //work
+	api.Patch("/products/:id/stock", h.TelegramAuth, h.RequireAdmin, h.UpdateStock)       //work
 
-	api.Get("/marks/user/:user_id", h.GetUserMarks)                           ///work
-	api.Get("/marks/user/:user_id/product/:product_id", h.GetProductUserMark) //work
-	api.Post("/marks/user/:user_id/product/:product_id", h.AddMark)           //work
-	api.Delete("/marks/user/:user_id/product/:product_id", h.DeleteMark)      //work
+	api.Get("/marks/user/:user_id", h.GetUserMarks)                                                     ///work
+	api.Get("/marks/user/:user_id/product/:product_id", h.GetProductUserMark)                           //work
+	api.Post("/marks/user/:user_id/product/:product_id", h.TelegramAuth, h.RequireSelf, h.AddMark)      //work
+	api.Delete("/marks/user/:user_id/product/:product_id", h.TelegramAuth, h.RequireSelf, h.DeleteMark) //work
 
 	api.Get("/avg_marks/product/:product_id", h.GetAvgMark) //work
 	api.Get("/avg_marks", h.GetAllAvgMarks)                 //work
 
-	api.Post("/comments/user/:user_id/product/:product_id", h.AddComment)      //work
-	api.Put("/comments/user/:user_id/product/:product_id", h.EditComment)      //work
-	api.Delete("/comments/user/:user_id/product/:product_id", h.DeleteComment) //work
-	api.Get("/comments/product/:product_id", h.GetCommentsByProduct)           //work
+	api.Post("/comments/user/:user_id/product/:product_id", h.TelegramAuth, h.RequireSelf, h.AddComment)      //work
+	api.Put("/comments/user/:user_id/product/:product_id", h.TelegramAuth, h.RequireSelf, h.EditComment)      //work
+	api.Delete("/comments/user/:user_id/product/:product_id", h.TelegramAuth, h.RequireSelf, h.DeleteComment) //work
+	api.Get("/comments/product/:product_id", h.GetCommentsByProduct)                                          //work
 }
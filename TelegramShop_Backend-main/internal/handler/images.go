@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+
+	imagesService "telegramshop_backend/internal/service/images"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UploadProductImage uploads an image file and adds it to a product
+// @Summary Upload product image
+// @Description Stores a JPEG, PNG or GIF file under the hash of its content, generates a JPEG thumbnail next to it (same name with the _thumb.jpg suffix) and appends the image URL to the product images. Uploading an image the product already has does not add it twice
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param image formData file true "Image file"
+// @Success 200 {object} models.UploadedImageResponse "Image uploaded"
+// @Failure 400 {object} models.ErrorResponse "Invalid product ID, missing or broken image"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Product not found"
+// @Failure 413 {object} models.ErrorResponse "Image file or dimensions are too large"
+// @Failure 415 {object} models.ErrorResponse "Image is not a JPEG, PNG or GIF"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/products/{id}/images/upload [post]
+func (h *Handler) UploadProductImage(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid product ID"))
+	}
+
+	product, err := h.productService.GetProductByID(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_product", err.Error()))
+	}
+	if product.ID == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_product_not_found", "Product not found"))
+	}
+
+	data, err := readUpload(c, "image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_file", "Multipart field image is required"))
+	}
+
+	var attachErr error
+	uploaded, err := h.imageService.Upload(c.Context(), data, func(url string) error {
+		if !slices.Contains(product.Image, url) {
+			attachErr = h.productService.AddProductImage(c.Context(), id, url)
+		}
+		return attachErr
+	})
+	if attachErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_add_product_image", attachErr.Error()))
+	}
+	if err != nil {
+		return imageErrorResp(c, err)
+	}
+
+	return c.JSON(web.OkResp("success_product_image_uploaded", uploaded))
+}
+
+// UploadCategoryImage uploads an image file and sets it as the category image
+// @Summary Upload category image
+// @Description Stores a JPEG, PNG or GIF file under the hash of its content, generates a JPEG thumbnail next to it (same name with the _thumb.jpg suffix) and makes it the category image. The previous uploaded image is deleted unless something else uses it
+// @Tags categories
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param image formData file true "Image file"
+// @Success 200 {object} models.UploadedImageResponse "Image uploaded"
+// @Failure 400 {object} models.ErrorResponse "Invalid category ID, missing or broken image"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Category not found"
+// @Failure 413 {object} models.ErrorResponse "Image file or dimensions are too large"
+// @Failure 415 {object} models.ErrorResponse "Image is not a JPEG, PNG or GIF"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/categories/{id}/image/upload [put]
+func (h *Handler) UploadCategoryImage(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid category ID"))
+	}
+
+	category, err := h.categoryService.GetCategoryByID(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_category_not_found", "Category not found"))
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_category", err.Error()))
+	}
+
+	data, err := readUpload(c, "image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_file", "Multipart field image is required"))
+	}
+
+	var attachErr error
+	uploaded, err := h.imageService.Upload(c.Context(), data, func(url string) error {
+		attachErr = h.categoryService.SetImage(c.Context(), id, url)
+		return attachErr
+	})
+	if attachErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_set_image", attachErr.Error()))
+	}
+	if err != nil {
+		return imageErrorResp(c, err)
+	}
+	if category.Image != nil && *category.Image != uploaded.URL {
+		h.cleanupImages(c.Context(), *category.Image)
+	}
+
+	return c.JSON(web.OkResp("success_category_image_uploaded", uploaded))
+}
+
+func readUpload(c *fiber.Ctx, field string) ([]byte, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+func imageErrorResp(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, imagesService.ErrFileTooLarge), errors.Is(err, imagesService.ErrImageTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(web.ErrorResp("error_image_too_large", err.Error()))
+	case errors.Is(err, imagesService.ErrUnsupportedType):
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(web.ErrorResp("error_unsupported_image_type", err.Error()))
+	case errors.Is(err, imagesService.ErrInvalidImage):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_image", err.Error()))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_upload_image", err.Error()))
+	}
+}
+
+// cleanupImages deletes the uploaded files among urls that nothing references anymore.
+// The change that dropped them has already succeeded, so failures are only logged.
+func (h *Handler) cleanupImages(ctx context.Context, urls ...string) {
+	if err := h.imageService.Cleanup(ctx, urls...); err != nil {
+		logger.Errorf("[cleanupImages] Error cleaning up replaced images: %v", err)
+	}
+}
@@ -47,11 +47,7 @@ func (h *Handler) GetProductUserMark(c *fiber.Ctx) error {
 }
 
 func (h *Handler) AddMark(c *fiber.Ctx) error {
-	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
-	if err != nil {
-		logger.Errorf("[AddMark] Error parsing user_id: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error", "Invalid user_id"))
-	}
+	userID := currentUser(c).ID
 
 	productID, err := strconv.Atoi(c.Params("product_id"))
 	if err != nil {
@@ -82,11 +78,7 @@ func (h *Handler) AddMark(c *fiber.Ctx) error {
 }
 
 func (h *Handler) DeleteMark(c *fiber.Ctx) error {
-	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
-	if err != nil {
-		logger.Errorf("[DeleteMark] Error parsing user_id: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error", "Invalid user_id"))
-	}
+	userID := currentUser(c).ID
 
 	productID, err := strconv.Atoi(c.Params("product_id"))
 	if err != nil {
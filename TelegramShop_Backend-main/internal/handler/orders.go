@@ -1,14 +1,22 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/orders"
 	"telegramshop_backend/pkg/web"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
 // CreateOrder creates a new order
 // @Summary Create new order
 // @Description Creates a new order for a user with specified products
@@ -16,24 +24,109 @@ import (
 // @Accept json
 // @Produce json
 // @Param order body models.CreateOrder true "Order creation data"
+// @Param Idempotency-Key header string false "Client-generated key; retries with the same key and body return the original order"
 // @Success 200 {object} models.OrderResponse "Order successfully created"
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body, order items or idempotency key"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 409 {object} models.InsufficientStockResponse "Not enough stock for some products or idempotency key reused with a different body"
+// @Failure 422 {object} models.PromoCodeErrorResponse "Unknown product, no matching price tier or promo code that cannot be applied"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/orders [post]
 func (h *Handler) CreateOrder(c *fiber.Ctx) error {
 	var input models.CreateOrder
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
 	}
+	input.UserID = currentUser(c).ID
+
+	key := c.Get(idempotencyKeyHeader)
+	if key == "" {
+		order, err := h.orderService.CreateOrder(c.Context(), input)
+		if err != nil {
+			return orderErrorResp(c, "error_create_order", err)
+		}
+
+		return c.JSON(web.OkResp("success_order_created", order))
+	}
 
-	order, err := h.orderService.CreateOrder(c.Context(), input)
+	if len(key) > maxIdempotencyKeyLength {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_idempotency_key", "Idempotency key is too long"))
+	}
+
+	order, replayed, err := h.orderService.CreateOrderIdempotent(c.Context(), input, key)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_create_order", err.Error()))
+		return orderErrorResp(c, "error_create_order", err)
+	}
+	if replayed {
+		c.Set(idempotentReplayedHeader, "true")
 	}
 
 	return c.JSON(web.OkResp("success_order_created", order))
 }
 
+// Checkout creates an order from the user's basket
+// @Summary Checkout basket
+// @Description Builds an order from the current basket, prices it, applies the promo code if one is given and reserves stock, then clears the basket. Lines that are out of stock, whose product no longer exists or whose quantity has no price tier are left out and listed in "dropped"
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param promo body models.ApplyPromoCode false "Promo code"
+// @Success 200 {object} models.CheckoutResponse "Order created from basket"
+// @Failure 400 {object} models.ErrorResponse "Basket is empty or invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 409 {object} models.UnavailableBasketResponse "None of the basket items can be ordered"
+// @Failure 422 {object} models.PromoCodeErrorResponse "Promo code that cannot be applied"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/orders/checkout [post]
+func (h *Handler) Checkout(c *fiber.Ctx) error {
+	var input models.ApplyPromoCode
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+		}
+	}
+
+	checkout, err := h.orderService.Checkout(c.Context(), currentUser(c).ID, input.PromoCode)
+	if err != nil {
+		return orderErrorResp(c, "error_checkout", err)
+	}
+
+	return c.JSON(web.OkResp("success_checkout", checkout))
+}
+
+// PreviewBasket prices the user's basket without placing an order
+// @Summary Preview basket price
+// @Description Prices the current basket the way checkout would, with the promo code applied if one is given, without reserving stock or creating an order. Lines that could not be ordered are listed in "dropped"
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param promo body models.ApplyPromoCode false "Promo code"
+// @Success 200 {object} models.OrderQuoteResponse "Priced basket"
+// @Failure 400 {object} models.ErrorResponse "Basket is empty or invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 409 {object} models.UnavailableBasketResponse "None of the basket items can be ordered"
+// @Failure 422 {object} models.PromoCodeErrorResponse "Promo code that cannot be applied"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/orders/preview [post]
+func (h *Handler) PreviewBasket(c *fiber.Ctx) error {
+	var input models.ApplyPromoCode
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+		}
+	}
+
+	quote, err := h.orderService.PreviewBasket(c.Context(), currentUser(c).ID, input.PromoCode)
+	if err != nil {
+		return orderErrorResp(c, "error_preview_basket", err)
+	}
+
+	return c.JSON(web.OkResp("success_basket_priced", quote))
+}
+
 // GetOrder retrieves order by ID
 // @Summary Get order by ID
 // @Description Returns order details with all products
@@ -42,7 +135,11 @@ func (h *Handler) CreateOrder(c *fiber.Ctx) error {
 // @Param id path int true "Order ID"
 // @Success 200 {object} models.OrderResponse "Order retrieved successfully"
 // @Failure 400 {object} models.ErrorResponse "Invalid order ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's data"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/orders/{id} [get]
 func (h *Handler) GetOrder(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -55,48 +152,198 @@ func (h *Handler) GetOrder(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_order", err.Error()))
 	}
+	if order.ID == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_order_not_found", "Order not found"))
+	}
+	if order.UserID != currentUser(c).ID {
+		isAdmin, err := h.adminService.IsAdmin(c.Context(), currentUser(c).ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_check_admin", err.Error()))
+		}
+		if !isAdmin {
+			return c.Status(fiber.StatusForbidden).JSON(web.ErrorResp("error_forbidden", "Access to another user's order is not allowed"))
+		}
+	}
 
 	return c.JSON(web.OkResp("success_order_retrieved", order))
 }
 
-// GetUserOrders retrieves all orders for a specific user
+// GetUserOrders lists the orders of a specific user page by page
 // @Summary Get user's orders
-// @Description Returns all orders for a specific user
+// @Description Returns one page of the user's orders, newest first. Pass next_cursor from the response as cursor to get the following page
 // @Tags orders
 // @Produce json
 // @Param user_id path int true "User ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, at most 100" default(20)
 // @Success 200 {object} models.OrderListResponse "User's orders retrieved successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 400 {object} models.ErrorResponse "Invalid user ID, cursor or limit"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's data"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/orders/user/{user_id} [get]
 func (h *Handler) GetUserOrders(c *fiber.Ctx) error {
-	userIDStr := c.Params("user_id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	page, err := parsePage(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_user_id", "Invalid user ID"))
+		return pageErrorResp(c, err)
 	}
 
-	orders, err := h.orderService.GetUserOrders(c.Context(), userID)
+	orders, next, err := h.orderService.GetUserOrders(c.Context(), currentUser(c).ID, page)
+	if isPageError(err) {
+		return pageErrorResp(c, err)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_user_orders", err.Error()))
 	}
 
-	return c.JSON(web.OkResp("success_user_orders_retrieved", orders))
+	return c.JSON(web.PageResp("success_user_orders_retrieved", orders, next))
 }
 
-// GetAllOrders retrieves all orders
+// GetAllOrders lists all orders page by page
 // @Summary Get all orders
-// @Description Returns all orders in the system
+// @Description Returns one page of orders, newest first. Pass next_cursor from the response as cursor to get the following page
 // @Tags orders
 // @Produce json
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, at most 100" default(20)
 // @Success 200 {object} models.OrderListResponse "All orders retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor or limit"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/orders/all [get]
 func (h *Handler) GetAllOrders(c *fiber.Ctx) error {
-	orders, err := h.orderService.GetAll(c.Context())
+	page, err := parsePage(c)
+	if err != nil {
+		return pageErrorResp(c, err)
+	}
+
+	orders, next, err := h.orderService.GetAll(c.Context(), page)
+	if isPageError(err) {
+		return pageErrorResp(c, err)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_all_orders", err.Error()))
 	}
 
-	return c.JSON(web.OkResp("success_all_orders_retrieved", orders))
+	return c.JSON(web.PageResp("success_all_orders_retrieved", orders, next))
+}
+
+// UpdateOrderStatus moves an order to a new status
+// @Summary Update order status
+// @Description Moves an order along its lifecycle (pending → paid → assembling → shipped → delivered, with cancelled and refunded branches) and records the change in the order history
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param status body models.UpdateOrderStatus true "New status"
+// @Success 200 {object} models.OrderResponse "Order status updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID or unknown status"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "Transition is not allowed or status changed concurrently"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/orders/{id}/status [patch]
+func (h *Handler) UpdateOrderStatus(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_order_id", "Invalid order ID"))
+	}
+
+	var input models.UpdateOrderStatus
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	order, err := h.orderService.UpdateStatus(c.Context(), id, input.Status, currentUser(c).ID, input.Comment)
+	if err != nil {
+		return orderErrorResp(c, "error_update_order_status", err)
+	}
+
+	return c.JSON(web.OkResp("success_order_status_updated", order))
+}
+
+// CancelOrder cancels the current user's order
+// @Summary Cancel order
+// @Description Cancels the user's own order while it is still pending or paid and returns reserved items to stock
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param reason body models.CancelOrder false "Cancellation reason"
+// @Success 200 {object} models.OrderResponse "Order cancelled"
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID or request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's order"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "Order can no longer be cancelled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *Handler) CancelOrder(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_order_id", "Invalid order ID"))
+	}
+
+	var input models.CancelOrder
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+		}
+	}
+
+	order, err := h.orderService.CancelOrder(c.Context(), id, currentUser(c).ID, input.Reason, false)
+	if err != nil {
+		return orderErrorResp(c, "error_cancel_order", err)
+	}
+
+	return c.JSON(web.OkResp("success_order_cancelled", order))
+}
+
+// orderErrorResp maps order errors to response statuses, falling back to fallbackStatus.
+func orderErrorResp(c *fiber.Ctx, fallbackStatus string, err error) error {
+	var stockErr *orders.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_insufficient_stock", stockErr))
+	}
+	var basketErr *orders.UnavailableBasketError
+	if errors.As(err, &basketErr) {
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_basket_unavailable", basketErr))
+	}
+	var promoErr *orders.PromoCodeError
+	if errors.As(err, &promoErr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(web.ErrorResp("error_invalid_promo_code", promoErr))
+	}
+
+	switch {
+	case errors.Is(err, orders.ErrEmptyOrder), errors.Is(err, orders.ErrInvalidQuantity):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_order_items", err.Error()))
+	case errors.Is(err, orders.ErrIdempotencyKeyReused):
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_idempotency_key_reused", err.Error()))
+	case errors.Is(err, orders.ErrEmptyBasket):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_basket_empty", err.Error()))
+	case errors.Is(err, orders.ErrProductNotFound):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(web.ErrorResp("error_product_not_found", err.Error()))
+	case errors.Is(err, orders.ErrVariantNotFound), errors.Is(err, orders.ErrVariantRequired):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(web.ErrorResp("error_invalid_variant", err.Error()))
+	case errors.Is(err, orders.ErrPriceTierMissing):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(web.ErrorResp("error_price_not_found", err.Error()))
+	case errors.Is(err, orders.ErrOrderNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_order_not_found", err.Error()))
+	case errors.Is(err, orders.ErrUnknownStatus):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_unknown_order_status", err.Error()))
+	case errors.Is(err, orders.ErrNotOrderOwner):
+		return c.Status(fiber.StatusForbidden).JSON(web.ErrorResp("error_forbidden", "Access to another user's order is not allowed"))
+	case errors.Is(err, orders.ErrNotCancellable):
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_order_not_cancellable", err.Error()))
+	case errors.Is(err, orders.ErrInvalidTransition), errors.Is(err, orders.ErrStatusConflict):
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_invalid_status_transition", err.Error()))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp(fallbackStatus, err.Error()))
+	}
 }
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/outbox"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultOutboxListLimit = 100
+
+// GetOutboxEvents lists outbox events
+// @Summary List outbox events
+// @Description Returns the latest outbox events, optionally filtered by status (pending, done, dead)
+// @Tags outbox
+// @Produce json
+// @Param status query string false "Event status" Enums(pending, done, dead)
+// @Param limit query int false "Maximum number of events" default(100)
+// @Success 200 {object} models.OutboxEventListResponse "Outbox events retrieved"
+// @Failure 400 {object} models.ErrorResponse "Invalid status or limit"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/outbox [get]
+func (h *Handler) GetOutboxEvents(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", models.OutboxStatusPending, models.OutboxStatusDone, models.OutboxStatusDead:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_status", "Unknown outbox status"))
+	}
+
+	limit := c.QueryInt("limit", defaultOutboxListLimit)
+	if limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_limit", "Limit must be positive"))
+	}
+
+	events, err := h.outboxService.ListEvents(c.Context(), status, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_outbox_events", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_outbox_events_retrieved", events))
+}
+
+// ReplayOutboxEvent schedules a dead event for delivery again
+// @Summary Replay outbox event
+// @Description Resets a dead outbox event so the dispatcher delivers it again
+// @Tags outbox
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} models.SuccessResponse "Event scheduled for replay"
+// @Failure 400 {object} models.ErrorResponse "Invalid event ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "No dead event with this ID"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/outbox/{id}/replay [post]
+func (h *Handler) ReplayOutboxEvent(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_event_id", "Invalid event ID"))
+	}
+
+	err = h.outboxService.ReplayEvent(c.Context(), id)
+	if errors.Is(err, outbox.ErrEventNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_event_not_found", "No dead event with this ID"))
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_replay_event", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_event_replayed", nil))
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"errors"
+
+	"telegramshop_backend/pkg/pagination"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parsePage reads the cursor and limit query parameters shared by paginated listings.
+func parsePage(c *fiber.Ctx) (pagination.Page, error) {
+	return pagination.NewPage(c.Query("cursor"), c.QueryInt("limit", 0))
+}
+
+func isPageError(err error) bool {
+	return errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, pagination.ErrInvalidLimit)
+}
+
+func pageErrorResp(c *fiber.Ctx, err error) error {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_cursor", "Invalid cursor"))
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_limit", "Limit must be positive"))
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/payments"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateInvoiceLink creates a Telegram invoice link for the user's order
+// @Summary Create invoice link
+// @Description Creates a Telegram Payments invoice link for a pending order. The Mini App opens it with Telegram.WebApp.openInvoice
+// @Tags payments
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.InvoiceLinkResponse "Invoice link created"
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's order"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "Order is not awaiting payment"
+// @Failure 502 {object} models.ErrorResponse "Telegram API error"
+// @Security TelegramAuth
+// @Router /api/v1/orders/{id}/invoice [post]
+func (h *Handler) CreateInvoiceLink(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_order_id", "Invalid order ID"))
+	}
+
+	link, err := h.paymentService.CreateInvoiceLink(c.Context(), id, currentUser(c).ID)
+	switch {
+	case errors.Is(err, payments.ErrOrderNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_order_not_found", err.Error()))
+	case errors.Is(err, payments.ErrNotOrderOwner):
+		return c.Status(fiber.StatusForbidden).JSON(web.ErrorResp("error_forbidden", "Access to another user's order is not allowed"))
+	case errors.Is(err, payments.ErrOrderNotPayable):
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_order_not_payable", err.Error()))
+	case err != nil:
+		return c.Status(fiber.StatusBadGateway).JSON(web.ErrorResp("error_create_invoice", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_invoice_created", models.InvoiceLink{OrderID: int64(id), InvoiceLink: link}))
+}
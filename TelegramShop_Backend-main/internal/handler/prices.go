@@ -18,7 +18,10 @@ import (
 // @Param price body models.Price true "Price creation data"
 // @Success 200 {object} models.PriceResponse "Price successfully created"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/prices [post]
 func (h *Handler) CreatePrice(c *fiber.Ctx) error {
 	var input models.Price
@@ -96,7 +99,10 @@ func (h *Handler) GetPricesByProductID(c *fiber.Ctx) error {
 // @Param price body models.UpdatePriceInput true "Updated price data"
 // @Success 200 {object} models.SuccessResponse "Price successfully updated"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/prices/{id} [put]
 func (h *Handler) UpdatePrice(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -124,7 +130,10 @@ func (h *Handler) UpdatePrice(c *fiber.Ctx) error {
 // @Param id path int true "Price ID"
 // @Success 200 {object} models.SuccessResponse "Price successfully deleted"
 // @Failure 400 {object} models.ErrorResponse "Invalid price ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/prices/{id} [delete]
 func (h *Handler) DeletePrice(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -148,7 +157,10 @@ func (h *Handler) DeletePrice(c *fiber.Ctx) error {
 // @Param product_id path int true "Product ID"
 // @Success 200 {object} models.SuccessResponse "Prices successfully deleted"
 // @Failure 400 {object} models.ErrorResponse "Invalid product ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/prices/product/{product_id} [delete]
 func (h *Handler) DeletePricesByProductID(c *fiber.Ctx) error {
 	productIDStr := c.Params("product_id")
@@ -174,7 +186,10 @@ func (h *Handler) DeletePricesByProductID(c *fiber.Ctx) error {
 // @Param count body models.UpdatePriceCount true "New count value"
 // @Success 200 {object} models.SuccessResponse "Price count successfully updated"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/prices/{id}/count [put]
 func (h *Handler) UpdatePriceCount(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 
 	"telegramshop_backend/internal/models"
+	productsService "telegramshop_backend/internal/service/products"
+	"telegramshop_backend/pkg/pagination"
 	"telegramshop_backend/pkg/web"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,7 +24,10 @@ import (
 // @Param product body models.Product true "Product creation data"
 // @Success 200 {object} models.ProductResponse "Product successfully created"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products [post]
 func (h *Handler) CreateProduct(c *fiber.Ctx) error {
 	var input models.Product
@@ -59,21 +68,187 @@ func (h *Handler) GetProductByID(c *fiber.Ctx) error {
 	return c.JSON(web.OkResp("success_product_retrieved", product))
 }
 
-// GetAllProducts retrieves all products
-// @Summary Get all products
-// @Description Returns all products in the system
+// GetAllProducts lists products page by page
+// @Summary List products
+// @Description Returns one page of products matching the filters. Pass next_cursor from the response as cursor to get the following page; it is absent on the last page. Price filters and sorting use the single-item price tier
 // @Tags products
 // @Produce json
-// @Success 200 {object} models.ProductListResponse "All products retrieved successfully"
+// @Param category_id query int false "Category ID"
+// @Param firm_id query int false "Firm ID"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param in_stock query bool false "Only products in stock"
+// @Param attr.color query string false "Attribute filter: any attr.<key>=<value> keeps products whose attribute <key> equals <value>"
+// @Param sort query string false "Sort key" Enums(newest, price_asc, price_desc, popularity, rating) default(newest)
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} models.ProductListResponse "Products retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid filter, sort, cursor or limit"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /api/v1/products [get]
 func (h *Handler) GetAllProducts(c *fiber.Ctx) error {
-	products, err := h.productService.GetAllProducts(c.Context())
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_filter", err.Error()))
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return pageErrorResp(c, err)
+	}
+
+	products, next, err := h.productService.ListProducts(c.Context(), filter, page)
+	if isPageError(err) {
+		return pageErrorResp(c, err)
+	}
+	if errors.Is(err, productsService.ErrUnknownSort) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_sort", "Unknown sort key"))
+	}
+	if errors.Is(err, productsService.ErrInvalidPriceRange) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_filter", err.Error()))
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_all_products", err.Error()))
 	}
 
-	return c.JSON(web.OkResp("success_products_retrieved", products))
+	return c.JSON(web.PageResp("success_products_retrieved", products, next))
+}
+
+// SearchProducts searches the catalog
+// @Summary Search products
+// @Description Full-text search over product name, description, firm and category name with Russian morphology, ranked by relevance. Name matches weigh most. When nothing matches, products with a similar name are returned instead (fuzzy is true). Snippets are HTML with matches wrapped in <mark>
+// @Tags products
+// @Produce json
+// @Param q query string true "Search query, supports quotes, OR and -exclusion"
+// @Param limit query int false "Maximum number of results, at most 100" default(20)
+// @Success 200 {object} models.ProductSearchResponse "Search results"
+// @Failure 400 {object} models.ErrorResponse "Empty or too long query, invalid limit"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/v1/products/search [get]
+func (h *Handler) SearchProducts(c *fiber.Ctx) error {
+	page, err := pagination.NewPage("", c.QueryInt("limit", 0))
+	if err != nil {
+		return pageErrorResp(c, err)
+	}
+
+	results, err := h.productService.Search(c.Context(), c.Query("q"), page.Limit)
+	if errors.Is(err, productsService.ErrEmptyQuery) || errors.Is(err, productsService.ErrQueryTooLong) {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_query", err.Error()))
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_search_products", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_products_found", results))
+}
+
+// SuggestProducts returns autocomplete suggestions
+// @Summary Suggest products, firms and categories
+// @Description Autocomplete for the search box. Returns products, firms and categories with a word starting with the prefix, best sellers first; a firm or category ranks by the total sales of its products. Served from memory, so catalog changes show up after a short delay
+// @Tags products
+// @Produce json
+// @Param prefix query string true "Beginning of the typed text"
+// @Param limit query int false "Maximum number of suggestions, at most 100" default(20)
+// @Success 200 {object} models.SuggestionListResponse "Suggestions"
+// @Failure 400 {object} models.ErrorResponse "Empty prefix or invalid limit"
+// @Router /api/v1/products/suggest [get]
+func (h *Handler) SuggestProducts(c *fiber.Ctx) error {
+	prefix := strings.TrimSpace(c.Query("prefix"))
+	if prefix == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_prefix", "Prefix is required"))
+	}
+
+	page, err := pagination.NewPage("", c.QueryInt("limit", 0))
+	if err != nil {
+		return pageErrorResp(c, err)
+	}
+
+	return c.JSON(web.OkResp("success_suggestions_found", h.suggestService.Suggest(prefix, page.Limit)))
+}
+
+// GetProductFacets returns attribute values with product counts
+// @Summary Product attribute facets
+// @Description Returns every attribute key with its distinct values and the number of products having each, for rendering catalog filter chips. Use the values as attr.<key>=<value> filters of GET /products
+// @Tags products
+// @Produce json
+// @Param category_id query int false "Limit facets to one category"
+// @Success 200 {object} models.ProductFacetListResponse "Facets retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid category ID"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/v1/products/facets [get]
+func (h *Handler) GetProductFacets(c *fiber.Ctx) error {
+	var categoryID *int64
+	if raw := c.Query("category_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_category_id", "Invalid category ID"))
+		}
+		categoryID = &id
+	}
+
+	facets, err := h.productService.GetFacets(c.Context(), categoryID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_facets", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_facets_retrieved", facets))
+}
+
+const attributeParamPrefix = "attr."
+
+func parseProductFilter(c *fiber.Ctx) (models.ProductFilter, error) {
+	filter := models.ProductFilter{Sort: c.Query("sort")}
+
+	if raw := c.Query("category_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return models.ProductFilter{}, fmt.Errorf("invalid category_id")
+		}
+		filter.CategoryID = &id
+	}
+	if raw := c.Query("firm_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return models.ProductFilter{}, fmt.Errorf("invalid firm_id")
+		}
+		filter.FirmID = &id
+	}
+	if raw := c.Query("min_price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return models.ProductFilter{}, fmt.Errorf("invalid min_price")
+		}
+		filter.MinPrice = &price
+	}
+	if raw := c.Query("max_price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return models.ProductFilter{}, fmt.Errorf("invalid max_price")
+		}
+		filter.MaxPrice = &price
+	}
+	if raw := c.Query("in_stock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			return models.ProductFilter{}, fmt.Errorf("invalid in_stock")
+		}
+		filter.InStock = inStock
+	}
+	for param, value := range c.Queries() {
+		key, ok := strings.CutPrefix(param, attributeParamPrefix)
+		if !ok {
+			continue
+		}
+		if key == "" {
+			return models.ProductFilter{}, fmt.Errorf("attribute filter needs a key")
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = map[string]string{}
+		}
+		filter.Attributes[key] = value
+	}
+
+	return filter, nil
 }
 
 // UpdateProduct updates product details
@@ -86,7 +261,10 @@ func (h *Handler) GetAllProducts(c *fiber.Ctx) error {
 // @Param product body models.UpdateProductInput true "Updated product data"
 // @Success 200 {object} models.SuccessResponse "Product successfully updated"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id} [put]
 func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -114,7 +292,10 @@ func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
 // @Param id path int true "Product ID"
 // @Success 200 {object} models.SuccessResponse "Product successfully deleted"
 // @Failure 400 {object} models.ErrorResponse "Invalid product ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id} [delete]
 func (h *Handler) DeleteProduct(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -123,9 +304,11 @@ func (h *Handler) DeleteProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid product ID"))
 	}
 
+	before, _ := h.productService.GetProductByID(c.Context(), id)
 	if err := h.productService.DeleteProduct(c.Context(), id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_delete_product", err.Error()))
 	}
+	h.cleanupImages(c.Context(), before.Image...)
 
 	return c.JSON(web.OkResp("success_product_deleted", nil))
 }
@@ -140,7 +323,10 @@ func (h *Handler) DeleteProduct(c *fiber.Ctx) error {
 // @Param image body models.ImagesInput true "Image data"
 // @Success 200 {object} models.SuccessResponse "Product image successfully added"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id}/images [post]
 func (h *Handler) AddProductImage(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -171,7 +357,10 @@ func (h *Handler) AddProductImage(c *fiber.Ctx) error {
 // @Param image body models.ImagesInput true "Image data to remove"
 // @Success 200 {object} models.SuccessResponse "Product image successfully removed"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id}/images [delete]
 func (h *Handler) RemoveProductImage(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -188,6 +377,7 @@ func (h *Handler) RemoveProductImage(c *fiber.Ctx) error {
 	if err := h.productService.RemoveProductImage(c.Context(), id, input.Image); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_remove_product_image", err.Error()))
 	}
+	h.cleanupImages(c.Context(), input.Image)
 
 	return c.JSON(web.OkResp("success_product_image_removed", nil))
 }
@@ -202,7 +392,10 @@ func (h *Handler) RemoveProductImage(c *fiber.Ctx) error {
 // @Param images body models.ImagesInput true "Array of image data"
 // @Success 200 {object} models.SuccessResponse "Product images successfully set"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id}/images [put]
 func (h *Handler) SetProductImages(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -216,9 +409,17 @@ func (h *Handler) SetProductImages(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_body", "Invalid body"))
 	}
 
+	before, _ := h.productService.GetProductByID(c.Context(), id)
 	if err := h.productService.SetProductImages(c.Context(), id, input.Images); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_set_product_images", err.Error()))
 	}
+	var dropped []string
+	for _, image := range before.Image {
+		if !slices.Contains(input.Images, image) {
+			dropped = append(dropped, image)
+		}
+	}
+	h.cleanupImages(c.Context(), dropped...)
 
 	return c.JSON(web.OkResp("success_product_images_set", nil))
 }
@@ -233,7 +434,10 @@ func (h *Handler) SetProductImages(c *fiber.Ctx) error {
 // @Param count body models.CountInput true "Count to increment"
 // @Success 200 {object} models.SuccessResponse "Sell count successfully incremented"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id}/sell-count [put]
 func (h *Handler) IncrementSellCount(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -256,7 +460,7 @@ func (h *Handler) IncrementSellCount(c *fiber.Ctx) error {
 
 // UpdateStock updates product stock
 // @Summary Update stock
-// @Description Updates the stock count for a product
+// @Description Updates the stock count for a product. The stock of a product with variants is the sum of its variants' stock and is changed through them
 // @Tags products
 // @Accept json
 // @Produce json
@@ -264,7 +468,11 @@ func (h *Handler) IncrementSellCount(c *fiber.Ctx) error {
 // @Param stock body models.StockInput true "New stock value"
 // @Success 200 {object} models.SuccessResponse "Stock successfully updated"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 409 {object} models.ErrorResponse "Product has variants"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/products/{id}/stock [put]
 func (h *Handler) UpdateStock(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -278,7 +486,11 @@ func (h *Handler) UpdateStock(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_body", "Invalid body"))
 	}
 
-	if err := h.productService.UpdateStock(c.Context(), id, input.Stock); err != nil {
+	err = h.productService.UpdateStock(c.Context(), id, input.Stock)
+	if errors.Is(err, productsService.ErrStockFromVariants) {
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_stock_from_variants", err.Error()))
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_update_stock", err.Error()))
 	}
 
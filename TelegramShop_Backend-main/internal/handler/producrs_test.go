@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+)
+
+func TestParseProductFilter(t *testing.T) {
+	parse := func(t *testing.T, target string) (models.ProductFilter, error) {
+		var (
+			filter models.ProductFilter
+			err    error
+		)
+		app := fiber.New()
+		app.Get("/products", func(c *fiber.Ctx) error {
+			filter, err = parseProductFilter(c)
+			return nil
+		})
+		_, testErr := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+		require.NoError(t, testErr)
+		return filter, err
+	}
+
+	t.Run("AllFilters", func(t *testing.T) {
+		filter, err := parse(t, "/products?category_id=1&firm_id=2&min_price=10&max_price=99.5&in_stock=true&sort=rating&attr.color=black&attr.memory=128GB")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), *filter.CategoryID)
+		require.Equal(t, int64(2), *filter.FirmID)
+		require.Equal(t, 10.0, *filter.MinPrice)
+		require.Equal(t, 99.5, *filter.MaxPrice)
+		require.True(t, filter.InStock)
+		require.Equal(t, models.ProductSortRating, filter.Sort)
+		require.Equal(t, map[string]string{"color": "black", "memory": "128GB"}, filter.Attributes)
+	})
+
+	t.Run("NoFilters", func(t *testing.T) {
+		filter, err := parse(t, "/products")
+		require.NoError(t, err)
+		require.Equal(t, models.ProductFilter{}, filter)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, target := range []string{
+			"/products?category_id=x",
+			"/products?min_price=cheap",
+			"/products?in_stock=maybe",
+			"/products?attr.=black",
+		} {
+			_, err := parse(t, target)
+			require.Error(t, err, target)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/promotions"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// promotionErrorResp maps promo code service errors to a status code and response.
+func promotionErrorResp(c *fiber.Ctx, err error, fallbackStatus string) error {
+	switch {
+	case errors.Is(err, promotions.ErrPromoCodeNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_promo_code_not_found", "Promo code not found"))
+	case errors.Is(err, promotions.ErrDuplicateCode):
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_promo_code_exists", err.Error()))
+	case errors.Is(err, promotions.ErrInvalidPromoCode), errors.Is(err, promotions.ErrUnknownReference):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_promo_code_input", err.Error()))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp(fallbackStatus, err.Error()))
+	}
+}
+
+func parsePromoCodeID(c *fiber.Ctx) (int64, error) {
+	return strconv.ParseInt(c.Params("id"), 10, 64)
+}
+
+// CreatePromoCode creates a promo code
+// @Summary Create promo code
+// @Description Creates a promo code. kind is percent (value in (0, 100]), fixed (value in currency) or free_item (product_id given away once). percent and fixed may be limited to a category subtree and/or a firm. Codes are stored upper-case
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param promo_code body models.PromoCodeInput true "Promo code"
+// @Success 201 {object} models.PromoCodeResponse "Promo code created"
+// @Failure 400 {object} models.ErrorResponse "Invalid promo code or unknown product, category or firm"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 409 {object} models.ErrorResponse "Code already exists"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/promotions [post]
+func (h *Handler) CreatePromoCode(c *fiber.Ctx) error {
+	var input models.PromoCodeInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	code, err := h.promotionService.CreatePromoCode(c.Context(), input)
+	if err != nil {
+		return promotionErrorResp(c, err, "error_create_promo_code")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(web.OkResp("success_promo_code_created", code))
+}
+
+// GetPromoCodes lists promo codes
+// @Summary List promo codes
+// @Description Returns all promo codes, newest first, with the number of times each was used
+// @Tags promotions
+// @Produce json
+// @Success 200 {object} models.PromoCodeListResponse "Promo codes retrieved"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/promotions [get]
+func (h *Handler) GetPromoCodes(c *fiber.Ctx) error {
+	codes, err := h.promotionService.GetPromoCodes(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_promo_codes", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_promo_codes_retrieved", codes))
+}
+
+// GetPromoCode returns a promo code
+// @Summary Get promo code
+// @Description Returns the promo code with the number of times it was used
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promo code ID"
+// @Success 200 {object} models.PromoCodeResponse "Promo code retrieved"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Promo code not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/promotions/{id} [get]
+func (h *Handler) GetPromoCode(c *fiber.Ctx) error {
+	id, err := parsePromoCodeID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_promo_code_id", "Invalid promo code ID"))
+	}
+
+	code, err := h.promotionService.GetPromoCodeByID(c.Context(), id)
+	if err != nil {
+		return promotionErrorResp(c, err, "error_get_promo_code")
+	}
+
+	return c.JSON(web.OkResp("success_promo_code_retrieved", code))
+}
+
+// UpdatePromoCode replaces a promo code
+// @Summary Update promo code
+// @Description Replaces all fields of the promo code; omitted active defaults to true. Orders already placed keep their discounts
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path int true "Promo code ID"
+// @Param promo_code body models.PromoCodeInput true "Promo code"
+// @Success 200 {object} models.PromoCodeResponse "Promo code updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID, promo code or unknown product, category or firm"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Promo code not found"
+// @Failure 409 {object} models.ErrorResponse "Code already exists"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/promotions/{id} [put]
+func (h *Handler) UpdatePromoCode(c *fiber.Ctx) error {
+	id, err := parsePromoCodeID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_promo_code_id", "Invalid promo code ID"))
+	}
+
+	var input models.PromoCodeInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	code, err := h.promotionService.UpdatePromoCode(c.Context(), id, input)
+	if err != nil {
+		return promotionErrorResp(c, err, "error_update_promo_code")
+	}
+
+	return c.JSON(web.OkResp("success_promo_code_updated", code))
+}
+
+// DeletePromoCode deletes a promo code
+// @Summary Delete promo code
+// @Description Deletes the promo code. Orders that used it keep their discount lines and the code text
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promo code ID"
+// @Success 200 {object} models.SuccessResponse "Promo code deleted"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Promo code not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/promotions/{id} [delete]
+func (h *Handler) DeletePromoCode(c *fiber.Ctx) error {
+	id, err := parsePromoCodeID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_promo_code_id", "Invalid promo code ID"))
+	}
+
+	if err := h.promotionService.DeletePromoCode(c.Context(), id); err != nil {
+		return promotionErrorResp(c, err, "error_delete_promo_code")
+	}
+
+	return c.JSON(web.OkResp("success_promo_code_deleted", nil))
+}
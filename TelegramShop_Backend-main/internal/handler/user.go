@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/users"
 	"telegramshop_backend/pkg/web"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,7 +20,10 @@ import (
 // @Param user body models.CreateUser true "User creation data"
 // @Success 200 {object} models.UserResponse "User successfully created"
 // @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/users [post]
 func (h *Handler) CreateUser(c *fiber.Ctx) error {
 	var input models.CreateUser
@@ -36,13 +41,17 @@ func (h *Handler) CreateUser(c *fiber.Ctx) error {
 
 // GetUser retrieves user by ID
 // @Summary Get user by ID
-// @Description Returns user details by ID
+// @Description Returns user details by ID. Customers may only get themselves; admins may get anyone
 // @Tags users
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} models.UserResponse "User retrieved successfully"
 // @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Access to another user's data"
+// @Failure 404 {object} models.ErrorResponse "User not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/users/{id} [get]
 func (h *Handler) GetUser(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -52,6 +61,9 @@ func (h *Handler) GetUser(c *fiber.Ctx) error {
 	}
 
 	user, err := h.userService.GetUserByID(c.Context(), id)
+	if errors.Is(err, users.ErrUserNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_user_not_found", err.Error()))
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_user", err.Error()))
 	}
@@ -67,7 +79,10 @@ func (h *Handler) GetUser(c *fiber.Ctx) error {
 // @Param id path int true "User ID"
 // @Success 200 {object} models.SuccessResponse "User successfully deleted"
 // @Failure 400 {object} models.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/users/{id} [delete]
 func (h *Handler) DeleteUser(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -83,18 +98,71 @@ func (h *Handler) DeleteUser(c *fiber.Ctx) error {
 	return c.JSON(web.OkResp("success_user_deleted", nil))
 }
 
-// GetAllUsers retrieves all users
+// GetAllUsers lists users page by page
 // @Summary Get all users
-// @Description Returns all users in the system
+// @Description Returns one page of users ordered by ID. Pass next_cursor from the response as cursor to get the following page
 // @Tags users
 // @Produce json
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, at most 100" default(20)
 // @Success 200 {object} models.UserListResponse "All users retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor or limit"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
 // @Router /api/v1/users [get]
 func (h *Handler) GetAllUsers(c *fiber.Ctx) error {
-	users, err := h.userService.GetAll(c.Context())
+	page, err := parsePage(c)
+	if err != nil {
+		return pageErrorResp(c, err)
+	}
+
+	users, next, err := h.userService.GetAll(c.Context(), page)
+	if isPageError(err) {
+		return pageErrorResp(c, err)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_all_users", err.Error()))
 	}
-	return c.JSON(web.OkResp("success_all_users_retrieved", users))
+	return c.JSON(web.PageResp("success_all_users_retrieved", users, next))
+}
+
+// GetCurrentUser returns the authenticated user
+// @Summary Get current user
+// @Description Returns the user identified by Telegram init data, registering it on first request
+// @Tags users
+// @Produce json
+// @Success 200 {object} models.UserResponse "User retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Security TelegramAuth
+// @Router /api/v1/users/me [get]
+func (h *Handler) GetCurrentUser(c *fiber.Ctx) error {
+	return c.JSON(web.OkResp("success_user_retrieved", currentUser(c)))
+}
+
+// SetBasketReminders turns abandoned basket reminders on or off
+// @Summary Set basket reminders
+// @Description Opts the authenticated user in or out of bot reminders about a basket left untouched. Reminders are on by default
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param settings body models.BasketReminderSettings true "Reminder settings"
+// @Success 200 {object} models.BasketReminderSettingsResponse "Settings updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/users/me/basket-reminders [put]
+func (h *Handler) SetBasketReminders(c *fiber.Ctx) error {
+	var settings models.BasketReminderSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	if err := h.reminderService.SetRemindersEnabled(c.Context(), currentUser(c).ID, settings.Enabled); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_update_basket_reminders", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_basket_reminders_updated", settings))
 }
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegramshop_backend/internal/models"
+	productsService "telegramshop_backend/internal/service/products"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetProductVariants lists product variants
+// @Summary Get product variants
+// @Description Returns the variants (SKUs) of a product with their attributes, stock and price override
+// @Tags variants
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.ProductVariantListResponse "Variants retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Invalid product ID"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/v1/products/{id}/variants [get]
+func (h *Handler) GetProductVariants(c *fiber.Ctx) error {
+	productID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid product ID"))
+	}
+
+	variants, err := h.productService.GetVariants(c.Context(), productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_variants", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_variants_retrieved", variants))
+}
+
+// CreateProductVariant adds a variant to a product
+// @Summary Create product variant
+// @Description Adds a variant with its own SKU, attributes and stock. The product stock becomes the sum of its variants' stock. price, when set, replaces the product's price tiers for this variant
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant body models.ProductVariantInput true "Variant data"
+// @Success 200 {object} models.ProductVariantResponse "Variant successfully created"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or variant data"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Product not found"
+// @Failure 409 {object} models.ErrorResponse "SKU already used"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/products/{id}/variants [post]
+func (h *Handler) CreateProductVariant(c *fiber.Ctx) error {
+	productID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid product ID"))
+	}
+
+	var input models.ProductVariantInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	variant, err := h.productService.CreateVariant(c.Context(), productID, input)
+	if err != nil {
+		return variantErrorResp(c, "error_create_variant", err)
+	}
+
+	return c.JSON(web.OkResp("success_variant_created", variant))
+}
+
+// UpdateProductVariant updates a product variant
+// @Summary Update product variant
+// @Description Replaces the SKU, attributes, stock and price override of a variant and recomputes the product stock
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id path int true "Variant ID"
+// @Param variant body models.ProductVariantInput true "Variant data"
+// @Success 200 {object} models.ProductVariantResponse "Variant successfully updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or variant data"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Variant not found"
+// @Failure 409 {object} models.ErrorResponse "SKU already used"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/products/{id}/variants/{variant_id} [put]
+func (h *Handler) UpdateProductVariant(c *fiber.Ctx) error {
+	productID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid product ID"))
+	}
+	variantID, err := strconv.ParseInt(c.Params("variant_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid variant ID"))
+	}
+
+	var input models.ProductVariantInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	variant, err := h.productService.UpdateVariant(c.Context(), productID, variantID, input)
+	if err != nil {
+		return variantErrorResp(c, "error_update_variant", err)
+	}
+
+	return c.JSON(web.OkResp("success_variant_updated", variant))
+}
+
+// DeleteProductVariant deletes a product variant
+// @Summary Delete product variant
+// @Description Deletes a variant, removes it from baskets and favorites and recomputes the product stock. Past order lines keep their price but lose the variant reference
+// @Tags variants
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id path int true "Variant ID"
+// @Success 200 {object} models.SuccessResponse "Variant successfully deleted"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Variant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/products/{id}/variants/{variant_id} [delete]
+func (h *Handler) DeleteProductVariant(c *fiber.Ctx) error {
+	productID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid product ID"))
+	}
+	variantID, err := strconv.ParseInt(c.Params("variant_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_id", "Invalid variant ID"))
+	}
+
+	if err := h.productService.DeleteVariant(c.Context(), productID, variantID); err != nil {
+		return variantErrorResp(c, "error_delete_variant", err)
+	}
+
+	return c.JSON(web.OkResp("success_variant_deleted", nil))
+}
+
+func variantErrorResp(c *fiber.Ctx, status string, err error) error {
+	switch {
+	case errors.Is(err, productsService.ErrInvalidVariant):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_variant", err.Error()))
+	case errors.Is(err, productsService.ErrProductNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_product_not_found", err.Error()))
+	case errors.Is(err, productsService.ErrVariantNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_variant_not_found", err.Error()))
+	case errors.Is(err, productsService.ErrDuplicateSKU):
+		return c.Status(fiber.StatusConflict).JSON(web.ErrorResp("error_duplicate_sku", err.Error()))
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp(status, err.Error()))
+}
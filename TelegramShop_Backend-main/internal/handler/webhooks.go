@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/webhooks"
+	"telegramshop_backend/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultWebhookDeliveryListLimit = 100
+
+// webhookErrorResp maps webhook service errors to a status code and response.
+func webhookErrorResp(c *fiber.Ctx, err error, fallbackStatus string) error {
+	switch {
+	case errors.Is(err, webhooks.ErrSubscriptionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(web.ErrorResp("error_webhook_not_found", "Webhook subscription not found"))
+	case errors.Is(err, webhooks.ErrInvalidURL):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_url", err.Error()))
+	case errors.Is(err, webhooks.ErrEmptySecret):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_secret", err.Error()))
+	case errors.Is(err, webhooks.ErrNoEventTypes), errors.Is(err, webhooks.ErrUnknownEventType):
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_event_types", err.Error()))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp(fallbackStatus, err.Error()))
+	}
+}
+
+func parseWebhookID(c *fiber.Ctx) (int64, error) {
+	return strconv.ParseInt(c.Params("id"), 10, 64)
+}
+
+// CreateWebhook creates a webhook subscription
+// @Summary Create webhook subscription
+// @Description Subscribes an external URL to shop events: order.created, order.status_changed, product.stock_low, review.created. Deliveries are JSON POSTs signed with HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" keyed by the secret, sent in X-Webhook-Signature
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body models.CreateWebhookSubscription true "Subscription"
+// @Success 201 {object} models.WebhookSubscriptionResponse "Subscription created"
+// @Failure 400 {object} models.ErrorResponse "Invalid URL, secret or event types"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/webhooks [post]
+func (h *Handler) CreateWebhook(c *fiber.Ctx) error {
+	var input models.CreateWebhookSubscription
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(c.Context(), input)
+	if err != nil {
+		return webhookErrorResp(c, err, "error_create_webhook")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(web.OkResp("success_webhook_created", subscription))
+}
+
+// GetWebhooks lists webhook subscriptions
+// @Summary List webhook subscriptions
+// @Description Returns all webhook subscriptions. Secrets are never returned
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} models.WebhookSubscriptionListResponse "Subscriptions retrieved"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/webhooks [get]
+func (h *Handler) GetWebhooks(c *fiber.Ctx) error {
+	subscriptions, err := h.webhookService.GetSubscriptions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(web.ErrorResp("error_get_webhooks", err.Error()))
+	}
+
+	return c.JSON(web.OkResp("success_webhooks_retrieved", subscriptions))
+}
+
+// UpdateWebhook updates a webhook subscription
+// @Summary Update webhook subscription
+// @Description Changes the URL, secret, event types or active flag; omitted fields are kept
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param subscription body models.UpdateWebhookSubscription true "Changed fields"
+// @Success 200 {object} models.WebhookSubscriptionResponse "Subscription updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID, URL, secret or event types"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/webhooks/{id} [put]
+func (h *Handler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_webhook_id", "Invalid webhook ID"))
+	}
+
+	var input models.UpdateWebhookSubscription
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_request_body", "Invalid request body"))
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(c.Context(), id, input)
+	if err != nil {
+		return webhookErrorResp(c, err, "error_update_webhook")
+	}
+
+	return c.JSON(web.OkResp("success_webhook_updated", subscription))
+}
+
+// DeleteWebhook deletes a webhook subscription
+// @Summary Delete webhook subscription
+// @Description Deletes the subscription together with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.SuccessResponse "Subscription deleted"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_webhook_id", "Invalid webhook ID"))
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Context(), id); err != nil {
+		return webhookErrorResp(c, err, "error_delete_webhook")
+	}
+
+	return c.JSON(web.OkResp("success_webhook_deleted", nil))
+}
+
+// GetWebhookDeliveries returns the delivery log of a subscription
+// @Summary Webhook delivery log
+// @Description Returns the latest deliveries of the subscription with their status, attempts, last response status and error
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param limit query int false "Maximum number of deliveries" default(100)
+// @Success 200 {object} models.WebhookDeliveryListResponse "Deliveries retrieved"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID or limit"
+// @Failure 401 {object} models.ErrorResponse "Invalid Telegram init data"
+// @Failure 403 {object} models.ErrorResponse "Admin rights required"
+// @Failure 404 {object} models.ErrorResponse "Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Security TelegramAuth
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_webhook_id", "Invalid webhook ID"))
+	}
+
+	limit := c.QueryInt("limit", defaultWebhookDeliveryListLimit)
+	if limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(web.ErrorResp("error_invalid_limit", "Limit must be positive"))
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(c.Context(), id, limit)
+	if err != nil {
+		return webhookErrorResp(c, err, "error_get_webhook_deliveries")
+	}
+
+	return c.JSON(web.OkResp("success_webhook_deliveries_retrieved", deliveries))
+}
@@ -5,6 +5,7 @@ import "time"
 type BasketItem struct {
 	UserID    int64     `db:"user_id" json:"user_id"`
 	ProductID int       `db:"product_id" json:"product_id"`
+	VariantID *int      `db:"variant_id" json:"variant_id,omitempty"`
 	Quantity  int       `db:"quantity" json:"quantity"`
 	AddedAt   time.Time `db:"added_at" json:"added_at"`
 }
@@ -12,10 +13,42 @@ type BasketItem struct {
 type CreateBasketItem struct {
 	UserID    int64 `json:"user_id"`
 	ProductID int   `json:"product_id"`
+	VariantID *int  `json:"variant_id,omitempty"`
 	Quantity  int   `json:"quantity"`
 }
 
 type DeleteBasketItem struct {
 	UserID    int64 `json:"user_id"`
 	ProductID int   `json:"product_id"`
+	VariantID *int  `json:"variant_id,omitempty"`
+}
+
+// Warnings on a basket line that checkout would drop for lack of stock.
+const (
+	BasketWarningOutOfStock        = "out_of_stock"
+	BasketWarningInsufficientStock = "insufficient_stock"
+)
+
+// Basket is the user's basket priced with the same tiers that checkout uses.
+type Basket struct {
+	Items []BasketLine `json:"items"`
+	// Subtotal and Savings only count the lines checkout would order: priced and without a warning.
+	Subtotal float64 `json:"subtotal" example:"890"`
+	Savings  float64 `json:"savings" example:"102.5"`
+}
+
+// BasketLine is a basket item with its product details and price. Priced is false when the
+// variant or a price tier for the quantity is missing, and the line cannot be ordered as it is.
+type BasketLine struct {
+	BasketItem
+	ProductSummary
+	LinePrice
+	Priced bool `json:"priced"`
+	// Warning is out_of_stock or insufficient_stock when the quantity exceeds the stock.
+	Warning string `json:"warning,omitempty" example:"insufficient_stock"`
+}
+
+// ReplaceBasket is the new content of a basket.
+type ReplaceBasket struct {
+	Items []CreateBasketItem `json:"items"`
 }
@@ -0,0 +1,37 @@
+package models
+
+// CatalogItem is one product of a catalog import or export file, with its firm and
+// category referenced by name. An item without ID creates a product. Description, Stock
+// and Attributes are nil when the file leaves them out; an update then keeps the stored values.
+type CatalogItem struct {
+	ID          int64                  `json:"id,omitempty"`
+	Name        string                 `json:"name" example:"iPhone 13"`
+	Description *string                `json:"description"`
+	Firm        string                 `json:"firm" example:"Apple"`
+	Category    string                 `json:"category" example:"Смартфоны"`
+	Stock       *int                   `json:"stock"`
+	Attributes  map[string]interface{} `json:"attributes" swaggertype:"object"`
+	Images      []string               `json:"images"`
+	Prices      []CatalogPrice         `json:"prices"`
+}
+
+type CatalogPrice struct {
+	Count int     `json:"count" example:"1"`
+	Price float64 `json:"price" example:"79990"`
+}
+
+// CatalogRowError points at a problem with one row of an import file. Row is the
+// spreadsheet line for CSV (the header is line 1) and the 1-based array index for JSON.
+type CatalogRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type CatalogImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Errors  []CatalogRowError `json:"errors"`
+}
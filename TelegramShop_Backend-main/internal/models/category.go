@@ -1,12 +1,39 @@
 package models
 
+import "encoding/json"
+
 type Category struct {
-	ID    int64   `db:"id" json:"id"`
-	Name  string  `db:"name" json:"name"`
-	Image *string `db:"image" json:"image"`
+	ID       int64   `db:"id" json:"id"`
+	Name     string  `db:"name" json:"name"`
+	Image    *string `db:"image" json:"image"`
+	ParentID *int64  `db:"parent_id" json:"parent_id"`
 }
 
+// UpdateCategoryInput replaces the name and image of a category. The category moves only
+// when parent_id is sent; null moves it to the root.
 type UpdateCategoryInput struct {
-	Name  string  `db:"name" json:"name"`
-	Image *string `db:"image" json:"image"`
+	Name     string     `json:"name"`
+	Image    *string    `json:"image"`
+	ParentID NullableID `json:"parent_id" swaggertype:"integer"`
+}
+
+// NullableID is an optional, nullable ID in a request body. Set tells an omitted field from null.
+type NullableID struct {
+	Set   bool
+	Value *int64
+}
+
+func (n *NullableID) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
+
+func (n NullableID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
+
+// CategoryNode is a category with its subcategories, as returned by the category tree.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
@@ -5,15 +5,27 @@ import "time"
 type Favorite struct {
 	UserID    int64     `db:"user_id" json:"user_id"`
 	ProductID int       `db:"product_id" json:"product_id"`
+	VariantID *int      `db:"variant_id" json:"variant_id,omitempty"`
 	AddedAt   time.Time `db:"added_at" json:"added_at"`
 }
 
 type CreateFavorite struct {
 	UserID    int64 `json:"user_id"`
 	ProductID int   `json:"product_id"`
+	VariantID *int  `json:"variant_id,omitempty"`
 }
 
 type DeleteFavorite struct {
 	UserID    int64 `json:"user_id"`
 	ProductID int   `json:"product_id"`
+	VariantID *int  `json:"variant_id,omitempty"`
+}
+
+// FavoriteLine is a favorite with its product details. Price is the current price of a
+// single item, absent when one item cannot be ordered as it is: no tier starts at one, or
+// the product is sold in variants and the favorite has none.
+type FavoriteLine struct {
+	Favorite
+	ProductSummary
+	Price *float64 `json:"price" example:"90.25"`
 }
@@ -0,0 +1,7 @@
+package models
+
+// UploadedImage is a stored image together with its thumbnail.
+type UploadedImage struct {
+	URL          string `json:"url" example:"http://localhost:8080/uploads/images/3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b.jpg"`
+	ThumbnailURL string `json:"thumbnail_url" example:"http://localhost:8080/uploads/images/3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b_thumb.jpg"`
+}
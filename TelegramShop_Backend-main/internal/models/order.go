@@ -2,13 +2,89 @@ package models
 
 import "time"
 
+const (
+	OrderStatusPending    = "pending"
+	OrderStatusPaid       = "paid"
+	OrderStatusAssembling = "assembling"
+	OrderStatusShipped    = "shipped"
+	OrderStatusDelivered  = "delivered"
+	OrderStatusCancelled  = "cancelled"
+	OrderStatusRefunded   = "refunded"
+)
+
+// Reasons a basket line is left out of a checkout.
+const (
+	DropReasonOutOfStock      = "out_of_stock"
+	DropReasonProductNotFound = "product_not_found"
+	DropReasonVariantNotFound = "variant_not_found"
+	// DropReasonVariantRequired marks a line without a variant for a product that has variants.
+	DropReasonVariantRequired = "variant_required"
+	// DropReasonPriceTierMissing marks a line whose quantity no price tier of the product covers.
+	DropReasonPriceTierMissing = "price_tier_missing"
+)
+
 type (
 	OrderWithProducts struct {
-		ID        int64          `db:"id" json:"id"`
-		UserID    int64          `db:"user_id" json:"user_id"`
-		Status    string         `db:"status" json:"status"`
-		CreatedAt time.Time      `db:"created_at" json:"created_at"`
-		Products  []OrderProduct `json:"products"`
+		ID     int64  `db:"id" json:"id"`
+		UserID int64  `db:"user_id" json:"user_id"`
+		Status string `db:"status" json:"status"`
+		// TotalAmount is the amount to pay: the lines minus DiscountAmount.
+		TotalAmount    float64             `db:"total_amount" json:"total_amount"`
+		PromoCode      *string             `db:"promo_code" json:"promo_code,omitempty"`
+		DiscountAmount float64             `db:"discount_amount" json:"discount_amount"`
+		CancelReason   *string             `db:"cancel_reason" json:"cancel_reason,omitempty"`
+		CreatedAt      time.Time           `db:"created_at" json:"created_at"`
+		Products       []OrderProduct      `json:"products"`
+		Discounts      []OrderDiscount     `json:"discounts,omitempty"`
+		History        []OrderStatusChange `json:"history,omitempty"`
+	}
+
+	// OrderDiscount is a discount line of an order. ProductID and VariantID name the line it
+	// was taken from, or are empty for a discount on the order as a whole.
+	OrderDiscount struct {
+		ID          int    `db:"id" json:"id"`
+		OrderID     int    `db:"order_id" json:"order_id"`
+		PromoCodeID *int64 `db:"promo_code_id" json:"promo_code_id"`
+		Code        string `db:"code" json:"code" example:"SUMMER10"`
+		ProductID   *int   `db:"product_id" json:"product_id,omitempty"`
+		VariantID   *int   `db:"variant_id" json:"variant_id,omitempty"`
+		// Amount is positive and is subtracted from the order total.
+		Amount float64 `db:"amount" json:"amount"`
+	}
+
+	// OrderQuote is the basket priced as an order would be, without placing it.
+	OrderQuote struct {
+		Products       []OrderProduct      `json:"products"`
+		Subtotal       float64             `json:"subtotal"`
+		PromoCode      *string             `json:"promo_code,omitempty"`
+		Discounts      []OrderDiscount     `json:"discounts"`
+		DiscountAmount float64             `json:"discount_amount"`
+		TotalAmount    float64             `json:"total_amount"`
+		Dropped        []DroppedBasketItem `json:"dropped"`
+	}
+
+	// ApplyPromoCode carries the promo code of a checkout or a basket preview.
+	ApplyPromoCode struct {
+		PromoCode string `json:"promo_code" example:"SUMMER10"`
+	}
+
+	OrderStatusChange struct {
+		ID         int       `db:"id" json:"id"`
+		OrderID    int64     `db:"order_id" json:"order_id"`
+		FromStatus *string   `db:"from_status" json:"from_status"`
+		ToStatus   string    `db:"to_status" json:"to_status"`
+		ActorID    *int64    `db:"actor_id" json:"actor_id"`
+		Comment    string    `db:"comment" json:"comment"`
+		CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	}
+
+	UpdateOrderStatus struct {
+		Status  string `json:"status" example:"paid"`
+		Comment string `json:"comment" example:"Payment confirmed by manager"`
+	}
+
+	CancelOrder struct {
+		Reason string `json:"reason" example:"Ordered by mistake"`
 	}
 
 	Order struct {
@@ -22,15 +98,35 @@ type (
 		ID        int     `db:"id" json:"id"`
 		OrderID   int     `db:"order_id" json:"order_id"`
 		ProductID int     `db:"product_id" json:"product_id"`
+		VariantID *int    `db:"variant_id" json:"variant_id,omitempty"`
 		Quantity  int     `db:"quantity" json:"quantity"`
 		Price     float64 `db:"price" json:"price"`
 	}
 
 	CreateOrder struct {
-		UserID int64 `json:"user_id"`
-		Items  []struct {
-			ProductID int `json:"product_id"`
-			Quantity  int `json:"quantity"`
-		} `json:"items"`
+		UserID    int64             `json:"user_id"`
+		Items     []CreateOrderItem `json:"items"`
+		PromoCode string            `json:"promo_code,omitempty" example:"SUMMER10"`
+	}
+
+	CreateOrderItem struct {
+		ProductID int `json:"product_id"`
+		// VariantID is required for a product that has variants.
+		VariantID *int `json:"variant_id,omitempty"`
+		Quantity  int  `json:"quantity"`
+	}
+
+	// Checkout is an order built from the basket together with the lines that could not be ordered.
+	Checkout struct {
+		Order   OrderWithProducts   `json:"order"`
+		Dropped []DroppedBasketItem `json:"dropped"`
+	}
+
+	DroppedBasketItem struct {
+		ProductID int    `json:"product_id"`
+		VariantID *int   `json:"variant_id,omitempty"`
+		Quantity  int    `json:"quantity"`
+		Available int    `json:"available"`
+		Reason    string `json:"reason" example:"out_of_stock"`
 	}
 )
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusDone    = "done"
+	OutboxStatusDead    = "dead"
+)
+
+// Domain event types written to the outbox.
+const (
+	EventOrderCreated        = "order.created"
+	EventOrderStatusChanged  = "order.status_changed"
+	EventProductStockChanged = "product.stock_changed"
+	EventProductUpdated      = "product.updated"
+	EventPriceChanged        = "price.changed"
+	EventReviewChanged       = "review.changed"
+	// EventCatalogChanged is written when a product, firm or category is created, renamed or deleted.
+	EventCatalogChanged = "catalog.changed"
+)
+
+const (
+	CatalogEntityProduct  = "product"
+	CatalogEntityFirm     = "firm"
+	CatalogEntityCategory = "category"
+)
+
+// Actions carried by review events.
+const (
+	ReviewCreated = "created"
+	ReviewUpdated = "updated"
+	ReviewDeleted = "deleted"
+)
+
+type (
+	OutboxEvent struct {
+		ID          int64           `db:"id" json:"id"`
+		EventType   string          `db:"event_type" json:"event_type"`
+		Payload     json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+		Status      string          `db:"status" json:"status"`
+		Attempts    int             `db:"attempts" json:"attempts"`
+		LastError   string          `db:"last_error" json:"last_error"`
+		AvailableAt time.Time       `db:"available_at" json:"available_at"`
+		CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+		ProcessedAt *time.Time      `db:"processed_at" json:"processed_at"`
+	}
+
+	OrderEvent struct {
+		OrderID int64  `json:"order_id"`
+		UserID  int64  `json:"user_id"`
+		From    string `json:"from,omitempty"`
+		To      string `json:"to"`
+	}
+
+	// ProductEvent carries the stock before and after the change when the change touched it.
+	ProductEvent struct {
+		ProductID int64 `json:"product_id"`
+		PrevStock *int  `json:"prev_stock,omitempty"`
+		Stock     *int  `json:"stock,omitempty"`
+	}
+
+	PriceEvent struct {
+		ProductID int64 `json:"product_id"`
+		PriceID   int64 `json:"price_id,omitempty"`
+	}
+
+	CatalogEvent struct {
+		Entity string `json:"entity" example:"firm"`
+		ID     int64  `json:"id"`
+	}
+
+	ReviewEvent struct {
+		ProductID int64  `json:"product_id"`
+		UserID    int64  `json:"user_id"`
+		Kind      string `json:"kind" example:"mark"`
+		Action    string `json:"action" example:"created"`
+	}
+)
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type (
+	// Payment is a successful Telegram payment. TotalAmount is in the smallest units of Currency.
+	// Mismatch marks a payment whose currency or amount differs from the order's invoice; it does
+	// not mark the order as paid and needs manual review.
+	Payment struct {
+		ID                      int64     `db:"id" json:"id"`
+		OrderID                 int64     `db:"order_id" json:"order_id"`
+		Currency                string    `db:"currency" json:"currency"`
+		TotalAmount             int64     `db:"total_amount" json:"total_amount"`
+		TelegramPaymentChargeID string    `db:"telegram_payment_charge_id" json:"telegram_payment_charge_id"`
+		ProviderPaymentChargeID string    `db:"provider_payment_charge_id" json:"provider_payment_charge_id"`
+		Mismatch                bool      `db:"mismatch" json:"mismatch"`
+		CreatedAt               time.Time `db:"created_at" json:"created_at"`
+	}
+
+	InvoiceLink struct {
+		OrderID     int64  `json:"order_id"`
+		InvoiceLink string `json:"invoice_link" example:"https://t.me/$AbCdEf"`
+	}
+)
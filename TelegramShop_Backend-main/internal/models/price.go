@@ -15,3 +15,12 @@ type UpdatePriceInput struct {
 type UpdatePriceCount struct {
 	NewCount int `json:"new_count" example:"15"`
 }
+
+// LinePrice is a quantity of one product priced at its best tier.
+type LinePrice struct {
+	UnitPrice float64 `json:"unit_price" example:"80"`
+	// BasePrice is the single-item price the savings are measured against.
+	BasePrice float64 `json:"base_price" example:"90.25"`
+	LineTotal float64 `json:"line_total" example:"800"`
+	Savings   float64 `json:"savings" example:"102.5"`
+}
@@ -38,3 +38,58 @@ type CountInput struct {
 type StockInput struct {
 	Stock int `json:"stock"`
 }
+
+// Product listing sort keys.
+const (
+	ProductSortNewest     = "newest"
+	ProductSortPriceAsc   = "price_asc"
+	ProductSortPriceDesc  = "price_desc"
+	ProductSortPopularity = "popularity"
+	ProductSortRating     = "rating"
+)
+
+// ProductFilter narrows the product listing. Nil fields are not filtered on.
+// Prices are compared against the single-item tier, the one with the lowest count.
+type ProductFilter struct {
+	// CategoryID matches products of the category and of all its subcategories.
+	CategoryID *int64
+	FirmID     *int64
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    bool
+	// Attributes keeps products whose attributes have every key with the given value, compared
+	// as text the way facets list it, so "42" matches both 42 and "42".
+	Attributes map[string]string
+	Sort       string
+}
+
+// ProductFacet lists the values of one attribute key with the number of products having each.
+type ProductFacet struct {
+	Key    string       `json:"key" example:"color"`
+	Values []FacetValue `json:"values"`
+}
+
+type FacetValue struct {
+	Value string `json:"value" example:"black"`
+	Count int    `json:"count" example:"3"`
+}
+
+// ProductSearchResult is a product found by search. Snippet is an HTML fragment of the name and
+// description with matches wrapped in <mark>; fuzzy matches carry the plain name instead.
+type ProductSearchResult struct {
+	Product
+	Rank    float64 `json:"rank" example:"0.6"`
+	Snippet string  `json:"snippet" example:"<mark>Смартфон</mark> Apple"`
+	Fuzzy   bool    `json:"fuzzy"`
+}
+
+// ProductSummary is what basket and favorites lines show about their product, so the
+// WebApp does not have to request every product separately.
+type ProductSummary struct {
+	Name string `db:"name" json:"name" example:"Green tea"`
+	// Image is the first image of the product.
+	Image *string `db:"image" json:"image,omitempty" example:"https://example.com/1.jpg"`
+	// Stock is the variant's stock when the line has a variant.
+	Stock   int  `db:"stock" json:"stock" example:"5"`
+	InStock bool `db:"in_stock" json:"in_stock"`
+}
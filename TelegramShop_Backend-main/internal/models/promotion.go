@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Kinds of promo code discounts.
+const (
+	// PromoKindPercent takes Value percent off the eligible lines.
+	PromoKindPercent = "percent"
+	// PromoKindFixed takes Value off the eligible lines, at most their total.
+	PromoKindFixed = "fixed"
+	// PromoKindFreeItem makes one unit of ProductID free when the order contains it.
+	PromoKindFreeItem = "free_item"
+)
+
+// Reasons a promo code cannot be applied to an order.
+const (
+	PromoReasonNotFound        = "not_found"
+	PromoReasonInactive        = "inactive"
+	PromoReasonNotStarted      = "not_started"
+	PromoReasonExpired         = "expired"
+	PromoReasonUsageLimit      = "usage_limit_reached"
+	PromoReasonUserLimit       = "user_limit_reached"
+	PromoReasonMinOrderTotal   = "min_order_total_not_reached"
+	PromoReasonNoEligibleItems = "no_eligible_items"
+)
+
+type PromoCode struct {
+	ID    int64   `db:"id" json:"id"`
+	Code  string  `db:"code" json:"code" example:"SUMMER10"`
+	Kind  string  `db:"kind" json:"kind" example:"percent"`
+	Value float64 `db:"value" json:"value" example:"10"`
+	// ProductID is the product a free_item code gives away.
+	ProductID *int64 `db:"product_id" json:"product_id"`
+	// CategoryID and FirmID limit a percent or fixed code to products of the category subtree or the firm.
+	CategoryID     *int64     `db:"category_id" json:"category_id"`
+	FirmID         *int64     `db:"firm_id" json:"firm_id"`
+	MinOrderTotal  float64    `db:"min_order_total" json:"min_order_total"`
+	MaxUses        *int       `db:"max_uses" json:"max_uses"`
+	MaxUsesPerUser *int       `db:"max_uses_per_user" json:"max_uses_per_user"`
+	StartsAt       *time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt         *time.Time `db:"ends_at" json:"ends_at"`
+	Active         bool       `db:"active" json:"active"`
+	// Uses counts the orders placed with the code, cancelled and refunded ones excluded.
+	Uses      int       `db:"uses" json:"uses"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type PromoCodeInput struct {
+	Code           string     `json:"code" example:"SUMMER10"`
+	Kind           string     `json:"kind" example:"percent"`
+	Value          float64    `json:"value" example:"10"`
+	ProductID      *int64     `json:"product_id"`
+	CategoryID     *int64     `json:"category_id"`
+	FirmID         *int64     `json:"firm_id"`
+	MinOrderTotal  float64    `json:"min_order_total"`
+	MaxUses        *int       `json:"max_uses"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	// Active defaults to true.
+	Active *bool `json:"active"`
+}
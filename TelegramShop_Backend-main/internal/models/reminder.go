@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// IdleBasket is a basket nobody has touched since UpdatedAt, the latest change of any of its lines.
+type IdleBasket struct {
+	UserID     int64     `db:"user_id" json:"user_id"`
+	TelegramID int64     `db:"telegram_id" json:"telegram_id"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	Lines      int       `db:"lines" json:"lines"`
+	Quantity   int       `db:"quantity" json:"quantity"`
+}
+
+// BasketReminderSettings is the user's choice to get abandoned basket reminders.
+type BasketReminderSettings struct {
+	Enabled bool `json:"enabled" example:"false"`
+}
@@ -8,10 +8,10 @@ type BasketResponse struct {
 	Data   BasketItem `json:"data"`
 }
 
-// BasketListResponse represents a list of basket items response
+// BasketListResponse represents a priced basket response
 type BasketListResponse struct {
-	Status string       `json:"status" example:"success_user_basket_retrieved"`
-	Data   []BasketItem `json:"data"`
+	Status string `json:"status" example:"success_user_basket_retrieved"`
+	Data   Basket `json:"data"`
 }
 
 // FavoriteResponse represents a favorite item response
@@ -22,8 +22,8 @@ type FavoriteResponse struct {
 
 // FavoriteListResponse represents a list of favorite items response
 type FavoriteListResponse struct {
-	Status string     `json:"status" example:"success_user_favorites_retrieved"`
-	Data   []Favorite `json:"data"`
+	Status string         `json:"status" example:"success_user_favorites_retrieved"`
+	Data   []FavoriteLine `json:"data"`
 }
 
 // OrderResponse represents an order response
@@ -34,8 +34,107 @@ type OrderResponse struct {
 
 // OrderListResponse represents a list of orders response
 type OrderListResponse struct {
-	Status string              `json:"status" example:"success_all_orders_retrieved"`
-	Data   []OrderWithProducts `json:"data"`
+	Status     string              `json:"status" example:"success_all_orders_retrieved"`
+	Data       []OrderWithProducts `json:"data"`
+	NextCursor string              `json:"next_cursor,omitempty" example:"eyJpZCI6NDJ9"`
+}
+
+// InsufficientStockResponse represents an order rejected because of missing stock
+type InsufficientStockResponse struct {
+	Status string `json:"status" example:"error_insufficient_stock"`
+	Data   struct {
+		ProductIDs []int `json:"product_ids" example:"3,7"`
+	} `json:"data"`
+}
+
+// CheckoutResponse represents an order created from the basket
+type CheckoutResponse struct {
+	Status string   `json:"status" example:"success_checkout"`
+	Data   Checkout `json:"data"`
+}
+
+// OrderQuoteResponse represents a basket priced without placing an order
+type OrderQuoteResponse struct {
+	Status string     `json:"status" example:"success_basket_priced"`
+	Data   OrderQuote `json:"data"`
+}
+
+// PromoCodeErrorResponse represents an order or preview rejected because of its promo code
+type PromoCodeErrorResponse struct {
+	Status string `json:"status" example:"error_invalid_promo_code"`
+	Data   struct {
+		Code   string `json:"code" example:"SUMMER10"`
+		Reason string `json:"reason" example:"expired"`
+	} `json:"data"`
+}
+
+// PromoCodeResponse represents a promo code response
+type PromoCodeResponse struct {
+	Status string    `json:"status" example:"success_promo_code_created"`
+	Data   PromoCode `json:"data"`
+}
+
+// PromoCodeListResponse represents a list of promo codes response
+type PromoCodeListResponse struct {
+	Status string      `json:"status" example:"success_promo_codes_retrieved"`
+	Data   []PromoCode `json:"data"`
+}
+
+// BasketReminderSettingsResponse represents the user's basket reminder settings response
+type BasketReminderSettingsResponse struct {
+	Status string                 `json:"status" example:"success_basket_reminders_updated"`
+	Data   BasketReminderSettings `json:"data"`
+}
+
+// BasketQuantityErrorResponse represents a basket change rejected because the quantity exceeds
+// the stock or the per-line limit
+type BasketQuantityErrorResponse struct {
+	Status string `json:"status" example:"error_quantity_unavailable"`
+	Data   struct {
+		ProductID int  `json:"product_id" example:"3"`
+		VariantID *int `json:"variant_id,omitempty" example:"70"`
+		Quantity  int  `json:"quantity" example:"12"`
+		Stock     int  `json:"stock" example:"5"`
+		Limit     int  `json:"limit" example:"99"`
+	} `json:"data"`
+}
+
+// UnavailableBasketResponse represents a checkout rejected because no basket item can be ordered
+type UnavailableBasketResponse struct {
+	Status string `json:"status" example:"error_basket_unavailable"`
+	Data   struct {
+		Dropped []DroppedBasketItem `json:"dropped"`
+	} `json:"data"`
+}
+
+// InvoiceLinkResponse represents a created invoice link response
+type InvoiceLinkResponse struct {
+	Status string      `json:"status" example:"success_invoice_created"`
+	Data   InvoiceLink `json:"data"`
+}
+
+// OutboxEventListResponse represents a list of outbox events response
+type OutboxEventListResponse struct {
+	Status string        `json:"status" example:"success_outbox_events_retrieved"`
+	Data   []OutboxEvent `json:"data"`
+}
+
+// WebhookSubscriptionResponse represents a webhook subscription response
+type WebhookSubscriptionResponse struct {
+	Status string              `json:"status" example:"success_webhook_created"`
+	Data   WebhookSubscription `json:"data"`
+}
+
+// WebhookSubscriptionListResponse represents a list of webhook subscriptions response
+type WebhookSubscriptionListResponse struct {
+	Status string                `json:"status" example:"success_webhooks_retrieved"`
+	Data   []WebhookSubscription `json:"data"`
+}
+
+// WebhookDeliveryListResponse represents a webhook delivery log response
+type WebhookDeliveryListResponse struct {
+	Status string            `json:"status" example:"success_webhook_deliveries_retrieved"`
+	Data   []WebhookDelivery `json:"data"`
 }
 
 // UserResponse represents a user response
@@ -46,8 +145,9 @@ type UserResponse struct {
 
 // UserListResponse represents a list of users response
 type UserListResponse struct {
-	Status string `json:"status" example:"success_all_users_retrieved"`
-	Data   []User `json:"data"`
+	Status     string `json:"status" example:"success_all_users_retrieved"`
+	Data       []User `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJpZCI6NDJ9"`
 }
 
 // ProductResponse represents a product response
@@ -58,8 +158,51 @@ type ProductResponse struct {
 
 // ProductListResponse represents a list of products response
 type ProductListResponse struct {
-	Status string    `json:"status" example:"success_all_products_retrieved"`
-	Data   []Product `json:"data"`
+	Status     string    `json:"status" example:"success_all_products_retrieved"`
+	Data       []Product `json:"data"`
+	NextCursor string    `json:"next_cursor,omitempty" example:"eyJpZCI6NDJ9"`
+}
+
+// ProductSearchResponse represents product search results response
+type ProductSearchResponse struct {
+	Status string                `json:"status" example:"success_products_found"`
+	Data   []ProductSearchResult `json:"data"`
+}
+
+// SuggestionListResponse represents autocomplete suggestions response
+type SuggestionListResponse struct {
+	Status string       `json:"status" example:"success_suggestions_found"`
+	Data   []Suggestion `json:"data"`
+}
+
+// ProductFacetListResponse represents product attribute facets response
+type ProductFacetListResponse struct {
+	Status string         `json:"status" example:"success_facets_retrieved"`
+	Data   []ProductFacet `json:"data"`
+}
+
+// ProductVariantResponse represents a product variant response
+type ProductVariantResponse struct {
+	Status string         `json:"status" example:"success_variant_created"`
+	Data   ProductVariant `json:"data"`
+}
+
+// ProductVariantListResponse represents a list of product variants response
+type ProductVariantListResponse struct {
+	Status string           `json:"status" example:"success_variants_retrieved"`
+	Data   []ProductVariant `json:"data"`
+}
+
+// CatalogImportResponse represents a catalog import result response
+type CatalogImportResponse struct {
+	Status string              `json:"status" example:"success_catalog_imported"`
+	Data   CatalogImportResult `json:"data"`
+}
+
+// UploadedImageResponse represents an image upload response
+type UploadedImageResponse struct {
+	Status string        `json:"status" example:"success_product_image_uploaded"`
+	Data   UploadedImage `json:"data"`
 }
 
 // CategoryResponse represents a category response
@@ -68,6 +211,12 @@ type CategoryResponse struct {
 	Data   Category `json:"data"`
 }
 
+// CategoryTreeResponse represents a category tree response
+type CategoryTreeResponse struct {
+	Status string         `json:"status" example:"success_category_tree_retrieved"`
+	Data   []CategoryNode `json:"data"`
+}
+
 // CategoryListResponse represents a list of categories response
 type CategoryListResponse struct {
 	Status string     `json:"status" example:"success_all_categories_retrieved"`
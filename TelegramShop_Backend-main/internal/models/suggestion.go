@@ -0,0 +1,25 @@
+package models
+
+const (
+	SuggestionProduct  = "product"
+	SuggestionFirm     = "firm"
+	SuggestionCategory = "category"
+)
+
+// Suggestion is an autocomplete entry. SellCount is the units sold of the product,
+// or of all products of the firm or category.
+type Suggestion struct {
+	Type      string `json:"type" example:"product"`
+	ID        int64  `json:"id" example:"1"`
+	Name      string `json:"name" example:"iPhone 13"`
+	SellCount int    `json:"sell_count" example:"120"`
+}
+
+// ProductSales is the part of a product the suggestion index is built from.
+type ProductSales struct {
+	ID         int64  `db:"id"`
+	Name       string `db:"name"`
+	FirmID     int64  `db:"firm_id"`
+	CategoryID int64  `db:"category_id"`
+	SellCount  int    `db:"sell_count"`
+}
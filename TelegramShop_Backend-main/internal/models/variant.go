@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ProductVariant is a concrete purchasable version of a product, such as one size and color.
+// A product with variants has its stock derived from theirs.
+type ProductVariant struct {
+	ID         int64                  `db:"id" json:"id"`
+	ProductID  int64                  `db:"product_id" json:"product_id"`
+	SKU        string                 `db:"sku" json:"sku" example:"AIRMAX-42-BLK"`
+	Attributes map[string]interface{} `db:"attributes" json:"attributes" swaggertype:"object"`
+	Stock      int                    `db:"stock" json:"stock"`
+	// Price overrides the product's price tiers when set.
+	Price     *float64  `db:"price" json:"price"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type ProductVariantInput struct {
+	SKU        string                 `json:"sku" example:"AIRMAX-42-BLK"`
+	Attributes map[string]interface{} `json:"attributes" swaggertype:"object"`
+	Stock      int                    `json:"stock"`
+	Price      *float64               `json:"price"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Event types a webhook subscription can listen to. Order events are forwarded from the outbox
+// as they are, the other two are derived from product.stock_changed and review.changed.
+const (
+	WebhookOrderCreated       = EventOrderCreated
+	WebhookOrderStatusChanged = EventOrderStatusChanged
+	WebhookProductStockLow    = "product.stock_low"
+	WebhookReviewCreated      = "review.created"
+)
+
+var WebhookEventTypes = []string{
+	WebhookOrderCreated,
+	WebhookOrderStatusChanged,
+	WebhookProductStockLow,
+	WebhookReviewCreated,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type (
+	// WebhookSubscription is an external endpoint receiving shop events. The secret is write-only.
+	WebhookSubscription struct {
+		ID         int64          `db:"id" json:"id"`
+		URL        string         `db:"url" json:"url" example:"https://crm.example.com/hooks/shop"`
+		Secret     string         `db:"secret" json:"-"`
+		EventTypes pq.StringArray `db:"event_types" json:"event_types" swaggertype:"array,string" example:"order.created,order.status_changed"`
+		Active     bool           `db:"active" json:"active"`
+		CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	}
+
+	CreateWebhookSubscription struct {
+		URL        string   `json:"url" example:"https://crm.example.com/hooks/shop"`
+		Secret     string   `json:"secret" example:"s3cr3t"`
+		EventTypes []string `json:"event_types" example:"order.created,order.status_changed"`
+	}
+
+	// UpdateWebhookSubscription changes only the fields that are set.
+	UpdateWebhookSubscription struct {
+		URL        *string  `json:"url,omitempty" example:"https://crm.example.com/hooks/shop"`
+		Secret     *string  `json:"secret,omitempty" example:"n3w-s3cr3t"`
+		EventTypes []string `json:"event_types,omitempty" example:"product.stock_low"`
+		Active     *bool    `json:"active,omitempty" example:"false"`
+	}
+
+	// WebhookDelivery is one event sent to one subscription, together with the outcome of its last attempt.
+	WebhookDelivery struct {
+		ID             int64           `db:"id" json:"id"`
+		SubscriptionID int64           `db:"subscription_id" json:"subscription_id"`
+		EventID        int64           `db:"event_id" json:"event_id"`
+		EventType      string          `db:"event_type" json:"event_type"`
+		Payload        json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+		Status         string          `db:"status" json:"status"`
+		Attempts       int             `db:"attempts" json:"attempts"`
+		ResponseStatus *int            `db:"response_status" json:"response_status"`
+		LastError      string          `db:"last_error" json:"last_error"`
+		NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+		CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+		DeliveredAt    *time.Time      `db:"delivered_at" json:"delivered_at"`
+	}
+
+	StockLowEvent struct {
+		ProductID int64 `json:"product_id"`
+		Stock     int   `json:"stock"`
+		Threshold int   `json:"threshold"`
+	}
+)
@@ -0,0 +1,55 @@
+// Package pricing holds the tier pricing rule. It depends on models only, so the repositories
+// that price orders and the services that price baskets share it without crossing layers.
+package pricing
+
+import (
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/pkg/money"
+)
+
+// BestTier returns the tier with the largest count that does not exceed quantity.
+func BestTier(tiers []models.Price, quantity int) (models.Price, bool) {
+	best := -1
+	for i, tier := range tiers {
+		if tier.Count <= quantity && (best < 0 || tier.Count > tiers[best].Count) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return models.Price{}, false
+	}
+	return tiers[best], true
+}
+
+// Quote prices quantity units of a product. A variant price replaces the tiers; otherwise the
+// best tier applies and the savings are measured against the lowest-count tier.
+// ok is false when no tier covers the quantity.
+func Quote(tiers []models.Price, variantPrice *float64, quantity int) (models.LinePrice, bool) {
+	if variantPrice != nil {
+		return linePrice(*variantPrice, *variantPrice, quantity), true
+	}
+
+	tier, ok := BestTier(tiers, quantity)
+	if !ok {
+		return models.LinePrice{}, false
+	}
+	base := tier
+	for _, t := range tiers {
+		if t.Count < base.Count {
+			base = t
+		}
+	}
+	return linePrice(tier.Price, base.Price, quantity), true
+}
+
+func linePrice(unit, base float64, quantity int) models.LinePrice {
+	line := models.LinePrice{
+		UnitPrice: unit,
+		BasePrice: base,
+		LineTotal: money.Round(unit * float64(quantity)),
+	}
+	if base > unit {
+		line.Savings = money.Round((base - unit) * float64(quantity))
+	}
+	return line
+}
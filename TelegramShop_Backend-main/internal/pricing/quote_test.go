@@ -0,0 +1,41 @@
+package pricing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/pricing"
+)
+
+func TestQuote(t *testing.T) {
+	tiers := []models.Price{
+		{Count: 10, Price: 80},
+		{Count: 1, Price: 90.25},
+		{Count: 50, Price: 70},
+	}
+
+	line, ok := pricing.Quote(tiers, nil, 3)
+	require.True(t, ok)
+	require.Equal(t, models.LinePrice{UnitPrice: 90.25, BasePrice: 90.25, LineTotal: 270.75}, line)
+
+	line, ok = pricing.Quote(tiers, nil, 10)
+	require.True(t, ok)
+	require.Equal(t, 80.0, line.UnitPrice)
+	require.Equal(t, 800.0, line.LineTotal)
+	require.Equal(t, 102.5, line.Savings)
+
+	line, _ = pricing.Quote(tiers, nil, 120)
+	require.Equal(t, 70.0, line.UnitPrice)
+
+	variantPrice := 120.0
+	line, ok = pricing.Quote(tiers, &variantPrice, 10)
+	require.True(t, ok)
+	require.Equal(t, models.LinePrice{UnitPrice: 120, BasePrice: 120, LineTotal: 1200}, line)
+
+	_, ok = pricing.Quote(tiers[:1], nil, 3)
+	require.False(t, ok)
+	_, ok = pricing.Quote(nil, nil, 1)
+	require.False(t, ok)
+}
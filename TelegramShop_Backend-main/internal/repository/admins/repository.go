@@ -0,0 +1,65 @@
+package admins
+
+import (
+	"context"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+type Repository interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+	AddAdmin(ctx context.Context, userID int64) error
+	RemoveAdmin(ctx context.Context, userID int64) error
+	GetAll(ctx context.Context) ([]models.User, error)
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM admins WHERE user_id = $1)`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *repository) AddAdmin(ctx context.Context, userID int64) error {
+	query := `
+		INSERT INTO admins (user_id)
+		VALUES ($1)
+		ON CONFLICT (user_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (r *repository) RemoveAdmin(ctx context.Context, userID int64) error {
+	query := `DELETE FROM admins WHERE user_id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (r *repository) GetAll(ctx context.Context) ([]models.User, error) {
+	query := `
+		SELECT u.id, u.telegram_id, u.username, u.created_at
+		FROM admins a
+		JOIN users u ON u.id = a.user_id
+		ORDER BY u.id`
+
+	var users []models.User
+	err := r.db.SelectContext(ctx, &users, query)
+	return users, err
+}
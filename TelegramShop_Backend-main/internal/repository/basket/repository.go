@@ -2,6 +2,10 @@ package basket
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/pkg/logger"
 
@@ -9,14 +13,42 @@ import (
 	_ "github.com/lib/pq"
 )
 
+var (
+	ErrInvalidQuantity = errors.New("invalid basket quantity")
+	ErrProductNotFound = errors.New("product not found")
+	ErrVariantNotFound = errors.New("product variant not found")
+	ErrVariantRequired = errors.New("product has variants, variant_id is required")
+)
+
+// QuantityError reports a basket line whose quantity exceeds the stock or the per-line limit.
+type QuantityError struct {
+	ProductID int  `json:"product_id"`
+	VariantID *int `json:"variant_id,omitempty"`
+	Quantity  int  `json:"quantity"`
+	Stock     int  `json:"stock"`
+	Limit     int  `json:"limit"`
+}
+
+func (e *QuantityError) Error() string {
+	return fmt.Sprintf("quantity %d of product %d exceeds stock %d or limit %d", e.Quantity, e.ProductID, e.Stock, e.Limit)
+}
+
+// Repository keeps one line per user, product and variant. Every write checks the resulting
+// quantity against the stock and against limit, and fails with *QuantityError when it is exceeded.
 type Repository interface {
-	UpdateBasketItemQuantity(ctx context.Context, itemID int, quantity int) error
-	RemoveFromBasket(ctx context.Context, itemID int) error
-	GetUserBasket(ctx context.Context, userID int64) ([]models.BasketItem, error)
+	// GetUserBasket returns the basket lines with their product details in one query.
+	GetUserBasket(ctx context.Context, userID int64) ([]models.BasketLine, error)
 	ClearUserBasket(ctx context.Context, userID int64) error
-	CreateBasketItem(ctx context.Context, input models.CreateBasketItem) error
+	// AddBasketItem adds the positive quantity to the line, creating it when missing.
+	AddBasketItem(ctx context.Context, input models.CreateBasketItem, limit int) (models.BasketItem, error)
+	// SetBasketItem sets the quantity of the line, creating it when missing; quantity 0 removes it.
+	SetBasketItem(ctx context.Context, input models.CreateBasketItem, limit int) (models.BasketItem, error)
+	// ReplaceBasket replaces the whole basket of the user in one transaction. Lines of the
+	// same product and variant are merged and lines with quantity 0 are left out.
+	ReplaceBasket(ctx context.Context, userID int64, items []models.CreateBasketItem, limit int) error
+	// DeleteBasketItem removes the line of the variant, or every line of the product when
+	// VariantID is nil.
 	DeleteBasketItem(ctx context.Context, input models.DeleteBasketItem) error
-	UpdateBasketItem(ctx context.Context, input models.CreateBasketItem) error
 }
 
 type repository struct {
@@ -27,88 +59,282 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) UpdateBasketItemQuantity(ctx context.Context, itemID int, quantity int) error {
-
-	query := `UPDATE basket SET quantity = $1 WHERE id = $2`
+func (r *repository) GetUserBasket(ctx context.Context, userID int64) ([]models.BasketLine, error) {
+	query := `
+		SELECT b.user_id, b.product_id, b.variant_id, b.quantity, b.added_at,
+			p.name, p.image[1] AS image,
+			COALESCE(v.stock, p.stock) AS stock,
+			COALESCE(v.stock, p.stock) > 0 AS in_stock
+		FROM basket b
+		JOIN products p ON p.id = b.product_id
+		LEFT JOIN product_variants v ON v.id = b.variant_id
+		WHERE b.user_id = $1
+		ORDER BY b.id
+	`
 
-	_, err := r.db.ExecContext(ctx, query, quantity, itemID)
+	lines := []models.BasketLine{}
+	err := r.db.SelectContext(ctx, &lines, query, userID)
+	if err != nil {
+		logger.Errorf("[GetUserBasket] Error getting basket items: %v", err)
+		return nil, err
+	}
 
-	return err
+	return lines, nil
 }
 
-func (r *repository) RemoveFromBasket(ctx context.Context, itemID int) error {
-
-	query := `DELETE FROM basket WHERE id = $1`
-
-	_, err := r.db.ExecContext(ctx, query, itemID)
+func (r *repository) ClearUserBasket(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, clearUserBasketQuery, userID)
 
 	return err
 }
 
-func (r *repository) GetUserBasket(ctx context.Context, userID int64) ([]models.BasketItem, error) {
+const clearUserBasketQuery = `DELETE FROM basket WHERE user_id = $1`
+
+// LockUserBasket returns the user's basket rows and locks them until the end of tx,
+// so a concurrent basket update cannot slip in between reading and clearing the basket.
+func LockUserBasket(ctx context.Context, tx *sqlx.Tx, userID int64) ([]models.BasketItem, error) {
 	query := `
-		SELECT user_id, product_id, quantity
+		SELECT user_id, product_id, variant_id, quantity, added_at
 		FROM basket
-		WHERE user_id = $1
+		WHERE user_id = $1 AND product_id IS NOT NULL
+		ORDER BY id
+		FOR UPDATE
 	`
 
 	var items []models.BasketItem
-	err := r.db.SelectContext(ctx, &items, query, userID)
+	err := tx.SelectContext(ctx, &items, query, userID)
 	if err != nil {
-		logger.Errorf("[GetUserBasket] Error getting basket items: %v", err)
+		logger.Errorf("[LockUserBasket] Error locking basket items: %v", err)
 		return nil, err
 	}
 
 	return items, nil
 }
 
-func (r *repository) ClearUserBasket(ctx context.Context, userID int64) error {
-	query := `DELETE FROM basket WHERE user_id = $1`
-
-	_, err := r.db.ExecContext(ctx, query, userID)
+// ClearUserBasketTx removes every basket row of the user inside tx.
+func ClearUserBasketTx(ctx context.Context, tx *sqlx.Tx, userID int64) error {
+	_, err := tx.ExecContext(ctx, clearUserBasketQuery, userID)
 
 	return err
 }
 
-func (r *repository) CreateBasketItem(ctx context.Context, input models.CreateBasketItem) error {
-	query := `
-		INSERT INTO basket (user_id, product_id, quantity)
-		VALUES ($1, $2, $3)
-	`
+// upsertQuery writes a line; $5 tells whether the quantity is added to an existing line or replaces it.
+const upsertQuery = `
+	INSERT INTO basket (user_id, product_id, variant_id, quantity)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id, product_id, COALESCE(variant_id, 0))
+	DO UPDATE SET quantity = CASE WHEN $5 THEN basket.quantity + EXCLUDED.quantity ELSE EXCLUDED.quantity END,
+		updated_at = current_timestamp
+	RETURNING user_id, product_id, variant_id, quantity, added_at
+`
+
+func (r *repository) AddBasketItem(ctx context.Context, input models.CreateBasketItem, limit int) (models.BasketItem, error) {
+	if input.Quantity <= 0 {
+		return models.BasketItem{}, ErrInvalidQuantity
+	}
+
+	item, err := r.addBasketItem(ctx, input, limit)
+	if err != nil {
+		logger.Errorf("[AddBasketItem] Error adding basket item: %v", err)
+		return models.BasketItem{}, err
+	}
+
+	return item, nil
+}
+
+func (r *repository) addBasketItem(ctx context.Context, input models.CreateBasketItem, limit int) (models.BasketItem, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.BasketItem{}, err
+	}
+	defer tx.Rollback()
+
+	stock, err := lineStock(ctx, tx, input.ProductID, input.VariantID)
+	if err != nil {
+		return models.BasketItem{}, err
+	}
+	var item models.BasketItem
+	if err := tx.GetContext(ctx, &item, upsertQuery, input.UserID, input.ProductID, input.VariantID, input.Quantity, true); err != nil {
+		return models.BasketItem{}, err
+	}
+	if err := checkQuantity(item, stock, limit); err != nil {
+		return models.BasketItem{}, err
+	}
+
+	return item, tx.Commit()
+}
+
+func (r *repository) SetBasketItem(ctx context.Context, input models.CreateBasketItem, limit int) (models.BasketItem, error) {
+	if input.Quantity < 0 {
+		return models.BasketItem{}, ErrInvalidQuantity
+	}
+	if input.Quantity == 0 {
+		err := r.DeleteBasketItem(ctx, models.DeleteBasketItem{UserID: input.UserID, ProductID: input.ProductID, VariantID: input.VariantID})
+		return models.BasketItem{UserID: input.UserID, ProductID: input.ProductID, VariantID: input.VariantID}, err
+	}
+
+	item, err := r.setBasketItem(ctx, input, limit)
+	if err != nil {
+		logger.Errorf("[SetBasketItem] Error setting basket item: %v", err)
+		return models.BasketItem{}, err
+	}
+
+	return item, nil
+}
+
+func (r *repository) setBasketItem(ctx context.Context, input models.CreateBasketItem, limit int) (models.BasketItem, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.BasketItem{}, err
+	}
+	defer tx.Rollback()
 
-	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID, input.Quantity)
+	stock, err := lineStock(ctx, tx, input.ProductID, input.VariantID)
 	if err != nil {
-		logger.Errorf("[CreateBasketItem] Error creating basket item: %v", err)
+		return models.BasketItem{}, err
+	}
+	if err := checkQuantity(models.BasketItem{ProductID: input.ProductID, VariantID: input.VariantID, Quantity: input.Quantity}, stock, limit); err != nil {
+		return models.BasketItem{}, err
+	}
+	var item models.BasketItem
+	if err := tx.GetContext(ctx, &item, upsertQuery, input.UserID, input.ProductID, input.VariantID, input.Quantity, false); err != nil {
+		return models.BasketItem{}, err
+	}
+
+	return item, tx.Commit()
+}
+
+func (r *repository) ReplaceBasket(ctx context.Context, userID int64, items []models.CreateBasketItem, limit int) error {
+	type lineKey struct{ productID, variantID int }
+
+	merged := make([]models.CreateBasketItem, 0, len(items))
+	index := make(map[lineKey]int, len(items))
+	for _, item := range items {
+		if item.Quantity < 0 {
+			return fmt.Errorf("%w: product %d", ErrInvalidQuantity, item.ProductID)
+		}
+		if item.Quantity == 0 {
+			continue
+		}
+		key := lineKey{productID: item.ProductID}
+		if item.VariantID != nil {
+			key.variantID = *item.VariantID
+		}
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[key] = len(merged)
+		item.UserID = userID
+		merged = append(merged, item)
+	}
+
+	if err := r.replaceBasket(ctx, userID, merged, limit); err != nil {
+		logger.Errorf("[ReplaceBasket] Error replacing basket: %v", err)
 		return err
 	}
 
 	return nil
 }
 
-func (r *repository) DeleteBasketItem(ctx context.Context, input models.DeleteBasketItem) error {
+func (r *repository) replaceBasket(ctx context.Context, userID int64, items []models.CreateBasketItem, limit int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ClearUserBasketTx(ctx, tx, userID); err != nil {
+		return err
+	}
+	for _, item := range items {
+		stock, err := lineStock(ctx, tx, item.ProductID, item.VariantID)
+		if err != nil {
+			return err
+		}
+		line := models.BasketItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity}
+		if err := checkQuantity(line, stock, limit); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, upsertQuery, userID, item.ProductID, item.VariantID, item.Quantity, false); err != nil {
+			return err
+		}
+	}
 
+	return tx.Commit()
+}
+
+func (r *repository) DeleteBasketItem(ctx context.Context, input models.DeleteBasketItem) error {
 	query := `
 		DELETE FROM basket
-		WHERE user_id = $1 AND product_id = $2
+		WHERE user_id = $1 AND product_id = $2 AND ($3::integer IS NULL OR variant_id = $3)
 	`
 
-	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return err
+	res, err := tx.ExecContext(ctx, query, input.UserID, input.ProductID, input.VariantID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	// Removing a line is activity too, so the remaining lines are touched and the basket is not idle.
+	_, err = tx.ExecContext(ctx, `UPDATE basket SET updated_at = current_timestamp WHERE user_id = $1`, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *repository) UpdateBasketItem(ctx context.Context, input models.CreateBasketItem) error {
+// lineStock returns the stock a line of the product and variant draws from.
+func lineStock(ctx context.Context, tx *sqlx.Tx, productID int, variantID *int) (int, error) {
+	var product struct {
+		Stock       int  `db:"stock"`
+		HasVariants bool `db:"has_variants"`
+	}
 	query := `
-		UPDATE basket
-		SET quantity = $3
-		WHERE user_id = $1 AND product_id = $2
-	`
-
-	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID, input.Quantity)
+		SELECT COALESCE(p.stock, 0) AS stock,
+			EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id) AS has_variants
+		FROM products p
+		WHERE p.id = $1`
+	err := tx.GetContext(ctx, &product, query, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: product %d", ErrProductNotFound, productID)
+	}
 	if err != nil {
-		logger.Errorf("[UpdateBasketItem] Error updating basket item: %v", err)
-		return err
+		return 0, err
 	}
 
+	if variantID == nil {
+		if product.HasVariants {
+			return 0, fmt.Errorf("%w: product %d", ErrVariantRequired, productID)
+		}
+		return product.Stock, nil
+	}
+
+	var stock int
+	err = tx.GetContext(ctx, &stock, `SELECT stock FROM product_variants WHERE id = $1 AND product_id = $2`, *variantID, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: variant %d of product %d", ErrVariantNotFound, *variantID, productID)
+	}
+	return stock, err
+}
+
+func checkQuantity(item models.BasketItem, stock, limit int) error {
+	if item.Quantity > stock || item.Quantity > limit {
+		return &QuantityError{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Stock:     stock,
+			Limit:     limit,
+		}
+	}
 	return nil
 }
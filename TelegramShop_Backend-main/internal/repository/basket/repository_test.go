@@ -0,0 +1,255 @@
+package basket_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/repository/products"
+)
+
+const limit = 10
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func createUser(t *testing.T, db *sqlx.DB) int64 {
+	suffix := time.Now().UnixNano()
+	var id int64
+	err := db.Get(&id, `INSERT INTO users (telegram_id, username) VALUES ($1, $2) RETURNING id`, suffix, fmt.Sprintf("basket_test_%d", suffix))
+	require.NoError(t, err)
+	return id
+}
+
+func createProduct(t *testing.T, db *sqlx.DB, stock int) int {
+	product, err := products.NewRepository(db).CreateProduct(context.Background(), models.Product{
+		Name:        "Basket Test Product",
+		Description: "Basket test",
+		Stock:       stock,
+		Image:       []string{"https://example.com/basket.jpg"},
+	})
+	require.NoError(t, err)
+	return int(product.ID)
+}
+
+func createVariant(t *testing.T, db *sqlx.DB, productID int, stock int) int {
+	variant, err := products.NewRepository(db).CreateVariant(context.Background(), int64(productID), models.ProductVariantInput{
+		SKU:   fmt.Sprintf("BASKET-%d", time.Now().UnixNano()),
+		Stock: stock,
+	})
+	require.NoError(t, err)
+	return int(variant.ID)
+}
+
+func quantities(t *testing.T, repo basket.Repository, userID int64) map[string]int {
+	lines, err := repo.GetUserBasket(context.Background(), userID)
+	require.NoError(t, err)
+	result := make(map[string]int, len(lines))
+	for _, line := range lines {
+		key := fmt.Sprint(line.ProductID)
+		if line.VariantID != nil {
+			key += fmt.Sprintf("/%d", *line.VariantID)
+		}
+		result[key] = line.Quantity
+	}
+	return result
+}
+
+func TestBasketRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := basket.NewRepository(db)
+	ctx := context.Background()
+
+	t.Run("AddIncrementsExistingLine", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 8)
+
+		item, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 2}, limit)
+		require.NoError(t, err)
+		require.Equal(t, 2, item.Quantity)
+
+		item, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 3}, limit)
+		require.NoError(t, err)
+		require.Equal(t, 5, item.Quantity)
+
+		lines, err := repo.GetUserBasket(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		require.Equal(t, 5, lines[0].Quantity)
+		require.Equal(t, "Basket Test Product", lines[0].Name)
+		require.Equal(t, "https://example.com/basket.jpg", *lines[0].Image)
+		require.Equal(t, 8, lines[0].Stock)
+		require.True(t, lines[0].InStock)
+		require.False(t, lines[0].AddedAt.IsZero())
+	})
+
+	t.Run("AddBeyondStockKeepsQuantity", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 4)
+
+		_, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 3}, limit)
+		require.NoError(t, err)
+
+		_, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 2}, limit)
+		var quantityErr *basket.QuantityError
+		require.ErrorAs(t, err, &quantityErr)
+		require.Equal(t, 5, quantityErr.Quantity)
+		require.Equal(t, 4, quantityErr.Stock)
+		require.Equal(t, map[string]int{fmt.Sprint(productID): 3}, quantities(t, repo, userID))
+	})
+
+	t.Run("AddBeyondLimit", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 100)
+
+		_, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: limit + 1}, limit)
+		var quantityErr *basket.QuantityError
+		require.ErrorAs(t, err, &quantityErr)
+		require.Equal(t, limit, quantityErr.Limit)
+		require.Empty(t, quantities(t, repo, userID))
+	})
+
+	t.Run("AddInvalidQuantity", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 5)
+
+		for _, quantity := range []int{0, -1} {
+			_, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: quantity}, limit)
+			require.ErrorIs(t, err, basket.ErrInvalidQuantity)
+		}
+	})
+
+	t.Run("UnknownProductAndVariant", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 0)
+		variantID := createVariant(t, db, productID, 5)
+		otherVariant := createVariant(t, db, createProduct(t, db, 0), 5)
+
+		_, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: -1, Quantity: 1}, limit)
+		require.ErrorIs(t, err, basket.ErrProductNotFound)
+
+		_, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 1}, limit)
+		require.ErrorIs(t, err, basket.ErrVariantRequired)
+
+		_, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, VariantID: &otherVariant, Quantity: 1}, limit)
+		require.ErrorIs(t, err, basket.ErrVariantNotFound)
+
+		_, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, VariantID: &variantID, Quantity: 1}, limit)
+		require.NoError(t, err)
+	})
+
+	t.Run("VariantsAreSeparateLines", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 0)
+		first := createVariant(t, db, productID, 5)
+		second := createVariant(t, db, productID, 1)
+
+		_, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, VariantID: &first, Quantity: 4}, limit)
+		require.NoError(t, err)
+		_, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, VariantID: &second, Quantity: 1}, limit)
+		require.NoError(t, err)
+
+		_, err = repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: productID, VariantID: &second, Quantity: 1}, limit)
+		var quantityErr *basket.QuantityError
+		require.ErrorAs(t, err, &quantityErr)
+		require.Equal(t, 1, quantityErr.Stock, "a variant line is checked against the variant stock")
+
+		require.Equal(t, map[string]int{
+			fmt.Sprintf("%d/%d", productID, first):  4,
+			fmt.Sprintf("%d/%d", productID, second): 1,
+		}, quantities(t, repo, userID))
+
+		require.NoError(t, repo.DeleteBasketItem(ctx, models.DeleteBasketItem{UserID: userID, ProductID: productID, VariantID: &first}))
+		require.Equal(t, map[string]int{fmt.Sprintf("%d/%d", productID, second): 1}, quantities(t, repo, userID))
+	})
+
+	t.Run("SetQuantity", func(t *testing.T) {
+		userID := createUser(t, db)
+		productID := createProduct(t, db, 6)
+		input := models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 4}
+
+		item, err := repo.SetBasketItem(ctx, input, limit)
+		require.NoError(t, err)
+		require.Equal(t, 4, item.Quantity)
+
+		input.Quantity = 2
+		item, err = repo.SetBasketItem(ctx, input, limit)
+		require.NoError(t, err)
+		require.Equal(t, 2, item.Quantity)
+
+		input.Quantity = 7
+		var quantityErr *basket.QuantityError
+		_, err = repo.SetBasketItem(ctx, input, limit)
+		require.ErrorAs(t, err, &quantityErr)
+
+		input.Quantity = -1
+		_, err = repo.SetBasketItem(ctx, input, limit)
+		require.ErrorIs(t, err, basket.ErrInvalidQuantity)
+		require.Equal(t, map[string]int{fmt.Sprint(productID): 2}, quantities(t, repo, userID))
+
+		input.Quantity = 0
+		_, err = repo.SetBasketItem(ctx, input, limit)
+		require.NoError(t, err)
+		require.Empty(t, quantities(t, repo, userID))
+	})
+
+	t.Run("RemoveOnlyTouchesOwnLine", func(t *testing.T) {
+		userID := createUser(t, db)
+		otherUserID := createUser(t, db)
+		productID := createProduct(t, db, 5)
+		otherProductID := createProduct(t, db, 5)
+
+		for _, item := range []models.CreateBasketItem{
+			{UserID: userID, ProductID: productID, Quantity: 1},
+			{UserID: userID, ProductID: otherProductID, Quantity: 1},
+			{UserID: otherUserID, ProductID: productID, Quantity: 1},
+		} {
+			_, err := repo.AddBasketItem(ctx, item, limit)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, repo.DeleteBasketItem(ctx, models.DeleteBasketItem{UserID: userID, ProductID: productID}))
+		require.Equal(t, map[string]int{fmt.Sprint(otherProductID): 1}, quantities(t, repo, userID))
+		require.Equal(t, map[string]int{fmt.Sprint(productID): 1}, quantities(t, repo, otherUserID))
+	})
+
+	t.Run("ReplaceBasket", func(t *testing.T) {
+		userID := createUser(t, db)
+		oldProduct := createProduct(t, db, 5)
+		productID := createProduct(t, db, 5)
+		otherProductID := createProduct(t, db, 5)
+
+		_, err := repo.AddBasketItem(ctx, models.CreateBasketItem{UserID: userID, ProductID: oldProduct, Quantity: 1}, limit)
+		require.NoError(t, err)
+
+		err = repo.ReplaceBasket(ctx, userID, []models.CreateBasketItem{
+			{ProductID: productID, Quantity: 2},
+			{ProductID: otherProductID, Quantity: 0},
+			{ProductID: productID, Quantity: 1},
+		}, limit)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{fmt.Sprint(productID): 3}, quantities(t, repo, userID))
+
+		err = repo.ReplaceBasket(ctx, userID, []models.CreateBasketItem{
+			{ProductID: otherProductID, Quantity: 1},
+			{ProductID: productID, Quantity: 6},
+		}, limit)
+		var quantityErr *basket.QuantityError
+		require.ErrorAs(t, err, &quantityErr)
+		require.Equal(t, productID, quantityErr.ProductID)
+		require.Equal(t, map[string]int{fmt.Sprint(productID): 3}, quantities(t, repo, userID), "a failed replace leaves the basket unchanged")
+
+		require.NoError(t, repo.ReplaceBasket(ctx, userID, nil, limit))
+		require.Empty(t, quantities(t, repo, userID))
+	})
+}
@@ -0,0 +1,250 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/products"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrProductNotFound is returned by Import when an item references a product that was deleted meanwhile.
+var ErrProductNotFound = errors.New("product not found")
+
+type Repository interface {
+	// ExistingProductIDs returns which of ids belong to existing products.
+	ExistingProductIDs(ctx context.Context, ids []int64) (map[int64]bool, error)
+	// Import creates and updates the products of items in one transaction, creating missing
+	// firms and categories. Nil Description, Stock and Attributes and empty Images and Prices
+	// leave the stored values untouched. dropped lists the images the updated products lost.
+	Import(ctx context.Context, items []models.CatalogItem) (created, updated int, dropped []string, err error)
+	// Export calls fn for every product in id order, stopping at the first error.
+	Export(ctx context.Context, fn func(models.CatalogItem) error) error
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) ExistingProductIDs(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	var found []int64
+	if err := r.db.SelectContext(ctx, &found, `SELECT id FROM products WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[int64]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+func (r *repository) Import(ctx context.Context, items []models.CatalogItem) (created, updated int, dropped []string, err error) {
+	err = outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		created, updated, dropped = 0, 0, nil
+		names := &nameResolver{tx: tx, firms: map[string]int64{}, categories: map[string]int64{}}
+		ids := make([]int64, 0, len(items))
+
+		for _, item := range items {
+			firmID, err := names.resolve(ctx, "firms", names.firms, item.Firm, models.CatalogEntityFirm)
+			if err != nil {
+				return err
+			}
+			categoryID, err := names.resolve(ctx, "categories", names.categories, item.Category, models.CatalogEntityCategory)
+			if err != nil {
+				return err
+			}
+
+			id, replaced, err := upsertProduct(ctx, tx, item, firmID, categoryID)
+			if err != nil {
+				return err
+			}
+			dropped = append(dropped, replaced...)
+			if item.ID == 0 {
+				created++
+			} else {
+				updated++
+			}
+			ids = append(ids, id)
+
+			if len(item.Prices) > 0 {
+				if err := replacePrices(ctx, tx, id, item.Prices); err != nil {
+					return err
+				}
+			}
+		}
+
+		return products.RefreshSearchVectors(ctx, tx, "p.id = ANY($1)", pq.Array(ids))
+	})
+	return created, updated, dropped, err
+}
+
+// upsertProduct stores the item and publishes the change. It returns the product id and the
+// images an update replaced that the item does not list. The stock of a product with
+// variants is derived from them, so the imported value is ignored for such products.
+func upsertProduct(ctx context.Context, tx *sqlx.Tx, item models.CatalogItem, firmID, categoryID int64) (int64, []string, error) {
+	var attrs interface{}
+	if item.Attributes != nil {
+		data, err := json.Marshal(item.Attributes)
+		if err != nil {
+			return 0, nil, err
+		}
+		attrs = string(data)
+	}
+
+	if item.ID == 0 {
+		query := `
+			INSERT INTO products (name, description, firm_id, category_id, stock, attributes, image)
+			VALUES ($1, COALESCE($2, ''), $3, $4, COALESCE($5, 0), COALESCE($6::jsonb, '{}'), $7)
+			RETURNING id`
+
+		var id int64
+		err := tx.QueryRowContext(ctx, query,
+			item.Name, item.Description, firmID, categoryID, item.Stock, attrs, pq.StringArray(item.Images),
+		).Scan(&id)
+		if err != nil {
+			return 0, nil, err
+		}
+		return id, nil, outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityProduct, id)
+	}
+
+	query := `
+		UPDATE products
+		SET name = $1,
+			description = COALESCE($2, description),
+			firm_id = $3,
+			category_id = $4,
+			stock = CASE WHEN $5::integer IS NULL OR EXISTS (SELECT 1 FROM product_variants WHERE product_id = $8) THEN stock ELSE $5 END,
+			attributes = COALESCE($6::jsonb, attributes),
+			image = CASE WHEN cardinality($7::text[]) > 0 THEN $7 ELSE image END
+		WHERE id = $8
+		RETURNING stock`
+
+	prevStock, err := products.LockStock(ctx, tx, item.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, ErrProductNotFound
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	var dropped []string
+	if len(item.Images) > 0 {
+		var before pq.StringArray
+		if err := tx.GetContext(ctx, &before, `SELECT COALESCE(image, '{}') FROM products WHERE id = $1`, item.ID); err != nil {
+			return 0, nil, err
+		}
+		for _, image := range before {
+			if !slices.Contains(item.Images, image) {
+				dropped = append(dropped, image)
+			}
+		}
+	}
+
+	var stock int
+	err = tx.QueryRowContext(ctx, query,
+		item.Name, item.Description, firmID, categoryID, item.Stock, attrs, pq.StringArray(item.Images), item.ID,
+	).Scan(&stock)
+	if err != nil {
+		return 0, nil, err
+	}
+	return item.ID, dropped, outbox.Insert(ctx, tx, models.EventProductUpdated, models.ProductEvent{ProductID: item.ID, PrevStock: &prevStock, Stock: &stock})
+}
+
+func replacePrices(ctx context.Context, tx *sqlx.Tx, productID int64, prices []models.CatalogPrice) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM prices WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	for _, price := range prices {
+		_, err := tx.ExecContext(ctx, `INSERT INTO prices (product_id, count, price) VALUES ($1, $2, $3)`, productID, price.Count, price.Price)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nameResolver maps firm and category names to ids within one import, creating missing rows.
+type nameResolver struct {
+	tx         *sqlx.Tx
+	firms      map[string]int64
+	categories map[string]int64
+}
+
+// resolve returns the id of the row of table named name. table is a trusted constant.
+func (n *nameResolver) resolve(ctx context.Context, table string, cache map[string]int64, name, entity string) (int64, error) {
+	if id, ok := cache[name]; ok {
+		return id, nil
+	}
+
+	var id int64
+	err := n.tx.GetContext(ctx, &id, `INSERT INTO `+table+` (name) VALUES ($1) ON CONFLICT (name) DO NOTHING RETURNING id`, name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = n.tx.GetContext(ctx, &id, `SELECT id FROM `+table+` WHERE name = $1`, name)
+	case err == nil:
+		err = outbox.InsertCatalogEvent(ctx, n.tx, entity, id)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cache[name] = id
+	return id, nil
+}
+
+func (r *repository) Export(ctx context.Context, fn func(models.CatalogItem) error) error {
+	query := `
+		SELECT p.id, p.name, p.description, COALESCE(f.name, ''), COALESCE(c.name, ''),
+			COALESCE(p.stock, 0), p.attributes, COALESCE(p.image, '{}'),
+			COALESCE((
+				SELECT json_agg(json_build_object('count', pr.count, 'price', pr.price) ORDER BY pr.count)
+				FROM prices pr
+				WHERE pr.product_id = p.id
+			), '[]')
+		FROM products p
+		LEFT JOIN firms f ON f.id = p.firm_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		ORDER BY p.id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item          models.CatalogItem
+			attrs, prices []byte
+			images        pq.StringArray
+		)
+		err := rows.Scan(&item.ID, &item.Name, &item.Description, &item.Firm, &item.Category,
+			&item.Stock, &attrs, &images, &prices)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(attrs, &item.Attributes); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(prices, &item.Prices); err != nil {
+			return err
+		}
+		item.Images = images
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,96 @@
+package catalog_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/catalog"
+	"telegramshop_backend/internal/repository/prices"
+	"telegramshop_backend/internal/repository/products"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func TestCatalogRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := catalog.NewRepository(db)
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+
+	stock, newStock, description := 7, 3, ""
+	item := models.CatalogItem{
+		Description: &description,
+		Name:        "Imported Product",
+		Firm:        fmt.Sprintf("Imported Firm %d", suffix),
+		Category:    fmt.Sprintf("Imported Category %d", suffix),
+		Stock:       &stock,
+		Attributes:  map[string]interface{}{"color": "black"},
+		Images:      []string{"https://example.com/imported.jpg"},
+		Prices:      []models.CatalogPrice{{Count: 1, Price: 100}, {Count: 10, Price: 90}},
+	}
+
+	created, updated, dropped, err := repo.Import(ctx, []models.CatalogItem{item, {Name: "Second", Firm: item.Firm, Category: item.Category}})
+	require.NoError(t, err)
+	require.Empty(t, dropped)
+	require.Equal(t, 2, created)
+	require.Zero(t, updated)
+
+	var exported []models.CatalogItem
+	require.NoError(t, repo.Export(ctx, func(item models.CatalogItem) error {
+		if item.Firm == fmt.Sprintf("Imported Firm %d", suffix) {
+			exported = append(exported, item)
+		}
+		return nil
+	}))
+	require.Len(t, exported, 2)
+	item.ID = exported[0].ID
+	require.Equal(t, item, exported[0])
+
+	existing, err := repo.ExistingProductIDs(ctx, []int64{item.ID, -1})
+	require.NoError(t, err)
+	require.Equal(t, map[int64]bool{item.ID: true}, existing)
+
+	// Empty prices and images and absent attributes keep the stored ones.
+	_, updated, dropped, err = repo.Import(ctx, []models.CatalogItem{{ID: item.ID, Name: "Renamed", Firm: item.Firm, Category: item.Category, Stock: &newStock}})
+	require.NoError(t, err)
+	require.Equal(t, 1, updated)
+	require.Empty(t, dropped)
+
+	product, err := products.NewRepository(db).GetProductByID(ctx, item.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Renamed", product.Name)
+	require.Equal(t, 3, product.Stock)
+	require.Equal(t, item.Images, []string(product.Image))
+	require.Equal(t, item.Attributes, product.Attributes)
+
+	// Absent stock keeps the stored one.
+	_, _, _, err = repo.Import(ctx, []models.CatalogItem{{ID: item.ID, Name: "Renamed", Firm: item.Firm, Category: item.Category}})
+	require.NoError(t, err)
+	product, err = products.NewRepository(db).GetProductByID(ctx, item.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, product.Stock)
+
+	tiers, err := prices.NewRepository(db).GetPricesByProductID(ctx, item.ID)
+	require.NoError(t, err)
+	require.Len(t, tiers, 2)
+
+	// Replaced images are reported for cleanup.
+	replacement := []string{"https://example.com/replacement.jpg", "https://example.com/imported.jpg"}
+	_, _, dropped, err = repo.Import(ctx, []models.CatalogItem{{ID: item.ID, Name: "Renamed", Firm: item.Firm, Category: item.Category, Images: replacement[:1]}})
+	require.NoError(t, err)
+	require.Equal(t, replacement[1:], dropped)
+
+	_, _, _, err = repo.Import(ctx, []models.CatalogItem{{ID: -1, Name: "Ghost", Firm: item.Firm, Category: item.Category}})
+	require.ErrorIs(t, err, catalog.ErrProductNotFound)
+}
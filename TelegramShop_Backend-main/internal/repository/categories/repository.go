@@ -3,18 +3,32 @@ package categories
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/products"
 
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	ErrParentNotFound      = errors.New("parent category not found")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its subcategory")
+)
+
 type Repository interface {
+	// CreateCategory fails with ErrParentNotFound when ParentID does not exist.
 	CreateCategory(ctx context.Context, category models.Category) (models.Category, error)
 	GetCategoryByID(ctx context.Context, id int64) (models.Category, error)
 	GetAllCategories(ctx context.Context) ([]models.Category, error)
+	// UpdateCategory changes the parent only when category.ParentID is set. It fails with
+	// ErrParentNotFound or ErrCategoryCycle when the new parent is unknown or inside the category.
 	UpdateCategory(ctx context.Context, id int64, category models.UpdateCategoryInput) error
-	DeleteCategory(ctx context.Context, id int64) error
+	// DeleteCategory fails with ErrCategoryHasChildren when the category has subcategories,
+	// unless reparent is set: then they move to the parent of the deleted category.
+	DeleteCategory(ctx context.Context, id int64, reparent bool) error
 	SetImage(ctx context.Context, id int64, imageURL string) error
 	RemoveImage(ctx context.Context, id int64) error
 }
@@ -28,13 +42,21 @@ func NewRepository(db *sqlx.DB) Repository {
 }
 
 func (r *repository) CreateCategory(ctx context.Context, category models.Category) (models.Category, error) {
-	query := `INSERT INTO categories (name) VALUES ($1) RETURNING id`
-	err := r.db.QueryRowContext(ctx, query, category.Name).Scan(&category.ID)
+	query := `INSERT INTO categories (name, parent_id) VALUES ($1, $2) RETURNING id`
+	err := outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := checkParent(ctx, tx, 0, category.ParentID); err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx, query, category.Name, category.ParentID).Scan(&category.ID); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityCategory, category.ID)
+	})
 	return category, err
 }
 
 func (r *repository) GetCategoryByID(ctx context.Context, id int64) (models.Category, error) {
-	query := `SELECT id, name, image FROM categories WHERE id = $1`
+	query := `SELECT id, name, image, parent_id FROM categories WHERE id = $1`
 
 	var category models.Category
 	err := r.db.GetContext(ctx, &category, query, id)
@@ -45,7 +67,7 @@ func (r *repository) GetCategoryByID(ctx context.Context, id int64) (models.Cate
 }
 
 func (r *repository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
-	query := `SELECT id, name, image FROM categories`
+	query := `SELECT id, name, image, parent_id FROM categories`
 
 	var categories []models.Category
 	err := r.db.SelectContext(ctx, &categories, query)
@@ -53,15 +75,89 @@ func (r *repository) GetAllCategories(ctx context.Context) ([]models.Category, e
 }
 
 func (r *repository) UpdateCategory(ctx context.Context, id int64, category models.UpdateCategoryInput) error {
-	query := `UPDATE categories SET name = $1, image = $2 WHERE id = $3`
-	_, err := r.db.ExecContext(ctx, query, category.Name, category.Image, id)
-	return err
+	query := `
+		UPDATE categories
+		SET name = $1, image = $2, parent_id = CASE WHEN $3 THEN $4 ELSE parent_id END
+		WHERE id = $5`
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if category.ParentID.Set {
+			if err := checkParent(ctx, tx, id, category.ParentID.Value); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, query, category.Name, category.Image, category.ParentID.Set, category.ParentID.Value, id); err != nil {
+			return err
+		}
+		if err := products.RefreshSearchVectors(ctx, tx, "p.category_id = $1", id); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityCategory, id)
+	})
+}
+
+// checkParent verifies that parentID exists and is not the category id itself or one of its
+// descendants. id is 0 for a category that is being created. A move locks every category first,
+// so concurrent moves run one after another and cannot close a loop between them.
+func checkParent(ctx context.Context, tx *sqlx.Tx, id int64, parentID *int64) error {
+	if parentID == nil {
+		return nil
+	}
+	if id != 0 {
+		if _, err := tx.ExecContext(ctx, `SELECT id FROM categories ORDER BY id FOR UPDATE`); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors) AS found,
+			EXISTS (SELECT 1 FROM ancestors WHERE id = $2) AS cycle`
+
+	var result struct {
+		Found bool `db:"found"`
+		Cycle bool `db:"cycle"`
+	}
+	if err := tx.GetContext(ctx, &result, query, *parentID, id); err != nil {
+		return err
+	}
+	switch {
+	case !result.Found:
+		return ErrParentNotFound
+	case result.Cycle:
+		return ErrCategoryCycle
+	}
+	return nil
 }
 
-func (r *repository) DeleteCategory(ctx context.Context, id int64) error {
+func (r *repository) DeleteCategory(ctx context.Context, id int64, reparent bool) error {
 	query := `DELETE FROM categories WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if reparent {
+			reparentQuery := `
+				UPDATE categories
+				SET parent_id = (SELECT parent_id FROM categories WHERE id = $1)
+				WHERE parent_id = $1`
+			if _, err := tx.ExecContext(ctx, reparentQuery, id); err != nil {
+				return err
+			}
+		} else {
+			var hasChildren bool
+			if err := tx.GetContext(ctx, &hasChildren, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`, id); err != nil {
+				return err
+			}
+			if hasChildren {
+				return ErrCategoryHasChildren
+			}
+		}
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityCategory, id)
+	})
 }
 
 func (r *repository) SetImage(ctx context.Context, id int64, imageURL string) error {
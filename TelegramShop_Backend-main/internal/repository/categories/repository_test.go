@@ -2,7 +2,9 @@ package categories_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/categories"
@@ -60,8 +62,7 @@ func TestCategoryRepository(t *testing.T) {
 		createdCategory, err := repo.CreateCategory(ctx, category)
 		require.NoError(t, err)
 
-		createdCategory.Name = "Updated Category"
-		err = repo.UpdateCategory(ctx, createdCategory)
+		err = repo.UpdateCategory(ctx, createdCategory.ID, models.UpdateCategoryInput{Name: "Updated Category"})
 		require.NoError(t, err)
 
 		updatedCategory, err := repo.GetCategoryByID(ctx, createdCategory.ID)
@@ -96,11 +97,38 @@ func TestCategoryRepository(t *testing.T) {
 		createdCategory, err := repo.CreateCategory(ctx, category)
 		require.NoError(t, err)
 
-		err = repo.DeleteCategory(ctx, createdCategory.ID)
+		err = repo.DeleteCategory(ctx, createdCategory.ID, false)
 		require.NoError(t, err)
 
 		deletedCategory, err := repo.GetCategoryByID(ctx, createdCategory.ID)
 		require.NoError(t, err)
 		require.Equal(t, int64(0), deletedCategory.ID)
 	})
+
+	t.Run("MoveCategory", func(t *testing.T) {
+		suffix := fmt.Sprint(time.Now().UnixNano())
+		root, err := repo.CreateCategory(ctx, models.Category{Name: "Move Root " + suffix})
+		require.NoError(t, err)
+		child, err := repo.CreateCategory(ctx, models.Category{Name: "Move Child " + suffix, ParentID: &root.ID})
+		require.NoError(t, err)
+		other, err := repo.CreateCategory(ctx, models.Category{Name: "Move Other " + suffix})
+		require.NoError(t, err)
+
+		missing := int64(-1)
+		_, err = repo.CreateCategory(ctx, models.Category{Name: "Orphan " + suffix, ParentID: &missing})
+		require.ErrorIs(t, err, categories.ErrParentNotFound)
+
+		err = repo.UpdateCategory(ctx, root.ID, models.UpdateCategoryInput{Name: root.Name, ParentID: models.NullableID{Set: true, Value: &child.ID}})
+		require.ErrorIs(t, err, categories.ErrCategoryCycle)
+		err = repo.UpdateCategory(ctx, root.ID, models.UpdateCategoryInput{Name: root.Name, ParentID: models.NullableID{Set: true, Value: &root.ID}})
+		require.ErrorIs(t, err, categories.ErrCategoryCycle)
+
+		require.NoError(t, repo.UpdateCategory(ctx, child.ID, models.UpdateCategoryInput{Name: child.Name, ParentID: models.NullableID{Set: true, Value: &other.ID}}))
+		require.NoError(t, repo.UpdateCategory(ctx, child.ID, models.UpdateCategoryInput{Name: "Renamed Child " + suffix}))
+
+		moved, err := repo.GetCategoryByID(ctx, child.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Renamed Child "+suffix, moved.Name)
+		require.Equal(t, other.ID, *moved.ParentID, "a rename without parent_id keeps the parent")
+	})
 }
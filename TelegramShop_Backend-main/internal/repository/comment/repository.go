@@ -2,9 +2,13 @@ package comment
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/pkg/pagination"
 	"time"
 )
 
@@ -12,7 +16,8 @@ type Repository interface {
 	AddComment(ctx context.Context, comment models.Comment) (models.Comment, error)
 	UpdateComment(ctx context.Context, commentID int, newComment string) error
 	DeleteComment(ctx context.Context, commentID int) error
-	GetCommentsByProduct(ctx context.Context, productID int) ([]models.Comment, error)
+	// GetCommentsByProduct returns one page of the product comments, newest first, and the cursor of the next page.
+	GetCommentsByProduct(ctx context.Context, productID int, page pagination.Page) ([]models.Comment, string, error)
 	GetCommentsByUser(ctx context.Context, userID int64) ([]models.Comment, error)
 }
 
@@ -26,12 +31,18 @@ func (r repository) AddComment(ctx context.Context, comment models.Comment) (mod
         VALUES ($1, $2, $3, $4)
         RETURNING id, created_at`
 
-	err := r.db.QueryRowContext(ctx, query,
-		comment.UserID,
-		comment.ProductID,
-		comment.Comment,
-		time.Now().Format(time.RFC3339),
-	).Scan(&comment.ID, &comment.CreatedAt)
+	err := outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowContext(ctx, query,
+			comment.UserID,
+			comment.ProductID,
+			comment.Comment,
+			time.Now().Format(time.RFC3339),
+		).Scan(&comment.ID, &comment.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return insertCommentEvent(ctx, tx, comment.UserID, int64(comment.ProductID), models.ReviewCreated)
+	})
 	if err != nil {
 		return models.Comment{}, err
 	}
@@ -40,27 +51,66 @@ func (r repository) AddComment(ctx context.Context, comment models.Comment) (mod
 }
 
 func (r *repository) UpdateComment(ctx context.Context, commentID int, newComment string) error {
-	query := `UPDATE comments SET comment = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, newComment, commentID)
-	return err
+	query := `UPDATE comments SET comment = $1 WHERE id = $2 RETURNING user_id, product_id`
+	return r.changeComment(ctx, models.ReviewUpdated, query, newComment, commentID)
 }
 
 func (r repository) DeleteComment(ctx context.Context, commentID int) error {
-	query := `DELETE FROM comments WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, commentID)
-	return err
+	query := `DELETE FROM comments WHERE id = $1 RETURNING user_id, product_id`
+	return r.changeComment(ctx, models.ReviewDeleted, query, commentID)
 }
 
-func (r repository) GetCommentsByProduct(ctx context.Context, productID int) ([]models.Comment, error) {
-	query := `SELECT id, user_id, product_id, comment, created_at FROM comments WHERE product_id = $1`
+// changeComment runs a single-row comment query returning user_id and product_id and records a review event.
+func (r repository) changeComment(ctx context.Context, action, query string, args ...interface{}) error {
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var userID, productID int64
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&userID, &productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return insertCommentEvent(ctx, tx, userID, productID, action)
+	})
+}
+
+func insertCommentEvent(ctx context.Context, tx *sqlx.Tx, userID, productID int64, action string) error {
+	return outbox.Insert(ctx, tx, models.EventReviewChanged, models.ReviewEvent{
+		ProductID: productID,
+		UserID:    userID,
+		Kind:      "comment",
+		Action:    action,
+	})
+}
+
+func (r repository) GetCommentsByProduct(ctx context.Context, productID int, page pagination.Page) ([]models.Comment, string, error) {
+	var position pagination.IDPosition
+	resume, err := pagination.Decode(page.Cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := `
+		SELECT id, user_id, product_id, comment, created_at
+		FROM comments
+		WHERE product_id = $1 AND (NOT $2 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`
 
 	var comments []models.Comment
-	err := r.db.SelectContext(ctx, &comments, query, productID)
+	err = r.db.SelectContext(ctx, &comments, query, productID, resume, position.ID, page.Limit+1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return comments, nil
+	var next string
+	if len(comments) > page.Limit {
+		comments = comments[:page.Limit]
+		next = pagination.Encode(pagination.IDPosition{ID: int64(comments[page.Limit-1].ID)})
+	}
+
+	return comments, next, nil
 }
 
 func (r repository) GetCommentsByUser(ctx context.Context, userID int64) ([]models.Comment, error) {
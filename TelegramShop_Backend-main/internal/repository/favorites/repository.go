@@ -2,6 +2,9 @@ package favorites
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 
 	"telegramshop_backend/internal/models"
 
@@ -9,10 +12,20 @@ import (
 	_ "github.com/lib/pq"
 )
 
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrVariantNotFound = errors.New("product variant not found")
+	ErrVariantRequired = errors.New("product has variants, variant_id is required")
+)
+
 type Repository interface {
-	GetUserFavorites(ctx context.Context, userID int64) ([]models.Favorite, error)
+	// GetUserFavorites returns the favorites with their product details in one query.
+	GetUserFavorites(ctx context.Context, userID int64) ([]models.FavoriteLine, error)
 	IsProductInFavorites(ctx context.Context, userID int64, productID int) (bool, error)
+	// CreateFavorite adds the product or variant; adding it again is a no-op. The variant must
+	// belong to the product, and a product that has variants needs one.
 	CreateFavorite(ctx context.Context, input models.CreateFavorite) error
+	// DeleteFavorite removes the variant, or every favorite of the product when VariantID is nil.
 	DeleteFavorite(ctx context.Context, input models.DeleteFavorite) error
 }
 
@@ -24,28 +37,25 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) GetUserFavorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
-	
+func (r *repository) GetUserFavorites(ctx context.Context, userID int64) ([]models.FavoriteLine, error) {
 	query := `
-		SELECT user_id, product_id
-		FROM favorites
-		WHERE user_id = $1
+		SELECT f.user_id, f.product_id, f.variant_id, f.added_at,
+			p.name, p.image[1] AS image,
+			COALESCE(v.stock, p.stock) AS stock,
+			COALESCE(v.stock, p.stock) > 0 AS in_stock
+		FROM favorites f
+		JOIN products p ON p.id = f.product_id
+		LEFT JOIN product_variants v ON v.id = f.variant_id
+		WHERE f.user_id = $1
+		ORDER BY f.added_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	favorites := []models.FavoriteLine{}
+	err := r.db.SelectContext(ctx, &favorites, query, userID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var favorites []models.Favorite
-	for rows.Next() {
-		var fav models.Favorite
-		if err := rows.Scan(&fav.UserID, &fav.ProductID); err != nil {
-			return nil, err
-		}
-		favorites = append(favorites, fav)
-	}
-	
 	return favorites, nil
 }
 
@@ -63,25 +73,60 @@ func (r *repository) IsProductInFavorites(ctx context.Context, userID int64, pro
 }
 
 func (r *repository) CreateFavorite(ctx context.Context, input models.CreateFavorite) error {
-	
+	if err := r.checkVariant(ctx, input.ProductID, input.VariantID); err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO favorites (user_id, product_id)
-		VALUES ($1, $2)
+		INSERT INTO favorites (user_id, product_id, variant_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, product_id, COALESCE(variant_id, 0)) DO NOTHING
 	`
-	
-	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID)
-	
+
+	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID, input.VariantID)
 	return err
 }
 
+// checkVariant applies the variant rules of the basket: the variant must belong to the product,
+// and a product sold in variants cannot be added without one.
+func (r *repository) checkVariant(ctx context.Context, productID int, variantID *int) error {
+	var product struct {
+		HasVariants bool `db:"has_variants"`
+		HasVariant  bool `db:"has_variant"`
+	}
+	query := `
+		SELECT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id) AS has_variants,
+			EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.id = $2) AS has_variant
+		FROM products p
+		WHERE p.id = $1`
+	err := r.db.GetContext(ctx, &product, query, productID, variantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: product %d", ErrProductNotFound, productID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if variantID == nil {
+		if product.HasVariants {
+			return fmt.Errorf("%w: product %d", ErrVariantRequired, productID)
+		}
+		return nil
+	}
+	if !product.HasVariant {
+		return fmt.Errorf("%w: variant %d of product %d", ErrVariantNotFound, *variantID, productID)
+	}
+	return nil
+}
+
 func (r *repository) DeleteFavorite(ctx context.Context, input models.DeleteFavorite) error {
 	
 	query := `
 		DELETE FROM favorites
-		WHERE user_id = $1 AND product_id = $2
+		WHERE user_id = $1 AND product_id = $2 AND ($3::integer IS NULL OR variant_id = $3)
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID)
+	_, err := r.db.ExecContext(ctx, query, input.UserID, input.ProductID, input.VariantID)
 	
 	return err
 }
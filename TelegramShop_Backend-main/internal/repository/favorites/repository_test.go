@@ -0,0 +1,76 @@
+package favorites_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/favorites"
+	"telegramshop_backend/internal/repository/products"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func createUser(t *testing.T, db *sqlx.DB) int64 {
+	suffix := time.Now().UnixNano()
+	var id int64
+	err := db.Get(&id, `INSERT INTO users (telegram_id, username) VALUES ($1, $2) RETURNING id`, suffix, fmt.Sprintf("favorites_test_%d", suffix))
+	require.NoError(t, err)
+	return id
+}
+
+func TestFavoritesVariants(t *testing.T) {
+	db := setupTestDB(t)
+	repo := favorites.NewRepository(db)
+	productsRepo := products.NewRepository(db)
+	ctx := context.Background()
+
+	userID := createUser(t, db)
+	product, err := productsRepo.CreateProduct(ctx, models.Product{Name: "Favorites Test Product", Description: "Favorites test"})
+	require.NoError(t, err)
+	var variantIDs []int
+	for i := 0; i < 2; i++ {
+		variant, err := productsRepo.CreateVariant(ctx, product.ID, models.ProductVariantInput{SKU: fmt.Sprintf("FAV-%d", time.Now().UnixNano()), Stock: 1})
+		require.NoError(t, err)
+		variantIDs = append(variantIDs, int(variant.ID))
+	}
+
+	for _, variantID := range variantIDs {
+		require.NoError(t, repo.CreateFavorite(ctx, models.CreateFavorite{UserID: userID, ProductID: int(product.ID), VariantID: &variantID}))
+	}
+	require.NoError(t, repo.CreateFavorite(ctx, models.CreateFavorite{UserID: userID, ProductID: int(product.ID), VariantID: &variantIDs[0]}), "adding a favorite again is a no-op")
+
+	other, err := productsRepo.CreateProduct(ctx, models.Product{Name: "Favorites Test Other", Description: "Favorites test"})
+	require.NoError(t, err)
+	err = repo.CreateFavorite(ctx, models.CreateFavorite{UserID: userID, ProductID: int(other.ID), VariantID: &variantIDs[0]})
+	require.ErrorIs(t, err, favorites.ErrVariantNotFound, "the variant must belong to the product")
+	err = repo.CreateFavorite(ctx, models.CreateFavorite{UserID: userID, ProductID: int(product.ID)})
+	require.ErrorIs(t, err, favorites.ErrVariantRequired)
+	err = repo.CreateFavorite(ctx, models.CreateFavorite{UserID: userID, ProductID: -1})
+	require.ErrorIs(t, err, favorites.ErrProductNotFound)
+
+	lines, err := repo.GetUserFavorites(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	require.NoError(t, repo.DeleteFavorite(ctx, models.DeleteFavorite{UserID: userID, ProductID: int(product.ID), VariantID: &variantIDs[0]}))
+	lines, err = repo.GetUserFavorites(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	require.Equal(t, variantIDs[1], *lines[0].VariantID)
+
+	require.NoError(t, repo.DeleteFavorite(ctx, models.DeleteFavorite{UserID: userID, ProductID: int(product.ID)}))
+	lines, err = repo.GetUserFavorites(ctx, userID)
+	require.NoError(t, err)
+	require.Empty(t, lines)
+}
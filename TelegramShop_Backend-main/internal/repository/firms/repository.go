@@ -5,6 +5,8 @@ import (
 	"database/sql"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/products"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -31,7 +33,12 @@ func (r *repository) CreateFirm(ctx context.Context, firm models.Firm) (models.F
 		VALUES ($1)
 		RETURNING id`
 
-	err := r.db.QueryRowContext(ctx, query, firm.Name).Scan(&firm.ID)
+	err := outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, firm.Name).Scan(&firm.ID); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityFirm, firm.ID)
+	})
 	if err != nil {
 		return models.Firm{}, err
 	}
@@ -77,13 +84,24 @@ func (r *repository) UpdateFirm(ctx context.Context, id int64, input models.Upda
 		SET name = $1
 		WHERE id = $2`
 
-	_, err := r.db.ExecContext(ctx, query, input.Name, id)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, input.Name, id); err != nil {
+			return err
+		}
+		if err := products.RefreshSearchVectors(ctx, tx, "p.firm_id = $1", id); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityFirm, id)
+	})
 }
 
 func (r *repository) DeleteFirm(ctx context.Context, id int64) error {
 	query := `DELETE FROM firms WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityFirm, id)
+	})
 }
@@ -0,0 +1,48 @@
+package images
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// IsReferenced reports whether a product or a category still uses the image at url.
+	IsReferenced(ctx context.Context, url string) (bool, error)
+	// WithLock runs fn while holding a database lock on the stored file key, so an upload
+	// and a cleanup of the same file never interleave, even on different instances.
+	WithLock(ctx context.Context, key string, fn func() error) error
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) IsReferenced(ctx context.Context, url string) (bool, error) {
+	var referenced bool
+	err := r.db.GetContext(ctx, &referenced, `
+		SELECT EXISTS (SELECT 1 FROM products WHERE $1 = ANY(image))
+			OR EXISTS (SELECT 1 FROM categories WHERE image = $1)`, url)
+	return referenced, err
+}
+
+func (r *repository) WithLock(ctx context.Context, key string, fn func() error) error {
+	// The lock belongs to the transaction, so it is released however fn ends.
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
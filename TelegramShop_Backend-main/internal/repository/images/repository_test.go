@@ -0,0 +1,57 @@
+package images_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/repository/images"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func TestIsReferenced(t *testing.T) {
+	db := setupTestDB(t)
+	repo := images.NewRepository(db)
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+
+	productImage := fmt.Sprintf("https://example.com/uploads/images/product-%d.jpg", suffix)
+	categoryImage := fmt.Sprintf("https://example.com/uploads/images/category-%d.jpg", suffix)
+
+	var categoryID int64
+	require.NoError(t, db.GetContext(ctx, &categoryID,
+		`INSERT INTO categories (name, image) VALUES ($1, $2) RETURNING id`, fmt.Sprintf("Images %d", suffix), categoryImage))
+	defer db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, categoryID)
+
+	var firmID int64
+	require.NoError(t, db.GetContext(ctx, &firmID,
+		`INSERT INTO firms (name) VALUES ($1) RETURNING id`, fmt.Sprintf("Images %d", suffix)))
+	defer db.ExecContext(ctx, `DELETE FROM firms WHERE id = $1`, firmID)
+
+	var productID int64
+	require.NoError(t, db.GetContext(ctx, &productID, `
+		INSERT INTO products (name, description, firm_id, category_id, image)
+		VALUES ('Images', '', $1, $2, ARRAY['https://example.com/other.jpg', $3])
+		RETURNING id`, firmID, categoryID, productImage))
+	defer db.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, productID)
+
+	for url, want := range map[string]bool{
+		productImage:  true,
+		categoryImage: true,
+		fmt.Sprintf("https://example.com/uploads/images/unused-%d.jpg", suffix): false,
+	} {
+		referenced, err := repo.IsReferenced(ctx, url)
+		require.NoError(t, err)
+		require.Equal(t, want, referenced, url)
+	}
+}
@@ -3,6 +3,7 @@ package marks
 import (
 	"context"
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -29,12 +30,18 @@ func (r *repository) AddMark(ctx context.Context, mark models.Marks) (models.Mar
         INSERT INTO marks (user_id, product_id, mark, created_at)
         VALUES ($1, $2, $3, $4)
         RETURNING created_at`
-	err := r.db.QueryRowContext(ctx, query,
-		mark.UserID,
-		mark.ProductID,
-		mark.Mark,
-		time.Now().Format(time.RFC3339),
-	).Scan(&mark.CreatedAt)
+	err := outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowContext(ctx, query,
+			mark.UserID,
+			mark.ProductID,
+			mark.Mark,
+			time.Now().Format(time.RFC3339),
+		).Scan(&mark.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return insertMarkEvent(ctx, tx, mark.UserID, mark.ProductID, models.ReviewCreated)
+	})
 	if err != nil {
 		return models.Marks{}, err
 	}
@@ -44,14 +51,31 @@ func (r *repository) AddMark(ctx context.Context, mark models.Marks) (models.Mar
 
 func (r *repository) UpdateMark(ctx context.Context, userID int64, productID int, newMark float64) error {
 	query := `UPDATE marks SET mark = $1 WHERE user_id = $2 AND product_id = $3`
-	_, err := r.db.ExecContext(ctx, query, newMark, userID, productID)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, newMark, userID, productID); err != nil {
+			return err
+		}
+		return insertMarkEvent(ctx, tx, userID, productID, models.ReviewUpdated)
+	})
 }
 
 func (r *repository) DeleteMark(ctx context.Context, userID int64, productID int) error {
 	query := `DELETE FROM marks WHERE user_id = $1 AND product_id = $2`
-	_, err := r.db.ExecContext(ctx, query, userID, productID)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, userID, productID); err != nil {
+			return err
+		}
+		return insertMarkEvent(ctx, tx, userID, productID, models.ReviewDeleted)
+	})
+}
+
+func insertMarkEvent(ctx context.Context, tx *sqlx.Tx, userID int64, productID int, action string) error {
+	return outbox.Insert(ctx, tx, models.EventReviewChanged, models.ReviewEvent{
+		ProductID: int64(productID),
+		UserID:    userID,
+		Kind:      "mark",
+		Action:    action,
+	})
 }
 
 func (r *repository) GetMarksByProduct(ctx context.Context, productID int) ([]models.Marks, error) {
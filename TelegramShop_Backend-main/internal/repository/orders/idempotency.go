@@ -0,0 +1,118 @@
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// CreateOrderIdempotent creates the order at most once per user and key. The key is stored in the
+// same transaction as the order, so a failed attempt leaves nothing behind and can be retried.
+// A repeated request with the same hash returns the stored order with replayed set to true.
+func (r *repository) CreateOrderIdempotent(ctx context.Context, input models.CreateOrder, key, requestHash string, ttl time.Duration) (order models.OrderWithProducts, replayed bool, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND expires_at <= current_timestamp`,
+		input.UserID, key)
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	// A concurrent request holding the same key blocks this insert until it finishes.
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, current_timestamp + $4 * interval '1 second')
+		ON CONFLICT (user_id, key) DO NOTHING`,
+		input.UserID, key, requestHash, ttl.Seconds())
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	if inserted == 0 {
+		order, err = storedResponse(ctx, tx, input.UserID, key, requestHash)
+		if err != nil {
+			return models.OrderWithProducts{}, false, err
+		}
+		return order, true, nil
+	}
+
+	order, err = insertOrder(ctx, tx, input.UserID, input.Items, input.PromoCode)
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	response, err := json.Marshal(order)
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET order_id = $1, response = $2
+		WHERE user_id = $3 AND key = $4`,
+		order.ID, string(response), input.UserID, key)
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	return order, false, nil
+}
+
+// storedResponse returns the order saved under the key, provided it was created for the same request.
+func storedResponse(ctx context.Context, tx *sqlx.Tx, userID int64, key, requestHash string) (models.OrderWithProducts, error) {
+	var stored struct {
+		RequestHash string         `db:"request_hash"`
+		Response    sql.NullString `db:"response"`
+	}
+	err := tx.GetContext(ctx, &stored, `
+		SELECT request_hash, response
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2`,
+		userID, key)
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	if stored.RequestHash != requestHash || !stored.Response.Valid {
+		return models.OrderWithProducts{}, ErrIdempotencyKeyReused
+	}
+
+	var order models.OrderWithProducts
+	if err := json.Unmarshal([]byte(stored.Response.String), &order); err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	return order, nil
+}
+
+// DeleteExpiredIdempotencyKeys removes keys past their retention window.
+func (r *repository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= current_timestamp`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
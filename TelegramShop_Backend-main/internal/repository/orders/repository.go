@@ -3,19 +3,70 @@ package orders
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/pricing"
+	"telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/repository/promotions"
+	"telegramshop_backend/pkg/money"
+	"telegramshop_backend/pkg/pagination"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+var (
+	ErrEmptyOrder       = errors.New("order has no items")
+	ErrInvalidQuantity  = errors.New("quantity must be positive")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrPriceTierMissing = errors.New("no price tier matches the requested quantity")
+	ErrStatusConflict   = errors.New("order status was changed concurrently")
+	ErrEmptyBasket      = errors.New("basket is empty")
+	ErrVariantNotFound  = errors.New("product variant not found")
+	ErrVariantRequired  = errors.New("product has variants, variant_id is required")
+)
+
+// InsufficientStockError lists the products and variants whose stock cannot cover the requested quantity.
+type InsufficientStockError struct {
+	ProductIDs []int `json:"product_ids"`
+	VariantIDs []int `json:"variant_ids,omitempty"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for products %v", e.ProductIDs)
+}
+
+// UnavailableBasketError is returned by Checkout when none of the basket lines can be ordered.
+type UnavailableBasketError struct {
+	Dropped []models.DroppedBasketItem `json:"dropped"`
+}
+
+func (e *UnavailableBasketError) Error() string {
+	return fmt.Sprintf("none of the %d basket items can be ordered", len(e.Dropped))
+}
+
 type Repository interface {
 	CreateOrder(ctx context.Context, input models.CreateOrder) (models.OrderWithProducts, error)
+	CreateOrderIdempotent(ctx context.Context, input models.CreateOrder, key, requestHash string, ttl time.Duration) (models.OrderWithProducts, bool, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	// Checkout and PreviewBasket apply promoCode when it is not empty; a code that cannot be
+	// applied fails them with *promotions.PromoCodeError.
+	Checkout(ctx context.Context, userID int64, promoCode string) (models.Checkout, error)
+	// PreviewBasket prices the user's basket like Checkout would, without placing an order.
+	PreviewBasket(ctx context.Context, userID int64, promoCode string) (models.OrderQuote, error)
 	GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error)
-	GetUserOrders(ctx context.Context, userID int64) ([]models.OrderWithProducts, error)
-	GetAll(ctx context.Context) ([]models.OrderWithProducts, error)
+	// GetUserOrders and GetAll return one page of orders, newest first, and the cursor of the next page.
+	GetUserOrders(ctx context.Context, userID int64, page pagination.Page) ([]models.OrderWithProducts, string, error)
+	GetAll(ctx context.Context, page pagination.Page) ([]models.OrderWithProducts, string, error)
+	UpdateStatus(ctx context.Context, orderID int, from, to string, actorID int64, comment string) error
+	CancelOrder(ctx context.Context, orderID int, from string, actorID int64, reason string) error
+	GetStatusHistory(ctx context.Context, orderID int) ([]models.OrderStatusChange, error)
 }
 
 type repository struct {
@@ -33,45 +84,717 @@ func (r *repository) CreateOrder(ctx context.Context, input models.CreateOrder)
 	}
 	defer tx.Rollback()
 
+	order, err := insertOrder(ctx, tx, input.UserID, input.Items, input.PromoCode)
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	return order, nil
+}
+
+// Checkout turns the user's basket into an order and clears the basket in the same transaction.
+// Lines whose product is gone, whose stock cannot cover the quantity or whose quantity has no
+// price tier are left out and reported.
+func (r *repository) Checkout(ctx context.Context, userID int64, promoCode string) (models.Checkout, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Checkout{}, err
+	}
+	defer tx.Rollback()
+
+	basketItems, err := basket.LockUserBasket(ctx, tx, userID)
+	if err != nil {
+		return models.Checkout{}, err
+	}
+
+	available, dropped, err := availableBasketItems(ctx, tx, basketItems, true)
+	if err != nil {
+		return models.Checkout{}, err
+	}
+
+	result := models.Checkout{Dropped: dropped}
+	result.Order, err = insertOrder(ctx, tx, userID, available, promoCode)
+	if err != nil {
+		return models.Checkout{}, err
+	}
+
+	if err = basket.ClearUserBasketTx(ctx, tx, userID); err != nil {
+		return models.Checkout{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Checkout{}, err
+	}
+
+	return result, nil
+}
+
+func (r *repository) PreviewBasket(ctx context.Context, userID int64, promoCode string) (models.OrderQuote, error) {
+	// Nothing is written: the transaction only gives the reads one consistent view and is rolled back.
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.OrderQuote{}, err
+	}
+	defer tx.Rollback()
+
+	var basketItems []models.BasketItem
+	err = tx.SelectContext(ctx, &basketItems, `
+		SELECT user_id, product_id, variant_id, quantity
+		FROM basket
+		WHERE user_id = $1 AND product_id IS NOT NULL
+		ORDER BY id`, userID)
+	if err != nil {
+		return models.OrderQuote{}, err
+	}
+
+	available, dropped, err := availableBasketItems(ctx, tx, basketItems, false)
+	if err != nil {
+		return models.OrderQuote{}, err
+	}
+
+	stock, err := loadStock(ctx, tx, available, false)
+	if err != nil {
+		return models.OrderQuote{}, err
+	}
+	lines, subtotal, err := priceItems(ctx, tx, available, stock)
+	if err != nil {
+		return models.OrderQuote{}, err
+	}
+
+	quote := models.OrderQuote{
+		Products:    lines,
+		Subtotal:    money.Round(subtotal),
+		Discounts:   []models.OrderDiscount{},
+		TotalAmount: money.Round(subtotal),
+		Dropped:     dropped,
+	}
+	if promoCode == "" {
+		return quote, nil
+	}
+
+	promo, discounts, err := promotions.Apply(ctx, tx, userID, promoCode, lines, false)
+	if err != nil {
+		return models.OrderQuote{}, err
+	}
+	quote.PromoCode = &promo.Code
+	quote.Discounts = discounts
+	quote.DiscountAmount = discountTotal(discounts)
+	quote.TotalAmount = money.Round(math.Max(subtotal-quote.DiscountAmount, 0))
+
+	return quote, nil
+}
+
+// availableBasketItems merges the basket lines and splits them into the ones the current
+// stock and price tiers can cover and the dropped rest. It fails when no line can be ordered.
+func availableBasketItems(ctx context.Context, tx *sqlx.Tx, basketItems []models.BasketItem, lock bool) ([]models.CreateOrderItem, []models.DroppedBasketItem, error) {
+	items := make([]models.CreateOrderItem, 0, len(basketItems))
+	for _, item := range basketItems {
+		items = append(items, models.CreateOrderItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+	items, err := mergeItems(items)
+	if errors.Is(err, ErrEmptyOrder) {
+		return nil, nil, ErrEmptyBasket
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stock, err := loadStock(ctx, tx, items, lock)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tiers, err := productTiers(ctx, tx, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dropped := []models.DroppedBasketItem{}
+	available := make([]models.CreateOrderItem, 0, len(items))
+	for _, item := range items {
+		line := models.DroppedBasketItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		}
+		quantity, err := stock.available(item)
+		switch {
+		case errors.Is(err, ErrProductNotFound):
+			line.Reason = models.DropReasonProductNotFound
+		case errors.Is(err, ErrVariantNotFound):
+			line.Reason = models.DropReasonVariantNotFound
+		case errors.Is(err, ErrVariantRequired):
+			line.Reason = models.DropReasonVariantRequired
+		case quantity < item.Quantity:
+			line.Available = quantity
+			line.Reason = models.DropReasonOutOfStock
+		default:
+			if _, priced := quoteItem(item, stock, tiers); !priced {
+				line.Reason = models.DropReasonPriceTierMissing
+				break
+			}
+			available = append(available, item)
+			continue
+		}
+		dropped = append(dropped, line)
+	}
+	if len(available) == 0 {
+		return nil, nil, &UnavailableBasketError{Dropped: dropped}
+	}
+
+	return available, dropped, nil
+}
+
+// insertOrder prices the items, applies the promo code if any, reserves stock for the items
+// and stores the order inside tx.
+func insertOrder(ctx context.Context, tx *sqlx.Tx, userID int64, items []models.CreateOrderItem, promoCode string) (models.OrderWithProducts, error) {
+	items, err := mergeItems(items)
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	locked, err := lockStock(ctx, tx, items)
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	insufficient := &InsufficientStockError{}
+	for _, item := range items {
+		quantity, err := locked.available(item)
+		if err != nil {
+			return models.OrderWithProducts{}, err
+		}
+		if quantity < item.Quantity {
+			if item.VariantID != nil {
+				insufficient.VariantIDs = append(insufficient.VariantIDs, *item.VariantID)
+			}
+			insufficient.ProductIDs = append(insufficient.ProductIDs, item.ProductID)
+		}
+	}
+	if len(insufficient.ProductIDs) > 0 {
+		return models.OrderWithProducts{}, insufficient
+	}
+
+	lines, total, err := priceItems(ctx, tx, items, locked)
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	var (
+		promo     models.PromoCode
+		discounts []models.OrderDiscount
+		code      *string
+	)
+	if promoCode != "" {
+		promo, discounts, err = promotions.Apply(ctx, tx, userID, promoCode, lines, true)
+		if err != nil {
+			return models.OrderWithProducts{}, err
+		}
+		code = &promo.Code
+	}
+	discount := discountTotal(discounts)
+
 	orderQuery := `
-		INSERT INTO orders (user_id, status)
-		VALUES ($1, $2)
-		RETURNING id, user_id, status, created_at`
+		INSERT INTO orders (user_id, status, total_amount, promo_code, discount_amount)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, status, total_amount, promo_code, discount_amount, created_at`
 
 	var order models.OrderWithProducts
-	err = tx.QueryRowContext(ctx, orderQuery, input.UserID, "pending").Scan(
-		&order.ID, &order.UserID, &order.Status, &order.CreatedAt,
+	err = tx.QueryRowContext(ctx, orderQuery, userID, models.OrderStatusPending, money.Round(math.Max(total-discount, 0)), code, discount).Scan(
+		&order.ID, &order.UserID, &order.Status, &order.TotalAmount, &order.PromoCode, &order.DiscountAmount, &order.CreatedAt,
 	)
 	if err != nil {
 		return models.OrderWithProducts{}, err
 	}
 
-	productQuery := `
-		INSERT INTO order_products (order_id, product_id, quantity, price)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, order_id, product_id, quantity, price`
+	if code != nil {
+		if order.Discounts, err = insertDiscounts(ctx, tx, order.ID, discounts); err != nil {
+			return models.OrderWithProducts{}, err
+		}
+		if err = promotions.Redeem(ctx, tx, promo.ID, order.ID, userID); err != nil {
+			return models.OrderWithProducts{}, err
+		}
+	}
 
-	for _, item := range input.Items {
+	if err = insertStatusChange(ctx, tx, order.ID, nil, order.Status, userID, ""); err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
+	productQuery := `
+		INSERT INTO order_products (order_id, product_id, variant_id, quantity, price)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, order_id, product_id, variant_id, quantity, price`
+
+	// The stock of a product with variants is recomputed from them once all lines are stored.
+	stockQuery := `
+		UPDATE products
+		SET stock = CASE WHEN $3 THEN stock ELSE stock - $1 END,
+			sell_count = COALESCE(sell_count, 0) + $1
+		WHERE id = $2
+		RETURNING stock`
+
+	variantStockQuery := `UPDATE product_variants SET stock = stock - $1 WHERE id = $2`
+
+	variantProducts := make(map[int]bool)
+	for _, line := range lines {
 		var orderProduct models.OrderProduct
-		err = tx.QueryRowContext(ctx, productQuery, order.ID, item.ProductID, item.Quantity, 100).Scan(
-			&orderProduct.ID, &orderProduct.OrderID, &orderProduct.ProductID, &orderProduct.Quantity, &orderProduct.Price,
+		err = tx.QueryRowContext(ctx, productQuery, order.ID, line.ProductID, line.VariantID, line.Quantity, line.Price).Scan(
+			&orderProduct.ID, &orderProduct.OrderID, &orderProduct.ProductID, &orderProduct.VariantID, &orderProduct.Quantity, &orderProduct.Price,
 		)
 		if err != nil {
 			return models.OrderWithProducts{}, err
 		}
 		order.Products = append(order.Products, orderProduct)
+
+		if line.VariantID != nil {
+			if _, err = tx.ExecContext(ctx, variantStockQuery, line.Quantity, *line.VariantID); err != nil {
+				return models.OrderWithProducts{}, err
+			}
+			variantProducts[line.ProductID] = true
+		}
+
+		var stock int
+		if err = tx.QueryRowContext(ctx, stockQuery, line.Quantity, line.ProductID, line.VariantID != nil).Scan(&stock); err != nil {
+			return models.OrderWithProducts{}, err
+		}
+		if line.VariantID == nil {
+			if err = insertStockEvent(ctx, tx, int64(line.ProductID), stock+line.Quantity, stock); err != nil {
+				return models.OrderWithProducts{}, err
+			}
+		}
+	}
+	for productID := range variantProducts {
+		if _, err = products.SyncStock(ctx, tx, int64(productID)); err != nil {
+			return models.OrderWithProducts{}, err
+		}
 	}
 
-	if err = tx.Commit(); err != nil {
+	err = outbox.Insert(ctx, tx, models.EventOrderCreated, models.OrderEvent{
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		To:      order.Status,
+	})
+	if err != nil {
 		return models.OrderWithProducts{}, err
 	}
 
 	return order, nil
 }
 
+// priceItems prices every item with quoteItem and fails when a quantity has no price tier.
+func priceItems(ctx context.Context, tx *sqlx.Tx, items []models.CreateOrderItem, stock lockedStock) ([]models.OrderProduct, float64, error) {
+	tiers, err := productTiers(ctx, tx, items)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lines := make([]models.OrderProduct, 0, len(items))
+	var total float64
+	for _, item := range items {
+		line, ok := quoteItem(item, stock, tiers)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: product %d, quantity %d", ErrPriceTierMissing, item.ProductID, item.Quantity)
+		}
+
+		lines = append(lines, models.OrderProduct{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Price:     line.UnitPrice,
+		})
+		total += line.UnitPrice * float64(item.Quantity)
+	}
+
+	return lines, total, nil
+}
+
+// quoteItem prices item with pricing.Quote: its variant price or else its product's best tier
+// out of tiers, keyed by product id. ok is false when no tier covers the quantity.
+func quoteItem(item models.CreateOrderItem, stock lockedStock, tiers map[int][]models.Price) (models.LinePrice, bool) {
+	var variantPrice *float64
+	if item.VariantID != nil {
+		variantPrice = stock.variants[*item.VariantID].price
+	}
+	return pricing.Quote(tiers[item.ProductID], variantPrice, item.Quantity)
+}
+
+func insertDiscounts(ctx context.Context, tx *sqlx.Tx, orderID int64, discounts []models.OrderDiscount) ([]models.OrderDiscount, error) {
+	query := `
+		INSERT INTO order_discounts (order_id, promo_code_id, code, product_id, variant_id, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, order_id, promo_code_id, code, product_id, variant_id, amount`
+
+	stored := make([]models.OrderDiscount, 0, len(discounts))
+	for _, d := range discounts {
+		var discount models.OrderDiscount
+		err := tx.GetContext(ctx, &discount, query, orderID, d.PromoCodeID, d.Code, d.ProductID, d.VariantID, d.Amount)
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, discount)
+	}
+
+	return stored, nil
+}
+
+func discountTotal(discounts []models.OrderDiscount) float64 {
+	var total float64
+	for _, d := range discounts {
+		total += d.Amount
+	}
+	return money.Round(total)
+}
+
+func insertStockEvent(ctx context.Context, tx *sqlx.Tx, productID int64, prevStock, stock int) error {
+	return outbox.Insert(ctx, tx, models.EventProductStockChanged, models.ProductEvent{ProductID: productID, PrevStock: &prevStock, Stock: &stock})
+}
+
+func (r *repository) UpdateStatus(ctx context.Context, orderID int, from, to string, actorID int64, comment string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	err = tx.QueryRowContext(ctx, `UPDATE orders SET status = $1 WHERE id = $2 AND status = $3 RETURNING user_id`, to, orderID, from).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStatusConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	// A refunded order gives its items back to stock, the same way a cancelled one does.
+	if to == models.OrderStatusRefunded {
+		if err = restockOrder(ctx, tx, orderID); err != nil {
+			return err
+		}
+	}
+
+	if err = insertStatusChange(ctx, tx, int64(orderID), &from, to, actorID, comment); err != nil {
+		return err
+	}
+
+	err = outbox.Insert(ctx, tx, models.EventOrderStatusChanged, models.OrderEvent{
+		OrderID: int64(orderID),
+		UserID:  userID,
+		From:    from,
+		To:      to,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CancelOrder moves the order from status from to cancelled, stores the reason (an empty
+// one as none) and returns the reserved quantities to stock in one transaction.
+func (r *repository) CancelOrder(ctx context.Context, orderID int, from string, actorID int64, reason string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE orders
+		SET status = $1, cancel_reason = NULLIF($2, '')
+		WHERE id = $3 AND status = $4
+		RETURNING user_id`
+
+	var userID int64
+	err = tx.QueryRowContext(ctx, query, models.OrderStatusCancelled, reason, orderID, from).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStatusConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = restockOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	if err = insertStatusChange(ctx, tx, int64(orderID), &from, models.OrderStatusCancelled, actorID, reason); err != nil {
+		return err
+	}
+
+	err = outbox.Insert(ctx, tx, models.EventOrderStatusChanged, models.OrderEvent{
+		OrderID: int64(orderID),
+		UserID:  userID,
+		From:    from,
+		To:      models.OrderStatusCancelled,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// restockOrder returns the quantities reserved by the order to the stock of its products and
+// variants and takes them off the sell counts, recording the stock changes in the outbox.
+func restockOrder(ctx context.Context, tx *sqlx.Tx, orderID int) error {
+	variantRestockQuery := `
+		UPDATE product_variants v
+		SET stock = v.stock + op.quantity
+		FROM (
+			SELECT variant_id, SUM(quantity) AS quantity
+			FROM order_products
+			WHERE order_id = $1 AND variant_id IS NOT NULL
+			GROUP BY variant_id
+		) op
+		WHERE v.id = op.variant_id`
+
+	if _, err := tx.ExecContext(ctx, variantRestockQuery, orderID); err != nil {
+		return err
+	}
+
+	// Lines are summed per product because one product may be ordered in several variants.
+	restockQuery := `
+		UPDATE products p
+		SET stock = COALESCE(p.stock, 0) + op.plain_quantity,
+			sell_count = GREATEST(COALESCE(p.sell_count, 0) - op.quantity, 0)
+		FROM (
+			SELECT product_id,
+				SUM(quantity) AS quantity,
+				COALESCE(SUM(quantity) FILTER (WHERE variant_id IS NULL), 0) AS plain_quantity
+			FROM order_products
+			WHERE order_id = $1
+			GROUP BY product_id
+		) op
+		WHERE op.product_id = p.id
+		RETURNING p.id, p.stock, op.plain_quantity, EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id) AS has_variants`
+
+	var restocked []struct {
+		ProductID   int64 `db:"id"`
+		Stock       int   `db:"stock"`
+		Quantity    int   `db:"plain_quantity"`
+		HasVariants bool  `db:"has_variants"`
+	}
+	if err := tx.SelectContext(ctx, &restocked, restockQuery, orderID); err != nil {
+		return err
+	}
+	for _, product := range restocked {
+		var err error
+		if product.HasVariants {
+			_, err = products.SyncStock(ctx, tx, product.ProductID)
+		} else {
+			err = insertStockEvent(ctx, tx, product.ProductID, product.Stock-product.Quantity, product.Stock)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *repository) GetStatusHistory(ctx context.Context, orderID int) ([]models.OrderStatusChange, error) {
+	query := `
+		SELECT id, order_id, from_status, to_status, actor_id, comment, created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY id`
+
+	var history []models.OrderStatusChange
+	err := r.db.SelectContext(ctx, &history, query, orderID)
+	return history, err
+}
+
+// insertStatusChange records a status transition. A zero actorID means the change was made by the system.
+func insertStatusChange(ctx context.Context, tx *sqlx.Tx, orderID int64, from *string, to string, actorID int64, comment string) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_id, comment)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	var actor sql.NullInt64
+	if actorID != 0 {
+		actor = sql.NullInt64{Int64: actorID, Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, query, orderID, from, to, actor, comment)
+	return err
+}
+
+// mergeItems validates quantities and folds repeated product variants into one line, keeping the first-seen order.
+func mergeItems(items []models.CreateOrderItem) ([]models.CreateOrderItem, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	type lineKey struct{ productID, variantID int }
+
+	merged := make([]models.CreateOrderItem, 0, len(items))
+	index := make(map[lineKey]int, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: product %d", ErrInvalidQuantity, item.ProductID)
+		}
+		key := lineKey{productID: item.ProductID}
+		if item.VariantID != nil {
+			key.variantID = *item.VariantID
+		}
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged, nil
+}
+
+type lockedProduct struct {
+	stock       int
+	hasVariants bool
+}
+
+type lockedVariant struct {
+	productID int
+	stock     int
+	price     *float64
+}
+
+// lockedStock is the stock of the products and variants of an order, locked until the end of tx.
+type lockedStock struct {
+	products map[int]lockedProduct
+	variants map[int]lockedVariant
+}
+
+// available returns the stock that can cover the item, or why the item cannot be ordered at all.
+func (s lockedStock) available(item models.CreateOrderItem) (int, error) {
+	product, ok := s.products[item.ProductID]
+	if !ok {
+		return 0, fmt.Errorf("%w: product %d", ErrProductNotFound, item.ProductID)
+	}
+	if item.VariantID == nil {
+		if product.hasVariants {
+			return 0, fmt.Errorf("%w: product %d", ErrVariantRequired, item.ProductID)
+		}
+		return product.stock, nil
+	}
+
+	variant, ok := s.variants[*item.VariantID]
+	if !ok || variant.productID != item.ProductID {
+		return 0, fmt.Errorf("%w: variant %d of product %d", ErrVariantNotFound, *item.VariantID, item.ProductID)
+	}
+	return variant.stock, nil
+}
+
+// lockStock locks the product rows and then the variant rows of the items until the end of tx.
+// Rows are locked in id order, products before variants, so concurrent checkouts and variant
+// updates cannot deadlock each other.
+func lockStock(ctx context.Context, tx *sqlx.Tx, items []models.CreateOrderItem) (lockedStock, error) {
+	return loadStock(ctx, tx, items, true)
+}
+
+// loadStock reads the stock of the items, locking the rows as lockStock does when lock is set.
+func loadStock(ctx context.Context, tx *sqlx.Tx, items []models.CreateOrderItem, lock bool) (lockedStock, error) {
+	forUpdate := ""
+	if lock {
+		forUpdate = "FOR UPDATE"
+	}
+
+	var productIDs, variantIDs []int
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+
+	productQuery := `
+		SELECT p.id, COALESCE(p.stock, 0),
+			EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id)
+		FROM products p
+		WHERE p.id = ANY($1)
+		ORDER BY p.id
+		` + forUpdate
+
+	stock := lockedStock{
+		products: make(map[int]lockedProduct, len(productIDs)),
+		variants: make(map[int]lockedVariant, len(variantIDs)),
+	}
+
+	rows, err := tx.QueryContext(ctx, productQuery, pq.Array(productIDs))
+	if err != nil {
+		return lockedStock{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var product lockedProduct
+		if err := rows.Scan(&id, &product.stock, &product.hasVariants); err != nil {
+			return lockedStock{}, err
+		}
+		stock.products[id] = product
+	}
+	if err := rows.Err(); err != nil {
+		return lockedStock{}, err
+	}
+	if len(variantIDs) == 0 {
+		return stock, nil
+	}
+
+	variantQuery := `
+		SELECT id, product_id, stock, price
+		FROM product_variants
+		WHERE id = ANY($1)
+		ORDER BY id
+		` + forUpdate
+
+	variantRows, err := tx.QueryContext(ctx, variantQuery, pq.Array(variantIDs))
+	if err != nil {
+		return lockedStock{}, err
+	}
+	defer variantRows.Close()
+	for variantRows.Next() {
+		var id int
+		var variant lockedVariant
+		if err := variantRows.Scan(&id, &variant.productID, &variant.stock, &variant.price); err != nil {
+			return lockedStock{}, err
+		}
+		stock.variants[id] = variant
+	}
+
+	return stock, variantRows.Err()
+}
+
+// productTiers reads the price tiers of all the items' products with one query, keyed by product id.
+func productTiers(ctx context.Context, tx *sqlx.Tx, items []models.CreateOrderItem) (map[int][]models.Price, error) {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	query := `
+		SELECT id, product_id, count, price
+		FROM prices
+		WHERE product_id = ANY($1)`
+
+	var prices []models.Price
+	if err := tx.SelectContext(ctx, &prices, query, pq.Array(productIDs)); err != nil {
+		return nil, err
+	}
+
+	tiers := make(map[int][]models.Price, len(productIDs))
+	for _, price := range prices {
+		tiers[int(price.ProductID)] = append(tiers[int(price.ProductID)], price)
+	}
+	return tiers, nil
+}
+
 func (r *repository) GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error) {
 	orderQuery := `
-		SELECT o.id, o.user_id, o.status, o.created_at
+		SELECT o.id, o.user_id, o.status, o.total_amount, o.promo_code, o.discount_amount, o.cancel_reason, o.created_at
 		FROM orders o
 		WHERE o.id = $1`
 
@@ -85,7 +808,7 @@ func (r *repository) GetOrderByID(ctx context.Context, id int) (models.OrderWith
 	}
 
 	productsQuery := `
-		SELECT id, order_id, product_id, quantity, price
+		SELECT id, order_id, product_id, variant_id, quantity, price
 		FROM order_products
 		WHERE order_id = $1`
 
@@ -96,26 +819,67 @@ func (r *repository) GetOrderByID(ctx context.Context, id int) (models.OrderWith
 	}
 	order.Products = products
 
+	discounts, err := r.orderDiscounts(ctx, []int64{order.ID})
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+	order.Discounts = discounts[order.ID]
+
+	order.History, err = r.GetStatusHistory(ctx, id)
+	if err != nil {
+		return models.OrderWithProducts{}, err
+	}
+
 	return order, nil
 }
 
-func (r *repository) GetUserOrders(ctx context.Context, userID int64) ([]models.OrderWithProducts, error) {
-	query := `
+func (r *repository) GetUserOrders(ctx context.Context, userID int64, page pagination.Page) ([]models.OrderWithProducts, string, error) {
+	return r.listOrders(ctx, "o.user_id = $1", []interface{}{userID}, page)
+}
+
+func (r *repository) GetAll(ctx context.Context, page pagination.Page) ([]models.OrderWithProducts, string, error) {
+	return r.listOrders(ctx, "true", nil, page)
+}
+
+// listOrders returns one page of orders matching condition, newest first, with their products.
+// condition may reference args as $1..$n.
+func (r *repository) listOrders(ctx context.Context, condition string, args []interface{}, page pagination.Page) ([]models.OrderWithProducts, string, error) {
+	var position pagination.IDPosition
+	resume, err := pagination.Decode(page.Cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+	if resume {
+		args = append(args, position.ID)
+		condition += fmt.Sprintf(" AND o.id < $%d", len(args))
+	}
+	args = append(args, page.Limit+1)
+
+	// Page over orders first, then join the products, so LIMIT counts orders rather than order lines.
+	query := fmt.Sprintf(`
+		WITH page AS (
+			SELECT o.id
+			FROM orders o
+			WHERE %s
+			ORDER BY o.id DESC
+			LIMIT $%d
+		)
 		SELECT 
-			o.id, o.user_id, o.status, o.created_at,
+			o.id, o.user_id, o.status, o.total_amount, o.promo_code, o.discount_amount, o.cancel_reason, o.created_at,
 			COALESCE(op.id, 0) as product_id, 
 			COALESCE(op.order_id, 0) as product_order_id,
 			COALESCE(op.product_id, 0) as product_product_id,
+			op.variant_id as product_variant_id,
 			COALESCE(op.quantity, 0) as product_quantity,
 			COALESCE(op.price, 0) as product_price
-		FROM orders o
+		FROM page
+		JOIN orders o ON o.id = page.id
 		LEFT JOIN order_products op ON o.id = op.order_id
-		WHERE o.user_id = $1
-		ORDER BY o.created_at DESC, op.id`
+		ORDER BY o.id DESC, op.id`, condition, len(args))
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
@@ -126,26 +890,33 @@ func (r *repository) GetUserOrders(ctx context.Context, userID int64) ([]models.
 		var (
 			orderID, userID                                       int64
 			status                                                string
+			totalAmount, discountAmount                           float64
+			promoCode, cancelReason                               *string
 			createdAt                                             time.Time
 			productID, productOrderID, productProductID, quantity int
+			variantID                                             *int
 			price                                                 float64
 		)
 
 		err := rows.Scan(
-			&orderID, &userID, &status, &createdAt,
-			&productID, &productOrderID, &productProductID, &quantity, &price,
+			&orderID, &userID, &status, &totalAmount, &promoCode, &discountAmount, &cancelReason, &createdAt,
+			&productID, &productOrderID, &productProductID, &variantID, &quantity, &price,
 		)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		if _, exists := ordersMap[orderID]; !exists {
 			ordersMap[orderID] = &models.OrderWithProducts{
-				ID:        orderID,
-				UserID:    userID,
-				Status:    status,
-				CreatedAt: createdAt,
-				Products:  []models.OrderProduct{},
+				ID:             orderID,
+				UserID:         userID,
+				Status:         status,
+				TotalAmount:    totalAmount,
+				PromoCode:      promoCode,
+				DiscountAmount: discountAmount,
+				CancelReason:   cancelReason,
+				CreatedAt:      createdAt,
+				Products:       []models.OrderProduct{},
 			}
 			orderIDs = append(orderIDs, orderID)
 		}
@@ -155,85 +926,52 @@ func (r *repository) GetUserOrders(ctx context.Context, userID int64) ([]models.
 				ID:        productID,
 				OrderID:   productOrderID,
 				ProductID: productProductID,
+				VariantID: variantID,
 				Quantity:  quantity,
 				Price:     price,
 			})
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(orderIDs) > page.Limit {
+		orderIDs = orderIDs[:page.Limit]
+		next = pagination.Encode(pagination.IDPosition{ID: orderIDs[page.Limit-1]})
+	}
+
+	discounts, err := r.orderDiscounts(ctx, orderIDs)
+	if err != nil {
+		return nil, "", err
+	}
 
 	result := make([]models.OrderWithProducts, 0, len(orderIDs))
 	for _, orderID := range orderIDs {
-		result = append(result, *ordersMap[orderID])
+		order := ordersMap[orderID]
+		order.Discounts = discounts[orderID]
+		result = append(result, *order)
 	}
 
-	return result, nil
+	return result, next, nil
 }
 
-func (r *repository) GetAll(ctx context.Context) ([]models.OrderWithProducts, error) {
-	query := `
-		SELECT 
-			o.id, o.user_id, o.status, o.created_at,
-			COALESCE(op.id, 0) as product_id, 
-			COALESCE(op.order_id, 0) as product_order_id,
-			COALESCE(op.product_id, 0) as product_product_id,
-			COALESCE(op.quantity, 0) as product_quantity,
-			COALESCE(op.price, 0) as product_price
-		FROM orders o
-		LEFT JOIN order_products op ON o.id = op.order_id
-		ORDER BY o.created_at DESC, op.id`
-
-	rows, err := r.db.QueryContext(ctx, query)
+// orderDiscounts returns the discount lines of the orders, keyed by order id.
+func (r *repository) orderDiscounts(ctx context.Context, orderIDs []int64) (map[int64][]models.OrderDiscount, error) {
+	var discounts []models.OrderDiscount
+	err := r.db.SelectContext(ctx, &discounts, `
+		SELECT id, order_id, promo_code_id, code, product_id, variant_id, amount
+		FROM order_discounts
+		WHERE order_id = ANY($1)
+		ORDER BY id`, pq.Array(orderIDs))
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	ordersMap := make(map[int64]*models.OrderWithProducts)
-	var orderIDs []int64
-
-	for rows.Next() {
-		var (
-			orderID, userID                                       int64
-			status                                                string
-			createdAt                                             time.Time
-			productID, productOrderID, productProductID, quantity int
-			price                                                 float64
-		)
-
-		err := rows.Scan(
-			&orderID, &userID, &status, &createdAt,
-			&productID, &productOrderID, &productProductID, &quantity, &price,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if _, exists := ordersMap[orderID]; !exists {
-			ordersMap[orderID] = &models.OrderWithProducts{
-				ID:        orderID,
-				UserID:    userID,
-				Status:    status,
-				CreatedAt: createdAt,
-				Products:  []models.OrderProduct{},
-			}
-			orderIDs = append(orderIDs, orderID)
-		}
-
-		if productID > 0 {
-			ordersMap[orderID].Products = append(ordersMap[orderID].Products, models.OrderProduct{
-				ID:        productID,
-				OrderID:   productOrderID,
-				ProductID: productProductID,
-				Quantity:  quantity,
-				Price:     price,
-			})
-		}
-	}
 
-	result := make([]models.OrderWithProducts, 0, len(orderIDs))
-	for _, orderID := range orderIDs {
-		result = append(result, *ordersMap[orderID])
+	byOrder := make(map[int64][]models.OrderDiscount)
+	for _, d := range discounts {
+		byOrder[int64(d.OrderID)] = append(byOrder[int64(d.OrderID)], d)
 	}
-
-	return result, nil
+	return byOrder, nil
 }
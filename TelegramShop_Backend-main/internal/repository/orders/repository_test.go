@@ -0,0 +1,445 @@
+package orders_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/repository/orders"
+	"telegramshop_backend/internal/repository/prices"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/repository/promotions"
+	"telegramshop_backend/pkg/pagination"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func createPricedProduct(t *testing.T, db *sqlx.DB, stock int, tiers map[int]float64) int {
+	ctx := context.Background()
+	product, err := products.NewRepository(db).CreateProduct(ctx, models.Product{
+		Name:        "Order Test Product",
+		Description: "Order test",
+		Stock:       stock,
+	})
+	require.NoError(t, err)
+
+	for count, price := range tiers {
+		_, err := prices.NewRepository(db).CreatePrice(ctx, models.Price{
+			ProductID: product.ID,
+			Count:     count,
+			Price:     price,
+		})
+		require.NoError(t, err)
+	}
+
+	return int(product.ID)
+}
+
+func createUser(t *testing.T, db *sqlx.DB) int64 {
+	suffix := time.Now().UnixNano()
+	var id int64
+	err := db.Get(&id, `INSERT INTO users (telegram_id, username) VALUES ($1, $2) RETURNING id`, suffix, fmt.Sprintf("orders_test_%d", suffix))
+	require.NoError(t, err)
+	return id
+}
+
+// putInBasket inserts a basket line directly, bypassing the stock check of the basket
+// repository, as if the stock had dropped after the line was added.
+func putInBasket(t *testing.T, db *sqlx.DB, item models.CreateBasketItem) {
+	_, err := db.Exec(`INSERT INTO basket (user_id, product_id, variant_id, quantity) VALUES ($1, $2, $3, $4)`,
+		item.UserID, item.ProductID, item.VariantID, item.Quantity)
+	require.NoError(t, err)
+}
+
+func TestOrderRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := orders.NewRepository(db)
+	ctx := context.Background()
+
+	t.Run("CreateOrderUsesPriceTiers", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 100, map[int]float64{1: 100, 10: 90, 50: 80})
+
+		order, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items: []models.CreateOrderItem{
+				{ProductID: productID, Quantity: 12},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, order.Products, 1)
+		require.Equal(t, 90.0, order.Products[0].Price)
+		require.Equal(t, 1080.0, order.TotalAmount)
+
+		stored, err := repo.GetOrderByID(ctx, int(order.ID))
+		require.NoError(t, err)
+		require.Equal(t, order.TotalAmount, stored.TotalAmount)
+	})
+
+	t.Run("CreateOrderUnknownProduct", func(t *testing.T) {
+		_, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: -1, Quantity: 1}},
+		})
+		require.ErrorIs(t, err, orders.ErrProductNotFound)
+	})
+
+	t.Run("CreateOrderNoMatchingTier", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 100, map[int]float64{5: 50})
+
+		_, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 2}},
+		})
+		require.ErrorIs(t, err, orders.ErrPriceTierMissing)
+	})
+
+	t.Run("CreateOrderReservesStock", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 5, map[int]float64{1: 100})
+
+		_, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items: []models.CreateOrderItem{
+				{ProductID: productID, Quantity: 2},
+				{ProductID: productID, Quantity: 1},
+			},
+		})
+		require.NoError(t, err)
+
+		product, err := products.NewRepository(db).GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 2, product.Stock)
+		require.Equal(t, 3, product.SellCount)
+	})
+
+	t.Run("CreateOrderInsufficientStock", func(t *testing.T) {
+		enough := createPricedProduct(t, db, 10, map[int]float64{1: 100})
+		scarce := createPricedProduct(t, db, 1, map[int]float64{1: 100})
+
+		_, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items: []models.CreateOrderItem{
+				{ProductID: enough, Quantity: 2},
+				{ProductID: scarce, Quantity: 2},
+			},
+		})
+		var stockErr *orders.InsufficientStockError
+		require.ErrorAs(t, err, &stockErr)
+		require.Equal(t, []int{scarce}, stockErr.ProductIDs)
+
+		product, err := products.NewRepository(db).GetProductByID(ctx, int64(enough))
+		require.NoError(t, err)
+		require.Equal(t, 10, product.Stock)
+	})
+
+	t.Run("ParallelCheckoutsTakeLastUnitOnce", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 1, map[int]float64{1: 100})
+
+		const buyers = 2
+		var wg sync.WaitGroup
+		results := make(chan error, buyers)
+		for i := 0; i < buyers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.CreateOrder(ctx, models.CreateOrder{
+					UserID: 1,
+					Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 1}},
+				})
+				results <- err
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		var succeeded, rejected int
+		for err := range results {
+			var stockErr *orders.InsufficientStockError
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.As(err, &stockErr):
+				rejected++
+			default:
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		require.Equal(t, 1, succeeded)
+		require.Equal(t, 1, rejected)
+
+		product, err := products.NewRepository(db).GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 0, product.Stock)
+	})
+
+	t.Run("CheckoutDropsUnavailableItems", func(t *testing.T) {
+		basketRepo := basket.NewRepository(db)
+		require.NoError(t, basketRepo.ClearUserBasket(ctx, 1))
+
+		inStock := createPricedProduct(t, db, 10, map[int]float64{1: 100})
+		soldOut := createPricedProduct(t, db, 1, map[int]float64{1: 100})
+		wholesale := createPricedProduct(t, db, 10, map[int]float64{5: 50})
+		for _, item := range []models.CreateBasketItem{
+			{UserID: 1, ProductID: inStock, Quantity: 3},
+			{UserID: 1, ProductID: soldOut, Quantity: 2},
+			{UserID: 1, ProductID: wholesale, Quantity: 2},
+		} {
+			putInBasket(t, db, item)
+		}
+
+		checkout, err := repo.Checkout(ctx, 1, "")
+		require.NoError(t, err)
+		require.Len(t, checkout.Order.Products, 1)
+		require.Equal(t, inStock, checkout.Order.Products[0].ProductID)
+		require.Equal(t, 300.0, checkout.Order.TotalAmount)
+		require.Equal(t, []models.DroppedBasketItem{
+			{ProductID: soldOut, Quantity: 2, Available: 1, Reason: models.DropReasonOutOfStock},
+			{ProductID: wholesale, Quantity: 2, Reason: models.DropReasonPriceTierMissing},
+		}, checkout.Dropped)
+
+		items, err := basketRepo.GetUserBasket(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("CheckoutEmptyBasket", func(t *testing.T) {
+		require.NoError(t, basket.NewRepository(db).ClearUserBasket(ctx, 1))
+
+		_, err := repo.Checkout(ctx, 1, "")
+		require.ErrorIs(t, err, orders.ErrEmptyBasket)
+	})
+
+	t.Run("CancelOrderRestocks", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 5, map[int]float64{1: 100})
+
+		order, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 2}},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, repo.CancelOrder(ctx, int(order.ID), models.OrderStatusPending, 1, "ordered by mistake"))
+		require.ErrorIs(t, repo.CancelOrder(ctx, int(order.ID), models.OrderStatusPending, 1, "again"), orders.ErrStatusConflict)
+
+		stored, err := repo.GetOrderByID(ctx, int(order.ID))
+		require.NoError(t, err)
+		require.Equal(t, models.OrderStatusCancelled, stored.Status)
+		require.Equal(t, "ordered by mistake", *stored.CancelReason)
+
+		product, err := products.NewRepository(db).GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 5, product.Stock)
+		require.Equal(t, 0, product.SellCount)
+	})
+
+	t.Run("RefundRestocks", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 5, map[int]float64{1: 100})
+
+		order, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 2}},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, repo.UpdateStatus(ctx, int(order.ID), models.OrderStatusPending, models.OrderStatusPaid, 1, ""))
+		require.NoError(t, repo.UpdateStatus(ctx, int(order.ID), models.OrderStatusPaid, models.OrderStatusRefunded, 1, "damaged in transit"))
+
+		product, err := products.NewRepository(db).GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 5, product.Stock)
+		require.Equal(t, 0, product.SellCount)
+	})
+
+	t.Run("VariantsHaveOwnStockAndPrice", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 0, map[int]float64{1: 100})
+		productsRepo := products.NewRepository(db)
+		suffix := time.Now().UnixNano()
+		override := 120.0
+		size42, err := productsRepo.CreateVariant(ctx, int64(productID), models.ProductVariantInput{
+			SKU: fmt.Sprintf("TEST-42-%d", suffix), Attributes: map[string]interface{}{"size": "42"}, Stock: 3,
+		})
+		require.NoError(t, err)
+		size43, err := productsRepo.CreateVariant(ctx, int64(productID), models.ProductVariantInput{
+			SKU: fmt.Sprintf("TEST-43-%d", suffix), Attributes: map[string]interface{}{"size": "43"}, Stock: 1, Price: &override,
+		})
+		require.NoError(t, err)
+
+		product, err := productsRepo.GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 4, product.Stock)
+
+		_, err = repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 1}},
+		})
+		require.ErrorIs(t, err, orders.ErrVariantRequired)
+
+		id42, id43 := int(size42.ID), int(size43.ID)
+		order, err := repo.CreateOrder(ctx, models.CreateOrder{
+			UserID: 1,
+			Items: []models.CreateOrderItem{
+				{ProductID: productID, VariantID: &id42, Quantity: 2},
+				{ProductID: productID, VariantID: &id43, Quantity: 1},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, order.Products, 2)
+		require.Equal(t, 320.0, order.TotalAmount)
+
+		product, err = productsRepo.GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 1, product.Stock)
+		require.ErrorIs(t, productsRepo.UpdateStock(ctx, int64(productID), 10), products.ErrStockFromVariants)
+
+		require.NoError(t, repo.CancelOrder(ctx, int(order.ID), models.OrderStatusPending, 1, ""))
+		product, err = productsRepo.GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 4, product.Stock)
+		require.Equal(t, 0, product.SellCount)
+	})
+
+	t.Run("PromoCodeDiscountsAndLimits", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 10, map[int]float64{1: 100})
+		userID := createUser(t, db)
+		one := 1
+		active := true
+		code, err := promotions.NewRepository(db).CreatePromoCode(ctx, models.PromoCodeInput{
+			Code:           fmt.Sprintf("TEST%d", productID),
+			Kind:           models.PromoKindPercent,
+			Value:          10,
+			MinOrderTotal:  200,
+			MaxUsesPerUser: &one,
+			Active:         &active,
+		})
+		require.NoError(t, err)
+		defer promotions.NewRepository(db).DeletePromoCode(ctx, code.ID)
+
+		basketRepo := basket.NewRepository(db)
+		putInBasket(t, db, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 1})
+		var promoErr *promotions.PromoCodeError
+		_, err = repo.PreviewBasket(ctx, userID, code.Code)
+		require.ErrorAs(t, err, &promoErr)
+		require.Equal(t, models.PromoReasonMinOrderTotal, promoErr.Reason)
+
+		require.NoError(t, basketRepo.ClearUserBasket(ctx, userID))
+		putInBasket(t, db, models.CreateBasketItem{UserID: userID, ProductID: productID, Quantity: 3})
+		quote, err := repo.PreviewBasket(ctx, userID, strings.ToLower(code.Code))
+		require.NoError(t, err)
+		require.Equal(t, 300.0, quote.Subtotal)
+		require.Equal(t, 30.0, quote.DiscountAmount)
+		require.Equal(t, 270.0, quote.TotalAmount)
+
+		checkout, err := repo.Checkout(ctx, userID, code.Code)
+		require.NoError(t, err)
+		require.Equal(t, 270.0, checkout.Order.TotalAmount)
+		require.Equal(t, 30.0, checkout.Order.DiscountAmount)
+		require.Equal(t, code.Code, *checkout.Order.PromoCode)
+		require.Len(t, checkout.Order.Discounts, 1)
+
+		stored, err := repo.GetOrderByID(ctx, int(checkout.Order.ID))
+		require.NoError(t, err)
+		require.Equal(t, checkout.Order.Discounts, stored.Discounts)
+
+		input := models.CreateOrder{
+			UserID:    userID,
+			Items:     []models.CreateOrderItem{{ProductID: productID, Quantity: 2}},
+			PromoCode: code.Code,
+		}
+		_, err = repo.CreateOrder(ctx, input)
+		require.ErrorAs(t, err, &promoErr)
+		require.Equal(t, models.PromoReasonUserLimit, promoErr.Reason)
+
+		// A cancelled order gives the use back.
+		require.NoError(t, repo.CancelOrder(ctx, int(checkout.Order.ID), models.OrderStatusPending, userID, ""))
+		order, err := repo.CreateOrder(ctx, input)
+		require.NoError(t, err)
+		require.Equal(t, 180.0, order.TotalAmount)
+	})
+
+	t.Run("CreateOrderIdempotent", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 10, map[int]float64{1: 100})
+		input := models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 1}},
+		}
+		key := fmt.Sprintf("test-%d", productID)
+
+		first, replayed, err := repo.CreateOrderIdempotent(ctx, input, key, "hash-a", time.Hour)
+		require.NoError(t, err)
+		require.False(t, replayed)
+
+		second, replayed, err := repo.CreateOrderIdempotent(ctx, input, key, "hash-a", time.Hour)
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, first.ID, second.ID)
+
+		_, _, err = repo.CreateOrderIdempotent(ctx, input, key, "hash-b", time.Hour)
+		require.ErrorIs(t, err, orders.ErrIdempotencyKeyReused)
+
+		product, err := products.NewRepository(db).GetProductByID(ctx, int64(productID))
+		require.NoError(t, err)
+		require.Equal(t, 9, product.Stock)
+	})
+
+	t.Run("ExpiredIdempotencyKeyCreatesNewOrder", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 10, map[int]float64{1: 100})
+		input := models.CreateOrder{
+			UserID: 1,
+			Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 1}},
+		}
+		key := fmt.Sprintf("test-%d", productID)
+
+		first, _, err := repo.CreateOrderIdempotent(ctx, input, key, "hash-a", 0)
+		require.NoError(t, err)
+
+		second, replayed, err := repo.CreateOrderIdempotent(ctx, input, key, "hash-a", time.Hour)
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.NotEqual(t, first.ID, second.ID)
+	})
+
+	t.Run("UserOrdersPagination", func(t *testing.T) {
+		productID := createPricedProduct(t, db, 10, map[int]float64{1: 100})
+		userID := createUser(t, db)
+
+		var created []int64
+		for i := 0; i < 3; i++ {
+			order, err := repo.CreateOrder(ctx, models.CreateOrder{
+				UserID: userID,
+				Items:  []models.CreateOrderItem{{ProductID: productID, Quantity: 1}},
+			})
+			require.NoError(t, err)
+			created = append(created, order.ID)
+		}
+
+		first, next, err := repo.GetUserOrders(ctx, userID, pagination.Page{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		require.Equal(t, created[2], first[0].ID)
+		require.Equal(t, created[1], first[1].ID)
+		require.Len(t, first[0].Products, 1)
+		require.NotEmpty(t, next)
+
+		second, next, err := repo.GetUserOrders(ctx, userID, pagination.Page{Cursor: next, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		require.Equal(t, created[0], second[0].ID)
+		require.Empty(t, next)
+
+		_, _, err = repo.GetUserOrders(ctx, userID, pagination.Page{Cursor: "garbage!", Limit: 2})
+		require.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	})
+}
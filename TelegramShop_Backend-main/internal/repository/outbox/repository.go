@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrEventNotFound = errors.New("outbox event not found")
+
+type Repository interface {
+	// Claim leases up to limit due events. A claimed event is hidden from other dispatchers
+	// until lease expires, so an event whose dispatcher crashed is picked up again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkDone(ctx context.Context, id int64) error
+	// MarkFailed records a failed attempt and schedules the next one retryAfter from now by the
+	// database clock, or moves the event to the dead state when dead is set.
+	MarkFailed(ctx context.Context, id int64, lastError string, retryAfter time.Duration, dead bool) error
+	List(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error)
+	// Replay resets a dead event so the dispatcher delivers it again.
+	Replay(ctx context.Context, id int64) error
+	// CatalogVersion returns the id of the latest catalog.changed or product.updated event.
+	// Every instance polls it, since the outbox delivers each event to one instance only.
+	CatalogVersion(ctx context.Context) (int64, error)
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+// Insert writes an event inside tx, so it is stored only if the change it describes is committed.
+func Insert(ctx context.Context, tx *sqlx.Tx, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox (event_type, payload) VALUES ($1, $2)`, eventType, string(data))
+	return err
+}
+
+// InsertCatalogEvent records inside tx that a product, firm or category was created, renamed or deleted.
+func InsertCatalogEvent(ctx context.Context, tx *sqlx.Tx, entity string, id int64) error {
+	return Insert(ctx, tx, models.EventCatalogChanged, models.CatalogEvent{Entity: entity, ID: id})
+}
+
+// InTx runs fn in a transaction that is committed only if fn succeeds.
+// Repositories use it to store a change together with its Insert-ed events.
+func InTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const eventColumns = `id, event_type, payload, status, attempts, last_error, available_at, created_at, processed_at`
+
+func (r *repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	query := `
+		UPDATE outbox
+		SET available_at = current_timestamp + $2 * interval '1 second',
+			attempts = attempts + 1
+		WHERE id IN (
+			SELECT id
+			FROM outbox
+			WHERE status = 'pending' AND available_at <= current_timestamp
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + eventColumns
+
+	var events []models.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, limit, lease.Seconds()); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the subquery order.
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (r *repository) MarkDone(ctx context.Context, id int64) error {
+	query := `
+		UPDATE outbox
+		SET status = 'done', last_error = '', processed_at = current_timestamp
+		WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *repository) MarkFailed(ctx context.Context, id int64, lastError string, retryAfter time.Duration, dead bool) error {
+	status := models.OutboxStatusPending
+	if dead {
+		status = models.OutboxStatusDead
+	}
+
+	query := `
+		UPDATE outbox
+		SET status = $1, last_error = $2, available_at = current_timestamp + $3 * interval '1 second'
+		WHERE id = $4`
+
+	_, err := r.db.ExecContext(ctx, query, status, lastError, retryAfter.Seconds(), id)
+	return err
+}
+
+func (r *repository) List(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM outbox
+		WHERE $1 = '' OR status = $1
+		ORDER BY id DESC
+		LIMIT $2`
+
+	var events []models.OutboxEvent
+	err := r.db.SelectContext(ctx, &events, query, status, limit)
+	return events, err
+}
+
+func (r *repository) Replay(ctx context.Context, id int64) error {
+	query := `
+		UPDATE outbox
+		SET status = 'pending', attempts = 0, available_at = current_timestamp, processed_at = NULL
+		WHERE id = $1 AND status = 'dead'`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrEventNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) CatalogVersion(ctx context.Context) (int64, error) {
+	// The predicate matches idx_outbox_catalog_version, so this reads one index entry.
+	query := `
+		SELECT COALESCE(MAX(id), 0)
+		FROM outbox
+		WHERE event_type IN ('` + models.EventCatalogChanged + `', '` + models.EventProductUpdated + `')`
+
+	var version int64
+	err := r.db.GetContext(ctx, &version, query)
+	return version, err
+}
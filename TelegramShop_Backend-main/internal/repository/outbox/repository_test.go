@@ -0,0 +1,88 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func TestOutboxRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := outbox.NewRepository(db)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `DELETE FROM outbox`)
+	require.NoError(t, err)
+
+	require.NoError(t, outbox.InTx(ctx, db, func(tx *sqlx.Tx) error {
+		return outbox.Insert(ctx, tx, models.EventPriceChanged, models.PriceEvent{ProductID: 1})
+	}))
+
+	t.Run("ClaimLeasesEvent", func(t *testing.T) {
+		events, err := repo.Claim(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, 1, events[0].Attempts)
+		require.JSONEq(t, `{"product_id":1}`, string(events[0].Payload))
+
+		again, err := repo.Claim(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Empty(t, again)
+	})
+
+	t.Run("DeadAndReplay", func(t *testing.T) {
+		events, err := repo.List(ctx, models.OutboxStatusPending, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		id := events[0].ID
+
+		require.NoError(t, repo.MarkFailed(ctx, id, "boom", 0, true))
+		dead, err := repo.List(ctx, models.OutboxStatusDead, 10)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+
+		require.NoError(t, repo.Replay(ctx, id))
+		require.ErrorIs(t, repo.Replay(ctx, id), outbox.ErrEventNotFound)
+
+		events, err = repo.Claim(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		require.NoError(t, repo.MarkDone(ctx, id))
+		done, err := repo.List(ctx, models.OutboxStatusDone, 10)
+		require.NoError(t, err)
+		require.Len(t, done, 1)
+	})
+
+	t.Run("CatalogVersion", func(t *testing.T) {
+		before, err := repo.CatalogVersion(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, outbox.InTx(ctx, db, func(tx *sqlx.Tx) error {
+			return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityFirm, 1)
+		}))
+		after, err := repo.CatalogVersion(ctx)
+		require.NoError(t, err)
+		require.Greater(t, after, before)
+
+		require.NoError(t, outbox.InTx(ctx, db, func(tx *sqlx.Tx) error {
+			return outbox.Insert(ctx, tx, models.EventPriceChanged, models.PriceEvent{ProductID: 1})
+		}))
+		unchanged, err := repo.CatalogVersion(ctx)
+		require.NoError(t, err)
+		require.Equal(t, after, unchanged, "other events do not change the catalog version")
+	})
+}
@@ -0,0 +1,54 @@
+package payments
+
+import (
+	"context"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// CreatePayment stores the payment and reports false if a payment with the same
+	// Telegram charge ID was already stored. A zero OrderID is stored as no order.
+	CreatePayment(ctx context.Context, payment models.Payment) (bool, error)
+	GetPaymentsByOrderID(ctx context.Context, orderID int64) ([]models.Payment, error)
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreatePayment(ctx context.Context, payment models.Payment) (bool, error) {
+	query := `
+		INSERT INTO payments (order_id, currency, total_amount, telegram_payment_charge_id, provider_payment_charge_id, mismatch)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6)
+		ON CONFLICT (telegram_payment_charge_id) DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, query,
+		payment.OrderID, payment.Currency, payment.TotalAmount,
+		payment.TelegramPaymentChargeID, payment.ProviderPaymentChargeID, payment.Mismatch,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := res.RowsAffected()
+	return inserted > 0, err
+}
+
+func (r *repository) GetPaymentsByOrderID(ctx context.Context, orderID int64) ([]models.Payment, error) {
+	query := `
+		SELECT id, order_id, currency, total_amount, telegram_payment_charge_id, provider_payment_charge_id, mismatch, created_at
+		FROM payments
+		WHERE order_id = $1
+		ORDER BY id`
+
+	var payments []models.Payment
+	err := r.db.SelectContext(ctx, &payments, query, orderID)
+	return payments, err
+}
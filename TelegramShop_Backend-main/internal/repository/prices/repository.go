@@ -3,16 +3,21 @@ package prices
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	CreatePrice(ctx context.Context, price models.Price) (models.Price, error)
 	GetPriceByID(ctx context.Context, id int64) (models.Price, error)
 	GetPricesByProductID(ctx context.Context, productID int64) ([]models.Price, error)
+	// GetPricesByProductIDs returns the tiers of all the given products in one query.
+	GetPricesByProductIDs(ctx context.Context, productIDs []int64) ([]models.Price, error)
 	UpdatePrice(ctx context.Context, id int64, price models.UpdatePriceInput) error
 	DeletePrice(ctx context.Context, id int64) error
 	DeletePricesByProductID(ctx context.Context, productID int64) error
@@ -33,7 +38,12 @@ func (r *repository) CreatePrice(ctx context.Context, price models.Price) (model
 		VALUES ($1, $2, $3)
 		RETURNING id`
 
-	err := r.db.QueryRowContext(ctx, query, price.ProductID, price.Count, price.Price).Scan(&price.ID)
+	err := outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, price.ProductID, price.Count, price.Price).Scan(&price.ID); err != nil {
+			return err
+		}
+		return outbox.Insert(ctx, tx, models.EventPriceChanged, models.PriceEvent{ProductID: price.ProductID, PriceID: price.ID})
+	})
 	return price, err
 }
 
@@ -62,32 +72,62 @@ func (r *repository) GetPricesByProductID(ctx context.Context, productID int64)
 	return prices, err
 }
 
+func (r *repository) GetPricesByProductIDs(ctx context.Context, productIDs []int64) ([]models.Price, error) {
+	query := `
+		SELECT id, product_id, count, price
+		FROM prices
+		WHERE product_id = ANY($1)`
+
+	var prices []models.Price
+	err := r.db.SelectContext(ctx, &prices, query, pq.Array(productIDs))
+	return prices, err
+}
+
 func (r *repository) UpdatePrice(ctx context.Context, id int64, price models.UpdatePriceInput) error {
 	query := `
 		UPDATE prices
 		SET price = $1, count = $2
-		WHERE id = $3`
+		WHERE id = $3
+		RETURNING product_id`
 
-	_, err := r.db.ExecContext(ctx, query, price.Price, price.Count, id)
-	return err
+	return r.changePrice(ctx, id, query, price.Price, price.Count, id)
 }
 
 func (r *repository) DeletePrice(ctx context.Context, id int64) error {
-	query := `DELETE FROM prices WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	query := `DELETE FROM prices WHERE id = $1 RETURNING product_id`
+	return r.changePrice(ctx, id, query, id)
 }
 
 func (r *repository) DeletePricesByProductID(ctx context.Context, productID int64) error {
 	query := `DELETE FROM prices WHERE product_id = $1`
-	_, err := r.db.ExecContext(ctx, query, productID)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, productID); err != nil {
+			return err
+		}
+		return outbox.Insert(ctx, tx, models.EventPriceChanged, models.PriceEvent{ProductID: productID})
+	})
 }
 func (r *repository) UpdatePriceCount(ctx context.Context, id int64, newCount int) error {
 	query := `
 		UPDATE prices
 		SET count = $1
-		WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, newCount, id)
-	return err
+		WHERE id = $2
+		RETURNING product_id`
+	return r.changePrice(ctx, id, query, newCount, id)
+}
+
+// changePrice runs a single-row price query returning product_id and records a price.changed event.
+// A missing row is not an error, matching the previous behaviour of these methods.
+func (r *repository) changePrice(ctx context.Context, id int64, query string, args ...interface{}) error {
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var productID int64
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return outbox.Insert(ctx, tx, models.EventPriceChanged, models.PriceEvent{ProductID: productID, PriceID: id})
+	})
 }
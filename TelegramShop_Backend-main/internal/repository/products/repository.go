@@ -5,16 +5,35 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/pkg/pagination"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
+var ErrUnknownSort = errors.New("unknown sort key")
+
 type Repository interface {
 	CreateProduct(ctx context.Context, product models.Product) (models.Product, error)
 	GetProductByID(ctx context.Context, id int64) (models.Product, error)
-	GetAllProducts(ctx context.Context) ([]models.Product, error)
+	// ListProducts returns one page of products matching the filter and the cursor of the next page,
+	// empty on the last one.
+	ListProducts(ctx context.Context, filter models.ProductFilter, page pagination.Page) ([]models.Product, string, error)
+	// SearchFullText ranks products whose search document matches the websearch-style query.
+	SearchFullText(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error)
+	// SearchFuzzy ranks products by trigram similarity of the text to their name, tolerating typos.
+	SearchFuzzy(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error)
+	GetProductSales(ctx context.Context) ([]models.ProductSales, error)
+	// GetFacets counts products per attribute value, within a category and its
+	// subcategories when categoryID is set.
+	GetFacets(ctx context.Context, categoryID *int64) ([]models.ProductFacet, error)
 	UpdateProduct(ctx context.Context, id int64, product models.UpdateProductInput) error
 	DeleteProduct(ctx context.Context, id int64) error
 	AddProductImage(ctx context.Context, id int64, imageURL string) error
@@ -22,7 +41,17 @@ type Repository interface {
 	SetProductImages(ctx context.Context, id int64, images []string) error
 
 	IncrementSellCount(ctx context.Context, productID int64, count int) error
+	// UpdateStock fails with ErrStockFromVariants for a product with variants.
 	UpdateStock(ctx context.Context, productID int64, stock int) error
+
+	GetVariants(ctx context.Context, productID int64) ([]models.ProductVariant, error)
+	// GetVariantsByProductIDs returns the variants of all the given products in one query.
+	GetVariantsByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductVariant, error)
+	GetVariantByID(ctx context.Context, id int64) (models.ProductVariant, error)
+	// CreateVariant, UpdateVariant and DeleteVariant recompute the product stock from its variants.
+	CreateVariant(ctx context.Context, productID int64, input models.ProductVariantInput) (models.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, id int64, input models.ProductVariantInput) (models.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, id int64) error
 }
 
 type repository struct {
@@ -33,9 +62,10 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func scanProductRow(row *sqlx.Row, product *models.Product) error {
+// scanProduct reads the product columns, in the order of productColumns, followed by extra.
+func scanProduct(row interface{ Scan(...interface{}) error }, product *models.Product, extra ...interface{}) error {
 	var attrs []byte
-	err := row.Scan(
+	dest := append([]interface{}{
 		&product.ID,
 		&product.Name,
 		&product.FirmID,
@@ -45,15 +75,25 @@ func scanProductRow(row *sqlx.Row, product *models.Product) error {
 		&product.SellCount,
 		&product.Stock,
 		&product.Image,
-	)
-	if err != nil {
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
 		return err
 	}
 	return json.Unmarshal(attrs, &product.Attributes)
 }
 
+// marshalAttributes encodes attributes for the jsonb column. It returns a string because
+// lib/pq sends []byte as bytea, and stores missing attributes as an empty object.
+func marshalAttributes(attributes map[string]interface{}) (string, error) {
+	if attributes == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(attributes)
+	return string(data), err
+}
+
 func (r *repository) CreateProduct(ctx context.Context, product models.Product) (models.Product, error) {
-	attrs, err := json.Marshal(product.Attributes)
+	attrs, err := marshalAttributes(product.Attributes)
 	if err != nil {
 		return models.Product{}, err
 	}
@@ -63,16 +103,25 @@ func (r *repository) CreateProduct(ctx context.Context, product models.Product)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id`
 
-	err = r.db.QueryRowContext(ctx, query,
-		product.Name,
-		product.FirmID,
-		product.Description,
-		product.CategoryID,
-		attrs,
-		product.SellCount,
-		product.Stock,
-		product.Image,
-	).Scan(&product.ID)
+	err = outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowContext(ctx, query,
+			product.Name,
+			product.FirmID,
+			product.Description,
+			product.CategoryID,
+			attrs,
+			product.SellCount,
+			product.Stock,
+			product.Image,
+		).Scan(&product.ID)
+		if err != nil {
+			return err
+		}
+		if err := RefreshSearchVectors(ctx, tx, "p.id = $1", product.ID); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityProduct, product.ID)
+	})
 
 	return product, err
 }
@@ -86,53 +135,228 @@ func (r *repository) GetProductByID(ctx context.Context, id int64) (models.Produ
 	var product models.Product
 	row := r.db.QueryRowxContext(ctx, query, id)
 
-	err := scanProductRow(row, &product)
+	err := scanProduct(row, &product)
 	if errors.Is(err, sql.ErrNoRows) {
 		return models.Product{}, nil
 	}
 	return product, err
 }
 
-func (r *repository) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+// categorySubtree selects the category given by the %d-th parameter and all its descendants.
+const categorySubtree = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM categories WHERE id = $%d
+		UNION
+		SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+	)
+	SELECT id FROM subtree`
+
+// productSorts maps each sort key to its ordering expression and keyset direction.
+// Every ordering breaks ties by id in the same direction, so (value, id) identifies a position.
+var productSorts = map[string]struct {
+	expr string
+	desc bool
+}{
+	models.ProductSortNewest:     {expr: `p.id::float8`, desc: true},
+	models.ProductSortPriceAsc:   {expr: `COALESCE(pr.price, 0)::float8`},
+	models.ProductSortPriceDesc:  {expr: `COALESCE(pr.price, 0)::float8`, desc: true},
+	models.ProductSortPopularity: {expr: `COALESCE(p.sell_count, 0)::float8`, desc: true},
+	models.ProductSortRating:     {expr: `COALESCE(am.sum / NULLIF(am.count, 0), 0)::float8`, desc: true},
+}
+
+// productPosition is the cursor of the product listing.
+type productPosition struct {
+	Sort  string  `json:"s"`
+	Value float64 `json:"v"`
+	ID    int64   `json:"id"`
+}
+
+func (r *repository) ListProducts(ctx context.Context, filter models.ProductFilter, page pagination.Page) ([]models.Product, string, error) {
+	if filter.Sort == "" {
+		filter.Sort = models.ProductSortNewest
+	}
+	sort, ok := productSorts[filter.Sort]
+	if !ok {
+		return nil, "", ErrUnknownSort
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.CategoryID != nil {
+		where("p.category_id IN ("+categorySubtree+")", *filter.CategoryID)
+	}
+	if filter.FirmID != nil {
+		where("p.firm_id = $%d", *filter.FirmID)
+	}
+	if filter.MinPrice != nil {
+		where("pr.price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		where("pr.price <= $%d", *filter.MaxPrice)
+	}
+	if filter.InStock {
+		conditions = append(conditions, "p.stock > 0")
+	}
+	// Every alternative is a containment check served by the GIN index.
+	for _, key := range slices.Sorted(maps.Keys(filter.Attributes)) {
+		matches, err := attributeMatches(key, filter.Attributes[key])
+		if err != nil {
+			return nil, "", err
+		}
+		alternatives := make([]string, 0, len(matches))
+		for _, match := range matches {
+			args = append(args, match)
+			alternatives = append(alternatives, fmt.Sprintf("p.attributes @> $%d::jsonb", len(args)))
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	var position productPosition
+	resume, err := pagination.Decode(page.Cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+	if resume {
+		if position.Sort != filter.Sort {
+			return nil, "", pagination.ErrInvalidCursor
+		}
+		op := ">"
+		if sort.desc {
+			op = "<"
+		}
+		args = append(args, position.Value, position.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, p.id) %s ($%d, $%d)", sort.expr, op, len(args)-1, len(args)))
+	}
+
 	query := `
-		SELECT id, name, firm_id, description, category_id, attributes, sell_count, stock, image
-		FROM products`
+		SELECT ` + productColumns + `,
+			` + sort.expr + ` AS sort_value
+		FROM products p
+		LEFT JOIN LATERAL (
+			SELECT price FROM prices WHERE product_id = p.id ORDER BY count LIMIT 1
+		) pr ON true
+		LEFT JOIN avg_marks am ON am.product_id = p.id`
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	direction := "ASC"
+	if sort.desc {
+		direction = "DESC"
+	}
+	args = append(args, page.Limit+1)
+	query += fmt.Sprintf(`
+		ORDER BY sort_value %s, p.id %s
+		LIMIT $%d`, direction, direction, len(args))
 
-	rows, err := r.db.QueryxContext(ctx, query)
+	rows, err := r.db.QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
-	var products []models.Product
+	var (
+		products []models.Product
+		values   []float64
+	)
 	for rows.Next() {
 		var p models.Product
-		var attrs []byte
-		err := rows.Scan(
-			&p.ID,
-			&p.Name,
-			&p.FirmID,
-			&p.Description,
-			&p.CategoryID,
-			&attrs,
-			&p.SellCount,
-			&p.Stock,
-			&p.Image,
-		)
+		var value float64
+		if err := scanProduct(rows, &p, &value); err != nil {
+			return nil, "", err
+		}
+		products = append(products, p)
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(products) > page.Limit {
+		products = products[:page.Limit]
+		last := page.Limit - 1
+		next = pagination.Encode(productPosition{Sort: filter.Sort, Value: values[last], ID: products[last].ID})
+	}
+
+	return products, next, nil
+}
+
+// attributeMatches returns the JSON objects an attr.<key>=<value> filter matches. Facets list
+// every value as text, so a value that reads as a number or a boolean also matches that type.
+func attributeMatches(key, value string) ([]string, error) {
+	values := []interface{}{value}
+	if value == "true" || value == "false" {
+		values = append(values, value == "true")
+	} else if _, err := strconv.ParseFloat(value, 64); err == nil && json.Valid([]byte(value)) {
+		values = append(values, json.RawMessage(value))
+	}
+
+	matches := make([]string, 0, len(values))
+	for _, v := range values {
+		data, err := json.Marshal(map[string]interface{}{key: v})
 		if err != nil {
 			return nil, err
 		}
-		if err := json.Unmarshal(attrs, &p.Attributes); err != nil {
+		matches = append(matches, string(data))
+	}
+	return matches, nil
+}
+
+func (r *repository) GetProductSales(ctx context.Context) ([]models.ProductSales, error) {
+	query := `
+		SELECT id, name, COALESCE(firm_id, 0) AS firm_id, COALESCE(category_id, 0) AS category_id,
+			COALESCE(sell_count, 0) AS sell_count
+		FROM products`
+
+	var sales []models.ProductSales
+	err := r.db.SelectContext(ctx, &sales, query)
+	return sales, err
+}
+
+func (r *repository) GetFacets(ctx context.Context, categoryID *int64) ([]models.ProductFacet, error) {
+	query := `
+		SELECT kv.key, kv.value #>> '{}' AS value, count(*) AS count
+		FROM products p
+		CROSS JOIN LATERAL jsonb_each(p.attributes) kv
+		WHERE jsonb_typeof(p.attributes) = 'object'
+			AND ($1::int IS NULL OR p.category_id IN (` + fmt.Sprintf(categorySubtree, 1) + `))
+			AND jsonb_typeof(kv.value) IN ('string', 'number', 'boolean')
+		GROUP BY 1, 2
+		ORDER BY 1, 3 DESC, 2`
+
+	rows, err := r.db.QueryxContext(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facets []models.ProductFacet
+	for rows.Next() {
+		var key string
+		var value models.FacetValue
+		if err := rows.Scan(&key, &value.Value, &value.Count); err != nil {
 			return nil, err
 		}
-		products = append(products, p)
+		if len(facets) == 0 || facets[len(facets)-1].Key != key {
+			facets = append(facets, models.ProductFacet{Key: key})
+		}
+		last := &facets[len(facets)-1]
+		last.Values = append(last.Values, value)
 	}
 
-	return products, nil
+	return facets, rows.Err()
 }
 
 func (r *repository) UpdateProduct(ctx context.Context, id int64, product models.UpdateProductInput) error {
-	attrs, err := json.Marshal(product.Attributes)
+	attrs, err := marshalAttributes(product.Attributes)
 	if err != nil {
 		return err
 	}
@@ -144,26 +368,48 @@ func (r *repository) UpdateProduct(ctx context.Context, id int64, product models
 			description = $3,
 			category_id = $4,
 			attributes = $5,
-			stock = $6,
+			stock = CASE WHEN EXISTS (SELECT 1 FROM product_variants WHERE product_id = $8) THEN stock ELSE $6 END,
 			image = $7
-		WHERE id = $8`
-
-	_, err = r.db.ExecContext(ctx, query,
-		product.Name,
-		product.FirmID,
-		product.Description,
-		product.CategoryID,
-		attrs,
-		product.Stock,
-		product.Image,
-		id,
-	)
-	return err
+		WHERE id = $8
+		RETURNING stock`
+
+	// The stock of a product with variants is derived from them, so the input value is ignored.
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		prevStock, err := LockStock(ctx, tx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var stock int
+		err = tx.QueryRowContext(ctx, query,
+			product.Name,
+			product.FirmID,
+			product.Description,
+			product.CategoryID,
+			attrs,
+			product.Stock,
+			product.Image,
+			id,
+		).Scan(&stock)
+		if err != nil {
+			return err
+		}
+		if err := RefreshSearchVectors(ctx, tx, "p.id = $1", id); err != nil {
+			return err
+		}
+		return outbox.Insert(ctx, tx, models.EventProductUpdated, models.ProductEvent{ProductID: id, PrevStock: &prevStock, Stock: &stock})
+	})
 }
 
 func (r *repository) DeleteProduct(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, id)
-	return err
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return outbox.InsertCatalogEvent(ctx, tx, models.CatalogEntityProduct, id)
+	})
 }
 
 func (r *repository) AddProductImage(ctx context.Context, id int64, imageURL string) error {
@@ -193,6 +439,28 @@ func (r *repository) IncrementSellCount(ctx context.Context, productID int64, co
 }
 
 func (r *repository) UpdateStock(ctx context.Context, productID int64, stock int) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE products SET stock = $1 WHERE id = $2`, stock, productID)
-	return err
+	query := `
+		UPDATE products SET stock = $1
+		WHERE id = $2 AND NOT EXISTS (SELECT 1 FROM product_variants WHERE product_id = $2)`
+
+	return outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		prevStock, err := LockStock(ctx, tx, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, query, stock, productID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			// The product row is locked, so only its variants can have kept it from changing.
+			return ErrStockFromVariants
+		}
+		return outbox.Insert(ctx, tx, models.EventProductStockChanged, models.ProductEvent{ProductID: productID, PrevStock: &prevStock, Stock: &stock})
+	})
 }
@@ -0,0 +1,90 @@
+package products
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// searchVector is the weighted search document of the product row p:
+// name (A), firm and category names (B), description (C).
+const searchVector = `
+	setweight(to_tsvector('russian', coalesce(p.name, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce((SELECT name FROM firms WHERE id = p.firm_id), '')), 'B') ||
+	setweight(to_tsvector('russian', coalesce((SELECT name FROM categories WHERE id = p.category_id), '')), 'B') ||
+	setweight(to_tsvector('russian', coalesce(p.description, '')), 'C')`
+
+// RefreshSearchVectors recomputes the search document of the products matching condition,
+// which may reference args as $1..$n. The firms and categories repositories call it after a
+// rename, since their names are part of the document.
+func RefreshSearchVectors(ctx context.Context, db sqlx.ExecerContext, condition string, args ...interface{}) error {
+	_, err := db.ExecContext(ctx, `UPDATE products p SET search_vector = `+searchVector+` WHERE `+condition, args...)
+	return err
+}
+
+const productColumns = `p.id, p.name, p.firm_id, p.description, p.category_id, p.attributes, p.sell_count, p.stock, p.image`
+
+func (r *repository) SearchFullText(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error) {
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query)
+		SELECT ` + productColumns + `,
+			ts_rank_cd(p.search_vector, q.query) AS rank,
+			ts_headline('russian', p.name || '. ' || p.description, q.query,
+				'StartSel=<mark>, StopSel=</mark>, MinWords=5, MaxWords=20, MaxFragments=2') AS snippet
+		FROM products p, q
+		WHERE p.search_vector @@ q.query
+		ORDER BY rank DESC, p.id
+		LIMIT $2`
+
+	return r.search(ctx, query, text, limit)
+}
+
+func (r *repository) SearchFuzzy(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error) {
+	// <% matches when the text is similar to some part of the name, so one mistyped word still
+	// finds "Кроссовки Air Max". It is served by the trigram index on name.
+	query := `
+		SELECT ` + productColumns + `,
+			word_similarity($1, p.name) AS rank,
+			p.name AS snippet
+		FROM products p
+		WHERE $1 <% p.name
+		ORDER BY rank DESC, p.id
+		LIMIT $2`
+
+	results, err := r.search(ctx, query, text, limit)
+	for i := range results {
+		results[i].Fuzzy = true
+	}
+	return results, err
+}
+
+// escapeSnippet makes a ts_headline result safe to render as HTML: product text is escaped
+// and only the <mark> tags added by ts_headline are kept.
+func escapeSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>").Replace(escaped)
+}
+
+func (r *repository) search(ctx context.Context, query, text string, limit int) ([]models.ProductSearchResult, error) {
+	rows, err := r.db.QueryxContext(ctx, query, text, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.ProductSearchResult
+	for rows.Next() {
+		var result models.ProductSearchResult
+		if err := scanProduct(rows, &result.Product, &result.Rank, &result.Snippet); err != nil {
+			return nil, err
+		}
+		result.Snippet = escapeSnippet(result.Snippet)
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
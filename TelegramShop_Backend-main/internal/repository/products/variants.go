@@ -0,0 +1,190 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrVariantNotFound     = errors.New("product variant not found")
+	ErrDuplicateSKU        = errors.New("sku is already used by another variant")
+	ErrStockFromVariants   = errors.New("stock of a product with variants is the sum of its variants")
+	errUniqueViolationCode = pq.ErrorCode("23505")
+)
+
+const variantColumns = `v.id, v.product_id, v.sku, v.attributes, v.stock, v.price, v.created_at`
+
+func scanVariant(row interface{ Scan(...interface{}) error }, variant *models.ProductVariant) error {
+	var attrs []byte
+	err := row.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &attrs, &variant.Stock, &variant.Price, &variant.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(attrs, &variant.Attributes)
+}
+
+func (r *repository) GetVariants(ctx context.Context, productID int64) ([]models.ProductVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM product_variants v WHERE v.product_id = $1 ORDER BY v.id`
+	return r.selectVariants(ctx, query, productID)
+}
+
+func (r *repository) GetVariantsByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM product_variants v WHERE v.product_id = ANY($1) ORDER BY v.id`
+	return r.selectVariants(ctx, query, pq.Array(productIDs))
+}
+
+func (r *repository) selectVariants(ctx context.Context, query string, args ...interface{}) ([]models.ProductVariant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []models.ProductVariant{}
+	for rows.Next() {
+		var variant models.ProductVariant
+		if err := scanVariant(rows, &variant); err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, rows.Err()
+}
+
+func (r *repository) GetVariantByID(ctx context.Context, id int64) (models.ProductVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM product_variants v WHERE v.id = $1`
+
+	var variant models.ProductVariant
+	err := scanVariant(r.db.QueryRowContext(ctx, query, id), &variant)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductVariant{}, ErrVariantNotFound
+	}
+	return variant, err
+}
+
+func (r *repository) CreateVariant(ctx context.Context, productID int64, input models.ProductVariantInput) (models.ProductVariant, error) {
+	attrs, err := marshalAttributes(input.Attributes)
+	if err != nil {
+		return models.ProductVariant{}, err
+	}
+
+	query := `
+		INSERT INTO product_variants AS v (product_id, sku, attributes, stock, price)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + variantColumns
+
+	var variant models.ProductVariant
+	err = outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := lockProduct(ctx, tx, productID); err != nil {
+			return err
+		}
+		row := tx.QueryRowContext(ctx, query, productID, input.SKU, attrs, input.Stock, input.Price)
+		if err := scanVariant(row, &variant); err != nil {
+			return err
+		}
+		_, err := SyncStock(ctx, tx, productID)
+		return err
+	})
+	return variant, variantError(err)
+}
+
+func (r *repository) UpdateVariant(ctx context.Context, productID, id int64, input models.ProductVariantInput) (models.ProductVariant, error) {
+	attrs, err := marshalAttributes(input.Attributes)
+	if err != nil {
+		return models.ProductVariant{}, err
+	}
+
+	query := `
+		UPDATE product_variants AS v
+		SET sku = $1, attributes = $2, stock = $3, price = $4
+		WHERE v.id = $5 AND v.product_id = $6
+		RETURNING ` + variantColumns
+
+	var variant models.ProductVariant
+	err = outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := lockProduct(ctx, tx, productID); err != nil {
+			return err
+		}
+		row := tx.QueryRowContext(ctx, query, input.SKU, attrs, input.Stock, input.Price, id, productID)
+		if err := scanVariant(row, &variant); err != nil {
+			return err
+		}
+		_, err := SyncStock(ctx, tx, productID)
+		return err
+	})
+	return variant, variantError(err)
+}
+
+func (r *repository) DeleteVariant(ctx context.Context, productID, id int64) error {
+	err := outbox.InTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := lockProduct(ctx, tx, productID); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM product_variants WHERE id = $1 AND product_id = $2`, id, productID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrVariantNotFound
+		}
+		_, err = SyncStock(ctx, tx, productID)
+		return err
+	})
+	return variantError(err)
+}
+
+// lockProduct locks the product row before its variants are changed. Checkouts lock products
+// before variants too, so the two cannot deadlock.
+func lockProduct(ctx context.Context, tx *sqlx.Tx, productID int64) error {
+	var id int64
+	return tx.GetContext(ctx, &id, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productID)
+}
+
+func variantError(err error) error {
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrVariantNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == errUniqueViolationCode:
+		return ErrDuplicateSKU
+	}
+	return err
+}
+
+// LockStock locks the product row inside tx and returns its current stock, so that a change
+// can publish the value it started from.
+func LockStock(ctx context.Context, tx *sqlx.Tx, productID int64) (int, error) {
+	var stock int
+	err := tx.GetContext(ctx, &stock, `SELECT COALESCE(stock, 0) FROM products WHERE id = $1 FOR UPDATE`, productID)
+	return stock, err
+}
+
+// SyncStock sets the product's stock to the sum of its variants' stock inside tx and
+// publishes the new value. It returns the product stock.
+func SyncStock(ctx context.Context, tx *sqlx.Tx, productID int64) (int, error) {
+	query := `
+		UPDATE products
+		SET stock = (SELECT COALESCE(SUM(stock), 0) FROM product_variants WHERE product_id = $1)
+		WHERE id = $1
+		RETURNING stock`
+
+	prevStock, err := LockStock(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+	var stock int
+	if err := tx.QueryRowContext(ctx, query, productID).Scan(&stock); err != nil {
+		return 0, err
+	}
+	return stock, outbox.Insert(ctx, tx, models.EventProductStockChanged, models.ProductEvent{ProductID: productID, PrevStock: &prevStock, Stock: &stock})
+}
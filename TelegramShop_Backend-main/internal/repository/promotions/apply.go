@@ -0,0 +1,121 @@
+package promotions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// NormalizeCode returns code the way it is stored: trimmed and upper-cased.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Apply computes the discount lines that code gives userID on an order made of lines, or a
+// *PromoCodeError explaining why it gives none. With lock set, the code row stays locked until
+// the end of tx so concurrent orders cannot exceed its usage limits; the order placed with the
+// discount must then be recorded with Redeem inside the same tx.
+func Apply(ctx context.Context, tx *sqlx.Tx, userID int64, code string, lines []models.OrderProduct, lock bool) (models.PromoCode, []models.OrderDiscount, error) {
+	code = NormalizeCode(code)
+
+	if lock {
+		// Lock in a statement of its own: the use counts below need a snapshot taken after
+		// a concurrent order holding the lock has committed its redemption.
+		_, err := tx.ExecContext(ctx, `SELECT 1 FROM promo_codes WHERE code = $1 FOR UPDATE`, code)
+		if err != nil {
+			return models.PromoCode{}, nil, err
+		}
+	}
+
+	var promo struct {
+		models.PromoCode
+		Started bool `db:"started"`
+		Expired bool `db:"expired"`
+	}
+	err := tx.GetContext(ctx, &promo, `
+		SELECT `+promoColumns+`,
+			(pc.starts_at IS NULL OR pc.starts_at <= current_timestamp) AS started,
+			(pc.ends_at IS NOT NULL AND pc.ends_at <= current_timestamp) AS expired
+		FROM promo_codes pc
+		WHERE pc.code = $1`, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.PromoCode{}, nil, &PromoCodeError{Code: code, Reason: models.PromoReasonNotFound}
+	}
+	if err != nil {
+		return models.PromoCode{}, nil, err
+	}
+
+	var userUses int
+	err = tx.GetContext(ctx, &userUses, `
+		SELECT COUNT(*)
+		FROM promo_code_redemptions r
+		JOIN orders o ON o.id = r.order_id
+		WHERE r.promo_code_id = $1 AND r.user_id = $2 AND o.status NOT IN ('cancelled', 'refunded')`,
+		promo.ID, userID)
+	if err != nil {
+		return models.PromoCode{}, nil, err
+	}
+
+	if reason := availability(promo.PromoCode, promo.Started, promo.Expired, userUses); reason != "" {
+		return models.PromoCode{}, nil, &PromoCodeError{Code: code, Reason: reason}
+	}
+
+	eligible, err := eligibleProducts(ctx, tx, promo.PromoCode, lines)
+	if err != nil {
+		return models.PromoCode{}, nil, err
+	}
+
+	result, reason := discounts(promo.PromoCode, lines, func(productID int) bool { return eligible[productID] })
+	if reason != "" {
+		return models.PromoCode{}, nil, &PromoCodeError{Code: code, Reason: reason}
+	}
+
+	return promo.PromoCode, result, nil
+}
+
+// eligibleProducts returns which products of lines fall into the category subtree and the firm
+// the code is limited to.
+func eligibleProducts(ctx context.Context, tx *sqlx.Tx, promo models.PromoCode, lines []models.OrderProduct) (map[int]bool, error) {
+	productIDs := make([]int, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $3
+			UNION
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT p.id
+		FROM products p
+		WHERE p.id = ANY($1)
+			AND ($2::integer IS NULL OR p.firm_id = $2)
+			AND ($3::integer IS NULL OR p.category_id IN (SELECT id FROM subtree))`
+
+	var found []int
+	if err := tx.SelectContext(ctx, &found, query, pq.Array(productIDs), promo.FirmID, promo.CategoryID); err != nil {
+		return nil, err
+	}
+
+	eligible := make(map[int]bool, len(found))
+	for _, id := range found {
+		eligible[id] = true
+	}
+	return eligible, nil
+}
+
+// Redeem records that the order was placed with the promo code, counting it towards the limits.
+func Redeem(ctx context.Context, tx *sqlx.Tx, promoCodeID, orderID, userID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO promo_code_redemptions (promo_code_id, order_id, user_id)
+		VALUES ($1, $2, $3)`,
+		promoCodeID, orderID, userID)
+	return err
+}
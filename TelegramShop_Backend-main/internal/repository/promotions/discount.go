@@ -0,0 +1,106 @@
+package promotions
+
+import (
+	"fmt"
+	"math"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/pkg/money"
+)
+
+// PromoCodeError explains why a promo code cannot be applied to an order.
+type PromoCodeError struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason" example:"expired"`
+}
+
+func (e *PromoCodeError) Error() string {
+	return fmt.Sprintf("promo code %q cannot be applied: %s", e.Code, e.Reason)
+}
+
+// availability returns why promo cannot be used right now by a user who has already used it
+// userUses times, or "" if it can. started and expired compare the validity window with the
+// database clock.
+func availability(promo models.PromoCode, started, expired bool, userUses int) string {
+	switch {
+	case !promo.Active:
+		return models.PromoReasonInactive
+	case !started:
+		return models.PromoReasonNotStarted
+	case expired:
+		return models.PromoReasonExpired
+	case promo.MaxUses != nil && promo.Uses >= *promo.MaxUses:
+		return models.PromoReasonUsageLimit
+	case promo.MaxUsesPerUser != nil && userUses >= *promo.MaxUsesPerUser:
+		return models.PromoReasonUserLimit
+	}
+	return ""
+}
+
+// discounts computes the discount lines promo gives to an order made of lines, or the reason
+// it gives none. eligible tells whether a product falls into the scope of the code.
+func discounts(promo models.PromoCode, lines []models.OrderProduct, eligible func(productID int) bool) ([]models.OrderDiscount, string) {
+	var subtotal float64
+	for _, line := range lines {
+		subtotal += line.Price * float64(line.Quantity)
+	}
+	if money.Round(subtotal) < promo.MinOrderTotal {
+		return nil, models.PromoReasonMinOrderTotal
+	}
+
+	discount := func(line *models.OrderProduct, amount float64) models.OrderDiscount {
+		d := models.OrderDiscount{PromoCodeID: &promo.ID, Code: promo.Code, Amount: money.Round(amount)}
+		if line != nil {
+			productID := line.ProductID
+			d.ProductID = &productID
+			d.VariantID = line.VariantID
+		}
+		return d
+	}
+
+	var result []models.OrderDiscount
+	switch promo.Kind {
+	case models.PromoKindPercent:
+		for i := range lines {
+			if !eligible(lines[i].ProductID) {
+				continue
+			}
+			amount := lines[i].Price * float64(lines[i].Quantity) * promo.Value / 100
+			if money.Round(amount) > 0 {
+				result = append(result, discount(&lines[i], amount))
+			}
+		}
+
+	case models.PromoKindFixed:
+		var eligibleTotal float64
+		for _, line := range lines {
+			if eligible(line.ProductID) {
+				eligibleTotal += line.Price * float64(line.Quantity)
+			}
+		}
+		if amount := math.Min(promo.Value, eligibleTotal); money.Round(amount) > 0 {
+			result = append(result, discount(nil, amount))
+		}
+
+	case models.PromoKindFreeItem:
+		// The cheapest matching line gives its unit away, so variants with their own price
+		// cannot be used to pick the most expensive one.
+		var cheapest *models.OrderProduct
+		for i := range lines {
+			if promo.ProductID == nil || int64(lines[i].ProductID) != *promo.ProductID {
+				continue
+			}
+			if cheapest == nil || lines[i].Price < cheapest.Price {
+				cheapest = &lines[i]
+			}
+		}
+		if cheapest != nil && money.Round(cheapest.Price) > 0 {
+			result = append(result, discount(cheapest, cheapest.Price))
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, models.PromoReasonNoEligibleItems
+	}
+	return result, ""
+}
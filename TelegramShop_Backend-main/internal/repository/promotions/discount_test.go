@@ -0,0 +1,102 @@
+package promotions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+)
+
+func TestDiscounts(t *testing.T) {
+	variant := 70
+	lines := []models.OrderProduct{
+		{ProductID: 1, Quantity: 2, Price: 100.50},
+		{ProductID: 2, Quantity: 1, Price: 300},
+		{ProductID: 2, VariantID: &variant, Quantity: 3, Price: 250},
+	}
+	all := func(int) bool { return true }
+	onlySecond := func(productID int) bool { return productID == 2 }
+
+	t.Run("PercentOnEligibleLines", func(t *testing.T) {
+		promo := models.PromoCode{ID: 9, Code: "TEN", Kind: models.PromoKindPercent, Value: 10}
+		result, reason := discounts(promo, lines, onlySecond)
+		require.Empty(t, reason)
+		require.Len(t, result, 2)
+		require.Equal(t, 30.0, result[0].Amount)
+		require.Equal(t, 2, *result[0].ProductID)
+		require.Nil(t, result[0].VariantID)
+		require.Equal(t, 75.0, result[1].Amount)
+		require.Equal(t, variant, *result[1].VariantID)
+		require.Equal(t, "TEN", result[1].Code)
+		require.Equal(t, int64(9), *result[1].PromoCodeID)
+	})
+
+	t.Run("PercentRoundsPerLine", func(t *testing.T) {
+		promo := models.PromoCode{Kind: models.PromoKindPercent, Value: 15}
+		result, _ := discounts(promo, lines[:1], all)
+		require.Equal(t, 30.15, result[0].Amount)
+	})
+
+	t.Run("FixedIsCappedByEligibleTotal", func(t *testing.T) {
+		promo := models.PromoCode{Kind: models.PromoKindFixed, Value: 500}
+		result, reason := discounts(promo, lines, func(productID int) bool { return productID == 1 })
+		require.Empty(t, reason)
+		require.Len(t, result, 1)
+		require.Nil(t, result[0].ProductID)
+		require.Equal(t, 201.0, result[0].Amount)
+
+		result, _ = discounts(promo, lines, all)
+		require.Equal(t, 500.0, result[0].Amount)
+	})
+
+	t.Run("FreeItemTakesCheapestUnit", func(t *testing.T) {
+		productID := int64(2)
+		promo := models.PromoCode{Kind: models.PromoKindFreeItem, ProductID: &productID}
+		result, reason := discounts(promo, lines, all)
+		require.Empty(t, reason)
+		require.Len(t, result, 1)
+		require.Equal(t, 250.0, result[0].Amount)
+		require.Equal(t, variant, *result[0].VariantID)
+	})
+
+	t.Run("FreeItemNotInOrder", func(t *testing.T) {
+		productID := int64(3)
+		promo := models.PromoCode{Kind: models.PromoKindFreeItem, ProductID: &productID}
+		_, reason := discounts(promo, lines, all)
+		require.Equal(t, models.PromoReasonNoEligibleItems, reason)
+	})
+
+	t.Run("NoEligibleLines", func(t *testing.T) {
+		promo := models.PromoCode{Kind: models.PromoKindPercent, Value: 10}
+		_, reason := discounts(promo, lines, func(int) bool { return false })
+		require.Equal(t, models.PromoReasonNoEligibleItems, reason)
+	})
+
+	t.Run("MinOrderTotal", func(t *testing.T) {
+		promo := models.PromoCode{Kind: models.PromoKindFixed, Value: 50, MinOrderTotal: 1251.01}
+		_, reason := discounts(promo, lines, all)
+		require.Equal(t, models.PromoReasonMinOrderTotal, reason)
+
+		promo.MinOrderTotal = 1251
+		_, reason = discounts(promo, lines, all)
+		require.Empty(t, reason)
+	})
+}
+
+func TestAvailability(t *testing.T) {
+	one := 1
+	three := 3
+	promo := models.PromoCode{Active: true, MaxUses: &three, MaxUsesPerUser: &one, Uses: 2}
+
+	require.Empty(t, availability(promo, true, false, 0))
+	require.Equal(t, models.PromoReasonNotStarted, availability(promo, false, false, 0))
+	require.Equal(t, models.PromoReasonExpired, availability(promo, true, true, 0))
+	require.Equal(t, models.PromoReasonUserLimit, availability(promo, true, false, 1))
+
+	promo.Uses = 3
+	require.Equal(t, models.PromoReasonUsageLimit, availability(promo, true, false, 0))
+
+	promo.Active = false
+	require.Equal(t, models.PromoReasonInactive, availability(promo, true, false, 0))
+}
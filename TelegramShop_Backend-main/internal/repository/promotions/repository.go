@@ -0,0 +1,131 @@
+package promotions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrPromoCodeNotFound   = errors.New("promo code not found")
+	ErrDuplicateCode       = errors.New("promo code already exists")
+	ErrUnknownReference    = errors.New("product, category or firm of the promo code does not exist")
+	errUniqueViolationCode = pq.ErrorCode("23505")
+	errForeignKeyCode      = pq.ErrorCode("23503")
+)
+
+// promoColumns selects a promo_codes row aliased pc together with its number of uses.
+// Cancelled and refunded orders give their use back.
+const promoColumns = `
+	pc.id, pc.code, pc.kind, pc.value, pc.product_id, pc.category_id, pc.firm_id,
+	pc.min_order_total, pc.max_uses, pc.max_uses_per_user, pc.starts_at, pc.ends_at,
+	pc.active, pc.created_at,
+	(SELECT COUNT(*)
+	 FROM promo_code_redemptions r
+	 JOIN orders o ON o.id = r.order_id
+	 WHERE r.promo_code_id = pc.id AND o.status NOT IN ('cancelled', 'refunded')) AS uses`
+
+type Repository interface {
+	CreatePromoCode(ctx context.Context, input models.PromoCodeInput) (models.PromoCode, error)
+	GetPromoCodes(ctx context.Context) ([]models.PromoCode, error)
+	GetPromoCodeByID(ctx context.Context, id int64) (models.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, id int64, input models.PromoCodeInput) (models.PromoCode, error)
+	DeletePromoCode(ctx context.Context, id int64) error
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreatePromoCode(ctx context.Context, input models.PromoCodeInput) (models.PromoCode, error) {
+	query := `
+		INSERT INTO promo_codes (code, kind, value, product_id, category_id, firm_id, min_order_total,
+			max_uses, max_uses_per_user, starts_at, ends_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12, true))
+		RETURNING id`
+
+	var id int64
+	err := r.db.GetContext(ctx, &id, query,
+		input.Code, input.Kind, input.Value, input.ProductID, input.CategoryID, input.FirmID, input.MinOrderTotal,
+		input.MaxUses, input.MaxUsesPerUser, input.StartsAt, input.EndsAt, input.Active)
+	if err != nil {
+		return models.PromoCode{}, promoError(err)
+	}
+
+	return r.GetPromoCodeByID(ctx, id)
+}
+
+func (r *repository) GetPromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	codes := []models.PromoCode{}
+	err := r.db.SelectContext(ctx, &codes, `SELECT `+promoColumns+` FROM promo_codes pc ORDER BY pc.id DESC`)
+	return codes, err
+}
+
+func (r *repository) GetPromoCodeByID(ctx context.Context, id int64) (models.PromoCode, error) {
+	var code models.PromoCode
+	err := r.db.GetContext(ctx, &code, `SELECT `+promoColumns+` FROM promo_codes pc WHERE pc.id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.PromoCode{}, ErrPromoCodeNotFound
+	}
+	return code, err
+}
+
+func (r *repository) UpdatePromoCode(ctx context.Context, id int64, input models.PromoCodeInput) (models.PromoCode, error) {
+	query := `
+		UPDATE promo_codes
+		SET code = $1, kind = $2, value = $3, product_id = $4, category_id = $5, firm_id = $6,
+			min_order_total = $7, max_uses = $8, max_uses_per_user = $9, starts_at = $10, ends_at = $11,
+			active = COALESCE($12, true)
+		WHERE id = $13`
+
+	res, err := r.db.ExecContext(ctx, query,
+		input.Code, input.Kind, input.Value, input.ProductID, input.CategoryID, input.FirmID, input.MinOrderTotal,
+		input.MaxUses, input.MaxUsesPerUser, input.StartsAt, input.EndsAt, input.Active, id)
+	if err != nil {
+		return models.PromoCode{}, promoError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.PromoCode{}, err
+	} else if n == 0 {
+		return models.PromoCode{}, ErrPromoCodeNotFound
+	}
+
+	return r.GetPromoCodeByID(ctx, id)
+}
+
+func (r *repository) DeletePromoCode(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM promo_codes WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPromoCodeNotFound
+	}
+	return nil
+}
+
+func promoError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case errUniqueViolationCode:
+			return ErrDuplicateCode
+		case errForeignKeyCode:
+			return ErrUnknownReference
+		}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package promotions_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/promotions"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func TestPromotionsRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := promotions.NewRepository(db)
+	ctx := context.Background()
+
+	input := models.PromoCodeInput{
+		Code:  fmt.Sprintf("REPO%d", time.Now().UnixNano()),
+		Kind:  models.PromoKindFixed,
+		Value: 150,
+	}
+	created, err := repo.CreatePromoCode(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, input.Code, created.Code)
+	require.True(t, created.Active)
+	require.Zero(t, created.Uses)
+
+	_, err = repo.CreatePromoCode(ctx, input)
+	require.ErrorIs(t, err, promotions.ErrDuplicateCode)
+
+	missing := int64(-1)
+	_, err = repo.CreatePromoCode(ctx, models.PromoCodeInput{Code: input.Code + "X", Kind: models.PromoKindFreeItem, ProductID: &missing})
+	require.ErrorIs(t, err, promotions.ErrUnknownReference)
+
+	inactive := false
+	input.Value = 200
+	input.Active = &inactive
+	updated, err := repo.UpdatePromoCode(ctx, created.ID, input)
+	require.NoError(t, err)
+	require.Equal(t, 200.0, updated.Value)
+	require.False(t, updated.Active)
+
+	codes, err := repo.GetPromoCodes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, codes[0].ID)
+
+	require.NoError(t, repo.DeletePromoCode(ctx, created.ID))
+	require.ErrorIs(t, repo.DeletePromoCode(ctx, created.ID), promotions.ErrPromoCodeNotFound)
+	_, err = repo.GetPromoCodeByID(ctx, created.ID)
+	require.ErrorIs(t, err, promotions.ErrPromoCodeNotFound)
+}
@@ -0,0 +1,74 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// FindIdleBaskets returns baskets of users who have not opted out, untouched for longer than
+	// idleFor and not reminded about since their last change, the longest idle first.
+	FindIdleBaskets(ctx context.Context, idleFor time.Duration, limit int) ([]models.IdleBasket, error)
+	// ClaimReminder records the reminder about the basket as of basketUpdatedAt. It returns false
+	// when the reminder was already claimed, so concurrent jobs remind a basket once.
+	ClaimReminder(ctx context.Context, userID int64, basketUpdatedAt time.Time) (bool, error)
+	// ReleaseReminder forgets a claimed reminder that could not be sent, so it is retried.
+	ReleaseReminder(ctx context.Context, userID int64, basketUpdatedAt time.Time) error
+	SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindIdleBaskets(ctx context.Context, idleFor time.Duration, limit int) ([]models.IdleBasket, error) {
+	query := `
+		SELECT b.user_id, u.telegram_id, MAX(b.updated_at) AS updated_at,
+			COUNT(*) AS lines, SUM(b.quantity) AS quantity
+		FROM basket b
+		JOIN users u ON u.id = b.user_id
+		WHERE u.basket_reminders_enabled
+		GROUP BY b.user_id, u.telegram_id
+		HAVING MAX(b.updated_at) < current_timestamp - $1 * interval '1 second'
+			AND NOT EXISTS (
+				SELECT 1 FROM basket_reminders r
+				WHERE r.user_id = b.user_id AND r.basket_updated_at >= MAX(b.updated_at))
+		ORDER BY updated_at
+		LIMIT $2`
+
+	baskets := []models.IdleBasket{}
+	err := r.db.SelectContext(ctx, &baskets, query, idleFor.Seconds(), limit)
+	return baskets, err
+}
+
+func (r *repository) ClaimReminder(ctx context.Context, userID int64, basketUpdatedAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO basket_reminders (user_id, basket_updated_at)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, basket_updated_at) DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, query, userID, basketUpdatedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *repository) ReleaseReminder(ctx context.Context, userID int64, basketUpdatedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM basket_reminders WHERE user_id = $1 AND basket_updated_at = $2`, userID, basketUpdatedAt)
+	return err
+}
+
+func (r *repository) SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET basket_reminders_enabled = $2 WHERE id = $1`, userID, enabled)
+	return err
+}
@@ -0,0 +1,100 @@
+package reminders_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/repository/reminders"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func createUser(t *testing.T, db *sqlx.DB) int64 {
+	suffix := time.Now().UnixNano()
+	var id int64
+	err := db.Get(&id, `INSERT INTO users (telegram_id, username) VALUES ($1, $2) RETURNING id`, suffix, fmt.Sprintf("reminders_test_%d", suffix))
+	require.NoError(t, err)
+	return id
+}
+
+// fillBasket puts a product in the user's basket and backdates it by idleFor.
+func fillBasket(t *testing.T, db *sqlx.DB, userID int64, idleFor time.Duration) {
+	product, err := products.NewRepository(db).CreateProduct(context.Background(), models.Product{
+		Name:        "Reminder Test Product",
+		Description: "Reminder test",
+		Stock:       10,
+	})
+	require.NoError(t, err)
+
+	_, err = basket.NewRepository(db).AddBasketItem(context.Background(), models.CreateBasketItem{UserID: userID, ProductID: int(product.ID), Quantity: 2}, 10)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE basket SET updated_at = current_timestamp - $2 * interval '1 second' WHERE user_id = $1`, userID, idleFor.Seconds())
+	require.NoError(t, err)
+}
+
+func findIdle(t *testing.T, repo reminders.Repository, userID int64) *models.IdleBasket {
+	baskets, err := repo.FindIdleBaskets(context.Background(), time.Hour, 1000)
+	require.NoError(t, err)
+	for _, basket := range baskets {
+		if basket.UserID == userID {
+			return &basket
+		}
+	}
+	return nil
+}
+
+func TestRemindersRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := reminders.NewRepository(db)
+	ctx := context.Background()
+
+	t.Run("IdleBasketRemindedOnce", func(t *testing.T) {
+		userID := createUser(t, db)
+		fillBasket(t, db, userID, 2*time.Hour)
+
+		idle := findIdle(t, repo, userID)
+		require.NotNil(t, idle)
+		require.Equal(t, 1, idle.Lines)
+		require.Equal(t, 2, idle.Quantity)
+
+		claimed, err := repo.ClaimReminder(ctx, userID, idle.UpdatedAt)
+		require.NoError(t, err)
+		require.True(t, claimed)
+		claimed, err = repo.ClaimReminder(ctx, userID, idle.UpdatedAt)
+		require.NoError(t, err)
+		require.False(t, claimed)
+		require.Nil(t, findIdle(t, repo, userID))
+
+		require.NoError(t, repo.ReleaseReminder(ctx, userID, idle.UpdatedAt))
+		require.NotNil(t, findIdle(t, repo, userID))
+	})
+
+	t.Run("RecentBasketNotIdle", func(t *testing.T) {
+		userID := createUser(t, db)
+		fillBasket(t, db, userID, time.Minute)
+		require.Nil(t, findIdle(t, repo, userID))
+	})
+
+	t.Run("OptOut", func(t *testing.T) {
+		userID := createUser(t, db)
+		fillBasket(t, db, userID, 2*time.Hour)
+
+		require.NoError(t, repo.SetRemindersEnabled(ctx, userID, false))
+		require.Nil(t, findIdle(t, repo, userID))
+		require.NoError(t, repo.SetRemindersEnabled(ctx, userID, true))
+		require.NotNil(t, findIdle(t, repo, userID))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"telegramshop_backend/internal/models"
+	"telegramshop_backend/pkg/pagination"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -14,10 +15,12 @@ import (
 type Repository interface {
 	CreateUser(ctx context.Context, user models.CreateUser) (models.User, error)
 	GetUserByID(ctx context.Context, telegramID int64) (models.User, error)
+	GetUserByInternalID(ctx context.Context, id int64) (models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (models.User, error)
 	UpdateUser(ctx context.Context, user models.User) error
 	DeleteUser(ctx context.Context, telegramID int64) error
-	GetAll(ctx context.Context) ([]models.User, error)
+	// GetAll returns one page of users ordered by id and the cursor of the next page.
+	GetAll(ctx context.Context, page pagination.Page) ([]models.User, string, error)
 }
 
 type repository struct {
@@ -58,6 +61,18 @@ func (r *repository) GetUserByID(ctx context.Context, telegramID int64) (models.
 	return user, nil
 }
 
+// GetUserByInternalID looks a user up by users.id, the key other tables reference.
+func (r *repository) GetUserByInternalID(ctx context.Context, id int64) (models.User, error) {
+	query := `SELECT id, telegram_id, username, created_at FROM users WHERE id = $1`
+
+	var user models.User
+	err := r.db.GetContext(ctx, &user, query, id)
+	if err == sql.ErrNoRows {
+		return models.User{}, nil
+	}
+	return user, err
+}
+
 func (r *repository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
 	query := `SELECT id, telegram_id, username, created_at FROM users WHERE username = $1`
 
@@ -92,14 +107,22 @@ func (r *repository) DeleteUser(ctx context.Context, telegramID int64) error {
 	return err
 }
 
-func (r *repository) GetAll(ctx context.Context) ([]models.User, error) {
+func (r *repository) GetAll(ctx context.Context, page pagination.Page) ([]models.User, string, error) {
+	var position pagination.IDPosition
+	if _, err := pagination.Decode(page.Cursor, &position); err != nil {
+		return nil, "", err
+	}
+
 	query := `
 		SELECT id, telegram_id, username, created_at
 		FROM users
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
 	`
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, position.ID, page.Limit+1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
@@ -107,9 +130,18 @@ func (r *repository) GetAll(ctx context.Context) ([]models.User, error) {
 	for rows.Next() {
 		var user models.User
 		if err := rows.Scan(&user.ID, &user.TelegramID, &user.Username, &user.CreatedAt); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		users = append(users, user)
 	}
-	return users, nil
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(users) > page.Limit {
+		users = users[:page.Limit]
+		next = pagination.Encode(pagination.IDPosition{ID: users[page.Limit-1].ID})
+	}
+	return users, next, nil
 }
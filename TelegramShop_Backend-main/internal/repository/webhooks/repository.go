@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"telegramshop_backend/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// PendingDelivery is a claimed delivery together with where and how to sign it.
+type PendingDelivery struct {
+	models.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+type Repository interface {
+	CreateSubscription(ctx context.Context, subscription models.CreateWebhookSubscription) (models.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscriptionByID(ctx context.Context, id int64) (models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	// Enqueue creates a delivery for every active subscription listening to eventType.
+	// Enqueueing the same event twice is a no-op, so an outbox retry does not duplicate deliveries.
+	Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error)
+	// Claim leases up to limit due deliveries, hiding them from other workers until lease expires.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]PendingDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int) error
+	// MarkFailed records a failed attempt and schedules the next one retryAfter from now by the
+	// database clock, or gives up on the delivery when final is set.
+	MarkFailed(ctx context.Context, id int64, responseStatus *int, lastError string, retryAfter time.Duration, final bool) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error)
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+const subscriptionColumns = `id, url, secret, event_types, active, created_at`
+
+func (r *repository) CreateSubscription(ctx context.Context, input models.CreateWebhookSubscription) (models.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types)
+		VALUES ($1, $2, $3)
+		RETURNING ` + subscriptionColumns
+
+	var subscription models.WebhookSubscription
+	err := r.db.GetContext(ctx, &subscription, query, input.URL, input.Secret, pq.StringArray(input.EventTypes))
+	return subscription, err
+}
+
+func (r *repository) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions ORDER BY id`
+
+	var subscriptions []models.WebhookSubscription
+	err := r.db.SelectContext(ctx, &subscriptions, query)
+	return subscriptions, err
+}
+
+func (r *repository) GetSubscriptionByID(ctx context.Context, id int64) (models.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	var subscription models.WebhookSubscription
+	err := r.db.GetContext(ctx, &subscription, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookSubscription{}, ErrSubscriptionNotFound
+	}
+	return subscription, err
+}
+
+func (r *repository) UpdateSubscription(ctx context.Context, subscription models.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $1, secret = $2, event_types = $3, active = $4
+		WHERE id = $5`
+
+	res, err := r.db.ExecContext(ctx, query,
+		subscription.URL, subscription.Secret, subscription.EventTypes, subscription.Active, subscription.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func (r *repository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *repository) Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3
+		FROM webhook_subscriptions
+		WHERE active AND $2 = ANY(event_types)
+		ON CONFLICT (subscription_id, event_id, event_type) DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, query, eventID, eventType, string(payload))
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at`
+
+func (r *repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]PendingDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = current_timestamp + $2 * interval '1 second',
+			attempts = d.attempts + 1
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= current_timestamp
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns + `, s.url, s.secret`
+
+	var deliveries []PendingDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, limit, lease.Seconds()); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the subquery order.
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *repository) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', response_status = $1, last_error = '', delivered_at = current_timestamp
+		WHERE id = $2`
+
+	_, err := r.db.ExecContext(ctx, query, responseStatus, id)
+	return err
+}
+
+func (r *repository) MarkFailed(ctx context.Context, id int64, responseStatus *int, lastError string, retryAfter time.Duration, final bool) error {
+	status := models.WebhookDeliveryPending
+	if final {
+		status = models.WebhookDeliveryFailed
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, response_status = $2, last_error = $3, next_attempt_at = current_timestamp + $4 * interval '1 second'
+		WHERE id = $5`
+
+	_, err := r.db.ExecContext(ctx, query, status, responseStatus, lastError, retryAfter.Seconds(), id)
+	return err
+}
+
+func (r *repository) GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2`
+
+	var deliveries []models.WebhookDelivery
+	err := r.db.SelectContext(ctx, &deliveries, query, subscriptionID, limit)
+	return deliveries, err
+}
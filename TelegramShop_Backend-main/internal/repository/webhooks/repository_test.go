@@ -0,0 +1,78 @@
+package webhooks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/webhooks"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", "host=localhost port=5432 user=root password=1111 dbname=telegram sslmode=disable")
+	require.NoError(t, err)
+	return db
+}
+
+func TestWebhooksRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := webhooks.NewRepository(db)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `DELETE FROM webhook_subscriptions`)
+	require.NoError(t, err)
+
+	subscription, err := repo.CreateSubscription(ctx, models.CreateWebhookSubscription{
+		URL:        "https://example.com/hook",
+		Secret:     "s3cr3t",
+		EventTypes: []string{models.WebhookOrderCreated},
+	})
+	require.NoError(t, err)
+	require.True(t, subscription.Active)
+
+	t.Run("EnqueueMatchesEventTypeOnce", func(t *testing.T) {
+		count, err := repo.Enqueue(ctx, 1, models.WebhookReviewCreated, []byte(`{}`))
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		count, err = repo.Enqueue(ctx, 1, models.WebhookOrderCreated, []byte(`{"order_id":1}`))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		count, err = repo.Enqueue(ctx, 1, models.WebhookOrderCreated, []byte(`{"order_id":1}`))
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("ClaimAndLog", func(t *testing.T) {
+		claimed, err := repo.Claim(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		require.Equal(t, "https://example.com/hook", claimed[0].URL)
+		require.Equal(t, "s3cr3t", claimed[0].Secret)
+		require.Equal(t, 1, claimed[0].Attempts)
+
+		status := 503
+		require.NoError(t, repo.MarkFailed(ctx, claimed[0].ID, &status, "unavailable", 0, true))
+
+		deliveries, err := repo.GetDeliveries(ctx, subscription.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, models.WebhookDeliveryFailed, deliveries[0].Status)
+		require.Equal(t, 503, *deliveries[0].ResponseStatus)
+	})
+
+	t.Run("DeleteCascades", func(t *testing.T) {
+		require.NoError(t, repo.DeleteSubscription(ctx, subscription.ID))
+		require.ErrorIs(t, repo.DeleteSubscription(ctx, subscription.ID), webhooks.ErrSubscriptionNotFound)
+
+		deliveries, err := repo.GetDeliveries(ctx, subscription.ID, 10)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	})
+}
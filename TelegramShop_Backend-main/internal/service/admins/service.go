@@ -0,0 +1,94 @@
+package admins
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/admins"
+	"telegramshop_backend/internal/repository/users"
+	"telegramshop_backend/pkg/logger"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type Service interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+	GetAdmins(ctx context.Context) ([]models.User, error)
+	GrantAdmin(ctx context.Context, telegramID int64) (models.User, error)
+	RevokeAdmin(ctx context.Context, telegramID int64) error
+}
+
+type service struct {
+	repo      admins.Repository
+	usersRepo users.Repository
+}
+
+func NewService(repo admins.Repository, usersRepo users.Repository) Service {
+	return &service{repo: repo, usersRepo: usersRepo}
+}
+
+func (s *service) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	isAdmin, err := s.repo.IsAdmin(ctx, userID)
+	if err != nil {
+		logger.Errorf("[IsAdmin] Error checking admin rights: %v", err)
+		return false, err
+	}
+
+	return isAdmin, nil
+}
+
+func (s *service) GetAdmins(ctx context.Context) ([]models.User, error) {
+	logger.Info("[GetAdmins] Getting all admins")
+
+	admins, err := s.repo.GetAll(ctx)
+	if err != nil {
+		logger.Errorf("[GetAdmins] Error getting admins: %v", err)
+		return nil, err
+	}
+
+	return admins, nil
+}
+
+func (s *service) GrantAdmin(ctx context.Context, telegramID int64) (models.User, error) {
+	logger.Infof("[GrantAdmin] Granting admin rights to user with id=%d", telegramID)
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		logger.Errorf("[GrantAdmin] Error getting user: %v", err)
+		return models.User{}, err
+	}
+
+	if err := s.repo.AddAdmin(ctx, user.ID); err != nil {
+		logger.Errorf("[GrantAdmin] Error granting admin rights: %v", err)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *service) RevokeAdmin(ctx context.Context, telegramID int64) error {
+	logger.Infof("[RevokeAdmin] Revoking admin rights from user with id=%d", telegramID)
+
+	user, err := s.getUser(ctx, telegramID)
+	if err != nil {
+		logger.Errorf("[RevokeAdmin] Error getting user: %v", err)
+		return err
+	}
+
+	if err := s.repo.RemoveAdmin(ctx, user.ID); err != nil {
+		logger.Errorf("[RevokeAdmin] Error revoking admin rights: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) getUser(ctx context.Context, telegramID int64) (models.User, error) {
+	user, err := s.usersRepo.GetUserByID(ctx, telegramID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	return user, err
+}
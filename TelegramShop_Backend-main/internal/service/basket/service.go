@@ -5,72 +5,119 @@ import (
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/service/pricing"
 	"telegramshop_backend/pkg/logger"
 )
 
+var (
+	ErrInvalidQuantity = basket.ErrInvalidQuantity
+	ErrProductNotFound = basket.ErrProductNotFound
+	ErrVariantNotFound = basket.ErrVariantNotFound
+	ErrVariantRequired = basket.ErrVariantRequired
+)
+
+type QuantityError = basket.QuantityError
+
+// DefaultMaxQuantity is the default limit on the quantity of one basket line.
+const DefaultMaxQuantity = 99
+
 type Service interface {
-	GetUserBasket(ctx context.Context, userID int64) ([]models.BasketItem, error)
+	GetUserBasket(ctx context.Context, userID int64) (models.Basket, error)
+	// AddToBasket adds the quantity to the line of the product and variant, creating it when missing.
 	AddToBasket(ctx context.Context, input models.BasketItem) (models.BasketItem, error)
+	// UpdateBasketItem sets the quantity of the line; quantity 0 removes it.
 	UpdateBasketItem(ctx context.Context, input models.BasketItem) (models.BasketItem, error)
-	RemoveFromBasket(ctx context.Context, userID int64, productID int) error
+	// ReplaceBasket replaces the whole basket atomically and returns it priced.
+	ReplaceBasket(ctx context.Context, userID int64, items []models.CreateBasketItem) (models.Basket, error)
+	// RemoveFromBasket removes the line of the variant, or every line of the product when variantID is nil.
+	RemoveFromBasket(ctx context.Context, userID int64, productID int, variantID *int) error
 }
 
 type service struct {
-	repo basket.Repository
+	repo           basket.Repository
+	pricingService pricing.Service
+	maxQuantity    int
 }
 
-func NewService(repo basket.Repository) Service {
-	return &service{repo: repo}
+func NewService(repo basket.Repository, pricingService pricing.Service, maxQuantity int) Service {
+	return &service{repo: repo, pricingService: pricingService, maxQuantity: maxQuantity}
 }
 
-func (s *service) GetUserBasket(ctx context.Context, userID int64) ([]models.BasketItem, error) {
+func (s *service) GetUserBasket(ctx context.Context, userID int64) (models.Basket, error) {
 	logger.Infof("[GetUserBasket] Getting basket items for user with id=%d", userID)
 
-	items, err := s.repo.GetUserBasket(ctx, userID)
+	lines, err := s.repo.GetUserBasket(ctx, userID)
 	if err != nil {
 		logger.Errorf("[GetUserBasket] Error getting basket items: %v", err)
-		return nil, err
+		return models.Basket{}, err
+	}
+
+	for i := range lines {
+		lines[i].Warning = stockWarning(lines[i])
 	}
 
-	return items, nil
+	basket, err := s.pricingService.PriceBasket(ctx, lines)
+	if err != nil {
+		logger.Errorf("[GetUserBasket] Error pricing basket: %v", err)
+		return models.Basket{}, err
+	}
+
+	return basket, nil
 }
 
 func (s *service) AddToBasket(ctx context.Context, input models.BasketItem) (models.BasketItem, error) {
 	logger.Infof("[AddToBasket] Adding product %d to basket for user %d", input.ProductID, input.UserID)
 
-	err := s.repo.CreateBasketItem(ctx, models.CreateBasketItem{
+	item, err := s.repo.AddBasketItem(ctx, models.CreateBasketItem{
 		UserID:    input.UserID,
 		ProductID: input.ProductID,
+		VariantID: input.VariantID,
 		Quantity:  input.Quantity,
-	})
+	}, s.maxQuantity)
 	if err != nil {
 		logger.Errorf("[AddToBasket] Error adding to basket: %v", err)
 		return models.BasketItem{}, err
 	}
 
-	return input, nil
+	return item, nil
 }
 
 func (s *service) UpdateBasketItem(ctx context.Context, input models.BasketItem) (models.BasketItem, error) {
 	logger.Infof("[UpdateBasketItem] Updating product %d in basket for user %d", input.ProductID, input.UserID)
 
-	err := s.repo.UpdateBasketItem(ctx, models.CreateBasketItem{
+	item, err := s.repo.SetBasketItem(ctx, models.CreateBasketItem{
 		UserID:    input.UserID,
 		ProductID: input.ProductID,
+		VariantID: input.VariantID,
 		Quantity:  input.Quantity,
-	})
+	}, s.maxQuantity)
 	if err != nil {
 		logger.Errorf("[UpdateBasketItem] Error updating basket item: %v", err)
 		return models.BasketItem{}, err
 	}
 
-	return input, nil
+	return item, nil
+}
+
+func (s *service) ReplaceBasket(ctx context.Context, userID int64, items []models.CreateBasketItem) (models.Basket, error) {
+	logger.Infof("[ReplaceBasket] Replacing basket of user %d with %d items", userID, len(items))
+
+	if err := s.repo.ReplaceBasket(ctx, userID, items, s.maxQuantity); err != nil {
+		logger.Errorf("[ReplaceBasket] Error replacing basket: %v", err)
+		return models.Basket{}, err
+	}
+
+	return s.GetUserBasket(ctx, userID)
 }
 
-func (s *service) RemoveFromBasket(ctx context.Context, userID int64, productID int) error {
+func (s *service) RemoveFromBasket(ctx context.Context, userID int64, productID int, variantID *int) error {
 	logger.Infof("[RemoveFromBasket] Removing product %d from basket for user %d", productID, userID)
 
-	err := s.repo.RemoveFromBasket(ctx, productID)
+	err := s.repo.DeleteBasketItem(ctx, models.DeleteBasketItem{
+		UserID:    userID,
+		ProductID: productID,
+		VariantID: variantID,
+	})
 	if err != nil {
 		logger.Errorf("[RemoveFromBasket] Error removing from basket: %v", err)
 		return err
@@ -78,3 +125,15 @@ func (s *service) RemoveFromBasket(ctx context.Context, userID int64, productID
 
 	return nil
 }
+
+// stockWarning tells why checkout would drop the line for lack of stock, if it would.
+func stockWarning(line models.BasketLine) string {
+	switch {
+	case line.Stock <= 0:
+		return models.BasketWarningOutOfStock
+	case line.Quantity > line.Stock:
+		return models.BasketWarningInsufficientStock
+	default:
+		return ""
+	}
+}
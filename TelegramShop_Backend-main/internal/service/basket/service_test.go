@@ -0,0 +1,55 @@
+package basket_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/basket"
+	"telegramshop_backend/internal/service/basket"
+	"telegramshop_backend/internal/service/pricing"
+)
+
+type fakeRepository struct {
+	repository.Repository
+	lines []models.BasketLine
+}
+
+func (r *fakeRepository) GetUserBasket(ctx context.Context, userID int64) ([]models.BasketLine, error) {
+	return r.lines, nil
+}
+
+// fakePricing prices every line at 10 per unit and counts lines without a warning.
+type fakePricing struct {
+	pricing.Service
+}
+
+func (p *fakePricing) PriceBasket(ctx context.Context, lines []models.BasketLine) (models.Basket, error) {
+	basket := models.Basket{Items: lines}
+	for _, line := range lines {
+		if line.Warning == "" {
+			basket.Subtotal += 10 * float64(line.Quantity)
+		}
+	}
+	return basket, nil
+}
+
+func TestGetUserBasketWarnings(t *testing.T) {
+	line := func(quantity, stock int) models.BasketLine {
+		return models.BasketLine{
+			BasketItem:     models.BasketItem{Quantity: quantity},
+			ProductSummary: models.ProductSummary{Stock: stock, InStock: stock > 0},
+		}
+	}
+	repo := &fakeRepository{lines: []models.BasketLine{line(2, 5), line(5, 5), line(6, 5), line(1, 0)}}
+
+	result, err := basket.NewService(repo, &fakePricing{}, basket.DefaultMaxQuantity).GetUserBasket(context.Background(), 1)
+	require.NoError(t, err)
+	require.Empty(t, result.Items[0].Warning)
+	require.Empty(t, result.Items[1].Warning)
+	require.Equal(t, models.BasketWarningInsufficientStock, result.Items[2].Warning)
+	require.Equal(t, models.BasketWarningOutOfStock, result.Items[3].Warning)
+	require.Equal(t, 70.0, result.Subtotal)
+}
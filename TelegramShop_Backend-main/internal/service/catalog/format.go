@@ -0,0 +1,282 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"telegramshop_backend/internal/models"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// csvColumns is the header of an exported CSV file. An imported file may order the
+// columns differently and leave out the optional ones, which keeps their stored values.
+// Empty stock, attributes, images and prices cells do the same.
+var csvColumns = []string{"id", "name", "description", "firm", "category", "stock", "attributes", "images", "prices"}
+
+var requiredCSVColumns = []string{"name", "firm", "category"}
+
+// listSeparator separates image URLs and "count:price" tiers within one CSV cell.
+const listSeparator = "|"
+
+// row is a decoded import row with the problems found while parsing its cells.
+type row struct {
+	number int
+	item   models.CatalogItem
+	errors []models.CatalogRowError
+}
+
+func (r *row) fail(field, format string, args ...interface{}) {
+	r.errors = append(r.errors, models.CatalogRowError{Row: r.number, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func decode(format string, r io.Reader) ([]row, error) {
+	switch format {
+	case FormatCSV:
+		return decodeCSV(r)
+	case FormatJSON:
+		return decodeJSON(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func decodeCSV(r io.Reader) ([]row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet programs often save UTF-8 CSV with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !isCSVColumn(name) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidFile, name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidFile, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFile, name)
+		}
+	}
+
+	var rows []row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		if len(rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseCSVRecord(line, record, columns))
+	}
+}
+
+func isCSVColumn(name string) bool {
+	for _, column := range csvColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCSVRecord(line int, record []string, columns map[string]int) row {
+	r := row{number: line}
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	r.item.Name = cell("name")
+	if _, ok := columns["description"]; ok {
+		description := cell("description")
+		r.item.Description = &description
+	}
+	r.item.Firm = cell("firm")
+	r.item.Category = cell("category")
+
+	if value := cell("id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			r.fail("id", "must be an integer")
+		}
+		r.item.ID = id
+	}
+	if value := cell("stock"); value != "" {
+		stock, err := strconv.Atoi(value)
+		if err != nil {
+			r.fail("stock", "must be an integer")
+		}
+		r.item.Stock = &stock
+	}
+	if value := cell("attributes"); value != "" {
+		if err := json.Unmarshal([]byte(value), &r.item.Attributes); err != nil || r.item.Attributes == nil {
+			r.fail("attributes", "must be a JSON object")
+		}
+	}
+	for _, image := range strings.Split(cell("images"), listSeparator) {
+		if image = strings.TrimSpace(image); image != "" {
+			r.item.Images = append(r.item.Images, image)
+		}
+	}
+	for _, tier := range strings.Split(cell("prices"), listSeparator) {
+		if tier = strings.TrimSpace(tier); tier == "" {
+			continue
+		}
+		count, price, ok := strings.Cut(tier, ":")
+		c, countErr := strconv.Atoi(strings.TrimSpace(count))
+		p, priceErr := strconv.ParseFloat(strings.TrimSpace(price), 64)
+		if !ok || countErr != nil || priceErr != nil {
+			r.fail("prices", "tier %q must look like count:price", tier)
+			continue
+		}
+		r.item.Prices = append(r.item.Prices, models.CatalogPrice{Count: c, Price: p})
+	}
+
+	return r
+}
+
+func decodeJSON(r io.Reader) ([]row, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected a JSON array of products", ErrInvalidFile)
+	}
+
+	var rows []row
+	for decoder.More() {
+		if len(rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+		r := row{number: len(rows) + 1}
+		if err := decoder.Decode(&r.item); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidFile, r.number, err)
+		}
+		rows = append(rows, r)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+	return rows, nil
+}
+
+// encoder writes exported items one by one, so the catalog never has to fit in memory.
+type encoder interface {
+	write(item models.CatalogItem) error
+	close() error
+}
+
+func newEncoder(format string, w io.Writer) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		return &csvEncoder{writer: writer}, writer.Write(csvColumns)
+	case FormatJSON:
+		_, err := io.WriteString(w, "[")
+		return &jsonEncoder{w: w}, err
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvEncoder) write(item models.CatalogItem) error {
+	var attrs string
+	if len(item.Attributes) > 0 {
+		data, err := json.Marshal(item.Attributes)
+		if err != nil {
+			return err
+		}
+		attrs = string(data)
+	}
+
+	var description, stock string
+	if item.Description != nil {
+		description = *item.Description
+	}
+	if item.Stock != nil {
+		stock = strconv.Itoa(*item.Stock)
+	}
+
+	tiers := make([]string, 0, len(item.Prices))
+	for _, tier := range item.Prices {
+		tiers = append(tiers, strconv.Itoa(tier.Count)+":"+strconv.FormatFloat(tier.Price, 'f', -1, 64))
+	}
+
+	return e.writer.Write([]string{
+		strconv.FormatInt(item.ID, 10),
+		item.Name,
+		description,
+		item.Firm,
+		item.Category,
+		stock,
+		attrs,
+		strings.Join(item.Images, listSeparator),
+		strings.Join(tiers, listSeparator),
+	})
+}
+
+func (e *csvEncoder) close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) write(item models.CatalogItem) error {
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append([]byte("\n"), data...))
+	return err
+}
+
+func (e *jsonEncoder) close() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
@@ -0,0 +1,179 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/catalog"
+	"telegramshop_backend/internal/service/images"
+	"telegramshop_backend/pkg/logger"
+)
+
+// MaxRows caps the size of one import.
+const MaxRows = 10000
+
+var (
+	ErrUnknownFormat = errors.New("format must be csv or json")
+	ErrInvalidFile   = errors.New("import file cannot be parsed")
+	ErrEmptyFile     = errors.New("import file has no products")
+	ErrTooManyRows   = fmt.Errorf("import file has more than %d products", MaxRows)
+	// ErrInvalidRows rejects an import in which some rows failed validation; nothing is written.
+	ErrInvalidRows = errors.New("some rows are invalid")
+)
+
+type Service interface {
+	// Import validates every row of the file and, unless dryRun is set or a row is invalid,
+	// stores all of them in one transaction. The result lists the problems of every row.
+	Import(ctx context.Context, format string, r io.Reader, dryRun bool) (models.CatalogImportResult, error)
+	// Export writes the whole catalog to w in the import format.
+	Export(ctx context.Context, format string, w io.Writer) error
+}
+
+type service struct {
+	repo         catalog.Repository
+	imageService images.Service
+}
+
+func NewService(repo catalog.Repository, imageService images.Service) Service {
+	return &service{repo: repo, imageService: imageService}
+}
+
+func (s *service) Import(ctx context.Context, format string, r io.Reader, dryRun bool) (models.CatalogImportResult, error) {
+	logger.Infof("[Import] Importing %s catalog, dry_run=%t", format, dryRun)
+
+	rows, err := decode(format, r)
+	if err == nil && len(rows) == 0 {
+		err = ErrEmptyFile
+	}
+	if err != nil {
+		logger.Errorf("[Import] Error reading file: %v", err)
+		return models.CatalogImportResult{}, err
+	}
+
+	var ids []int64
+	for _, r := range rows {
+		if r.item.ID > 0 {
+			ids = append(ids, r.item.ID)
+		}
+	}
+	existing, err := s.repo.ExistingProductIDs(ctx, ids)
+	if err != nil {
+		logger.Errorf("[Import] Error checking product ids: %v", err)
+		return models.CatalogImportResult{}, err
+	}
+
+	result := models.CatalogImportResult{DryRun: dryRun, Total: len(rows), Errors: []models.CatalogRowError{}}
+	items := make([]models.CatalogItem, 0, len(rows))
+	seen := make(map[int64]int, len(ids))
+	for i := range rows {
+		r := &rows[i]
+		validate(r, s.imageService.IsStored)
+		if id := r.item.ID; id > 0 {
+			if !existing[id] {
+				r.fail("id", "product %d does not exist", id)
+			} else if first, ok := seen[id]; ok {
+				r.fail("id", "product %d is already imported by row %d", id, first)
+			} else {
+				seen[id] = r.number
+			}
+			result.Updated++
+		} else {
+			result.Created++
+		}
+		result.Errors = append(result.Errors, r.errors...)
+		items = append(items, r.item)
+	}
+
+	if len(result.Errors) > 0 {
+		logger.Infof("[Import] %d problems found in %d rows", len(result.Errors), len(rows))
+		if !dryRun {
+			result.Created, result.Updated = 0, 0
+			return result, ErrInvalidRows
+		}
+		return result, nil
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	var dropped []string
+	result.Created, result.Updated, dropped, err = s.repo.Import(ctx, items)
+	if err != nil {
+		logger.Errorf("[Import] Error importing catalog: %v", err)
+		return models.CatalogImportResult{}, err
+	}
+	// The import is committed, so a failed cleanup only leaves files behind and is just logged.
+	if err := s.imageService.Cleanup(ctx, dropped...); err != nil {
+		logger.Errorf("[Import] Error cleaning up replaced images: %v", err)
+	}
+
+	logger.Infof("[Import] Created %d and updated %d products", result.Created, result.Updated)
+	return result, nil
+}
+
+// validate normalizes the item of r and records every problem with it. Images must be
+// http(s) URLs or URLs of uploaded images, which stored recognises.
+func validate(r *row, stored func(url string) bool) {
+	item := &r.item
+	item.Name = strings.TrimSpace(item.Name)
+	item.Firm = strings.TrimSpace(item.Firm)
+	item.Category = strings.TrimSpace(item.Category)
+
+	if item.ID < 0 {
+		r.fail("id", "must be positive")
+	}
+	if item.Name == "" {
+		r.fail("name", "is required")
+	}
+	if item.Firm == "" {
+		r.fail("firm", "is required")
+	}
+	if item.Category == "" {
+		r.fail("category", "is required")
+	}
+	if item.Stock != nil && *item.Stock < 0 {
+		r.fail("stock", "must not be negative")
+	}
+
+	for _, image := range item.Images {
+		if stored(image) {
+			continue
+		}
+		u, err := url.Parse(image)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			r.fail("images", "%q is neither an http(s) URL nor an uploaded image", image)
+		}
+	}
+
+	counts := make(map[int]bool, len(item.Prices))
+	for _, tier := range item.Prices {
+		switch {
+		case tier.Count < 1:
+			r.fail("prices", "tier count %d must be at least 1", tier.Count)
+		case tier.Price <= 0:
+			r.fail("prices", "price of tier %d must be positive", tier.Count)
+		case counts[tier.Count]:
+			r.fail("prices", "tier %d is listed twice", tier.Count)
+		}
+		counts[tier.Count] = true
+	}
+}
+
+func (s *service) Export(ctx context.Context, format string, w io.Writer) error {
+	logger.Infof("[Export] Exporting %s catalog", format)
+
+	enc, err := newEncoder(format, w)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Export(ctx, enc.write); err != nil {
+		logger.Errorf("[Export] Error exporting catalog: %v", err)
+		return err
+	}
+	return enc.close()
+}
@@ -0,0 +1,208 @@
+package catalog_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/catalog"
+	"telegramshop_backend/internal/service/images"
+)
+
+type fakeRepository struct {
+	existing map[int64]bool
+	imported []models.CatalogItem
+	dropped  []string
+	catalog  []models.CatalogItem
+}
+
+// fakeImages records the images an import asked to clean up.
+type fakeImages struct {
+	images.Service
+	cleaned []string
+}
+
+func (s *fakeImages) Cleanup(ctx context.Context, urls ...string) error {
+	s.cleaned = append(s.cleaned, urls...)
+	return nil
+}
+
+// IsStored recognises the relative URLs of the default local storage.
+func (s *fakeImages) IsStored(url string) bool {
+	return strings.HasPrefix(url, "/uploads/images/")
+}
+
+func (r *fakeRepository) ExistingProductIDs(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	return r.existing, nil
+}
+
+func (r *fakeRepository) Import(ctx context.Context, items []models.CatalogItem) (int, int, []string, error) {
+	r.imported = items
+	var created, updated int
+	for _, item := range items {
+		if item.ID == 0 {
+			created++
+		} else {
+			updated++
+		}
+	}
+	return created, updated, r.dropped, nil
+}
+
+func (r *fakeRepository) Export(ctx context.Context, fn func(models.CatalogItem) error) error {
+	for _, item := range r.catalog {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func stringPtr(v string) *string {
+	return &v
+}
+
+const validCSV = "\ufeffName,Firm,Category,Stock,Prices,Images,Attributes,ID\n" +
+	"iPhone 13,Apple,Смартфоны,5,1:79990|10:75000,https://example.com/1.jpg,\"{\"\"color\"\": \"\"black\"\"}\",\n" +
+	"Air Max,Nike,Кроссовки,3,1:12990,,,7\n"
+
+func TestImportCSV(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Imports", func(t *testing.T) {
+		repo := &fakeRepository{existing: map[int64]bool{7: true}, dropped: []string{"https://example.com/old.jpg"}}
+		imageService := &fakeImages{}
+		result, err := catalog.NewService(repo, imageService).Import(ctx, catalog.FormatCSV, strings.NewReader(validCSV), false)
+		require.NoError(t, err)
+		require.Equal(t, repo.dropped, imageService.cleaned, "replaced images are cleaned up")
+		require.Equal(t, models.CatalogImportResult{Total: 2, Created: 1, Updated: 1, Errors: []models.CatalogRowError{}}, result)
+
+		require.Len(t, repo.imported, 2)
+		require.Equal(t, models.CatalogItem{
+			Name:       "iPhone 13",
+			Firm:       "Apple",
+			Category:   "Смартфоны",
+			Stock:      intPtr(5),
+			Attributes: map[string]interface{}{"color": "black"},
+			Images:     []string{"https://example.com/1.jpg"},
+			Prices:     []models.CatalogPrice{{Count: 1, Price: 79990}, {Count: 10, Price: 75000}},
+		}, repo.imported[0])
+		require.Equal(t, int64(7), repo.imported[1].ID)
+	})
+
+	t.Run("DryRunReportsRowErrors", func(t *testing.T) {
+		file := "id,name,firm,category,stock,prices,images\n" +
+			"99,Ghost,Apple,Смартфоны,1,1:10,\n" +
+			",,Apple,Смартфоны,-1,0:10|1:abc,ftp://example.com/a.jpg\n"
+		repo := &fakeRepository{}
+		result, err := catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatCSV, strings.NewReader(file), true)
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Nil(t, repo.imported)
+
+		fields := map[int][]string{}
+		for _, rowErr := range result.Errors {
+			fields[rowErr.Row] = append(fields[rowErr.Row], rowErr.Field)
+		}
+		require.Equal(t, []string{"id"}, fields[2])
+		require.ElementsMatch(t, []string{"prices", "name", "stock", "images", "prices"}, fields[3])
+	})
+
+	t.Run("InvalidRowsRejectImport", func(t *testing.T) {
+		repo := &fakeRepository{}
+		file := "name,firm,category\niPhone,,Смартфоны\n"
+		result, err := catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatCSV, strings.NewReader(file), false)
+		require.ErrorIs(t, err, catalog.ErrInvalidRows)
+		require.Equal(t, []models.CatalogRowError{{Row: 2, Field: "firm", Message: "is required"}}, result.Errors)
+		require.Nil(t, repo.imported)
+	})
+
+	t.Run("AbsentColumnsKeepStoredValues", func(t *testing.T) {
+		repo := &fakeRepository{existing: map[int64]bool{7: true}}
+		file := "id,name,firm,category,stock,attributes\n7,iPhone 13,Apple,Смартфоны,,\n"
+		_, err := catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatCSV, strings.NewReader(file), false)
+		require.NoError(t, err)
+		require.Nil(t, repo.imported[0].Description)
+		require.Nil(t, repo.imported[0].Stock)
+		require.Nil(t, repo.imported[0].Attributes)
+
+		file = "id,name,description,firm,category\n7,iPhone 13,,Apple,Смартфоны\n"
+		_, err = catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatCSV, strings.NewReader(file), false)
+		require.NoError(t, err)
+		require.Equal(t, stringPtr(""), repo.imported[0].Description, "a present description column clears the description")
+	})
+
+	t.Run("BadHeader", func(t *testing.T) {
+		service := catalog.NewService(&fakeRepository{}, &fakeImages{})
+		_, err := service.Import(ctx, catalog.FormatCSV, strings.NewReader("name,firm,colour\n"), false)
+		require.ErrorIs(t, err, catalog.ErrInvalidFile)
+		_, err = service.Import(ctx, catalog.FormatCSV, strings.NewReader("name,firm\n"), false)
+		require.ErrorIs(t, err, catalog.ErrInvalidFile)
+		_, err = service.Import(ctx, catalog.FormatCSV, strings.NewReader("name,firm,category\n"), false)
+		require.ErrorIs(t, err, catalog.ErrEmptyFile)
+	})
+}
+
+func TestImportJSON(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepository{existing: map[int64]bool{3: true}}
+	file := `[{"id": 3, "name": "Galaxy S24", "firm": "Samsung", "category": "Смартфоны", "prices": [{"count": 1, "price": 89990}]},
+		{"id": 3, "name": "Galaxy S24", "firm": "Samsung", "category": "Смартфоны"}]`
+
+	result, err := catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatJSON, strings.NewReader(file), false)
+	require.ErrorIs(t, err, catalog.ErrInvalidRows)
+	require.Equal(t, []models.CatalogRowError{{Row: 2, Field: "id", Message: "product 3 is already imported by row 1"}}, result.Errors)
+
+	partial := `[{"id": 3, "name": "Galaxy S24", "firm": "Samsung", "category": "Смартфоны"}]`
+	_, err = catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatJSON, strings.NewReader(partial), false)
+	require.NoError(t, err)
+	require.Nil(t, repo.imported[0].Description, "absent keys keep the stored values")
+	require.Nil(t, repo.imported[0].Stock)
+	require.Nil(t, repo.imported[0].Attributes)
+
+	_, err = catalog.NewService(repo, &fakeImages{}).Import(ctx, catalog.FormatJSON, strings.NewReader(`{"name": "x"}`), false)
+	require.ErrorIs(t, err, catalog.ErrInvalidFile)
+	_, err = catalog.NewService(repo, &fakeImages{}).Import(ctx, "xml", strings.NewReader(file), false)
+	require.ErrorIs(t, err, catalog.ErrUnknownFormat)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	items := []models.CatalogItem{
+		{
+			ID:          1,
+			Name:        `Кроссовки "Air Max", 42`,
+			Description: stringPtr("Лёгкие\nи удобные"),
+			Firm:        "Nike",
+			Category:    "Кроссовки",
+			Stock:       intPtr(4),
+			Attributes:  map[string]interface{}{"size": "42"},
+			Images:      []string{"https://example.com/1.jpg", "/uploads/images/ab12.jpg"},
+			Prices:      []models.CatalogPrice{{Count: 1, Price: 12990.5}},
+		},
+		{ID: 2, Name: "Футболка", Firm: "Nike", Category: "Одежда"},
+	}
+
+	for _, format := range []string{catalog.FormatCSV, catalog.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, catalog.NewService(&fakeRepository{catalog: items}, &fakeImages{}).Export(ctx, format, &buf))
+
+			repo := &fakeRepository{existing: map[int64]bool{1: true, 2: true}}
+			result, err := catalog.NewService(repo, &fakeImages{}).Import(ctx, format, &buf, false)
+			require.NoError(t, err)
+			require.Equal(t, 2, result.Updated)
+			require.Equal(t, items[0], repo.imported[0])
+			require.Equal(t, items[1].Name, repo.imported[1].Name)
+			require.Empty(t, repo.imported[1].Prices)
+		})
+	}
+}
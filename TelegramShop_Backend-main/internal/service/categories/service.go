@@ -2,18 +2,27 @@ package categories
 
 import (
 	"context"
+	"sort"
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/categories"
 	"telegramshop_backend/pkg/logger"
 )
 
+var (
+	ErrParentNotFound      = categories.ErrParentNotFound
+	ErrCategoryCycle       = categories.ErrCategoryCycle
+	ErrCategoryHasChildren = categories.ErrCategoryHasChildren
+)
+
 type Service interface {
 	CreateCategory(ctx context.Context, input models.Category) (models.Category, error)
 	GetCategoryByID(ctx context.Context, id int64) (models.Category, error)
 	GetAllCategories(ctx context.Context) ([]models.Category, error)
+	// GetCategoryTree returns root categories with their subcategories nested, sorted by name.
+	GetCategoryTree(ctx context.Context) ([]models.CategoryNode, error)
 	UpdateCategory(ctx context.Context, id int64, input models.UpdateCategoryInput) error
-	DeleteCategory(ctx context.Context, id int64) error
+	DeleteCategory(ctx context.Context, id int64, reparent bool) error
 	SetImage(ctx context.Context, id int64, imageURL string) error
 	RemoveImage(ctx context.Context, id int64) error
 }
@@ -74,10 +83,40 @@ func (s *service) UpdateCategory(ctx context.Context, id int64, input models.Upd
 	return nil
 }
 
-func (s *service) DeleteCategory(ctx context.Context, id int64) error {
-	logger.Infof("[DeleteCategory] Deleting category with id=%d", id)
+func (s *service) GetCategoryTree(ctx context.Context) ([]models.CategoryNode, error) {
+	logger.Info("[GetCategoryTree] Getting category tree")
+
+	all, err := s.repo.GetAllCategories(ctx)
+	if err != nil {
+		logger.Errorf("[GetCategoryTree] Error getting categories: %v", err)
+		return nil, err
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	children := make(map[int64][]models.Category)
+	for _, category := range all {
+		var parentID int64
+		if category.ParentID != nil {
+			parentID = *category.ParentID
+		}
+		children[parentID] = append(children[parentID], category)
+	}
+
+	var build func(parentID int64) []models.CategoryNode
+	build = func(parentID int64) []models.CategoryNode {
+		nodes := make([]models.CategoryNode, 0, len(children[parentID]))
+		for _, category := range children[parentID] {
+			nodes = append(nodes, models.CategoryNode{Category: category, Children: build(category.ID)})
+		}
+		return nodes
+	}
+	return build(0), nil
+}
+
+func (s *service) DeleteCategory(ctx context.Context, id int64, reparent bool) error {
+	logger.Infof("[DeleteCategory] Deleting category with id=%d reparent=%t", id, reparent)
 
-	err := s.repo.DeleteCategory(ctx, id)
+	err := s.repo.DeleteCategory(ctx, id, reparent)
 	if err != nil {
 		logger.Errorf("[DeleteCategory] Error deleting category: %v", err)
 		return err
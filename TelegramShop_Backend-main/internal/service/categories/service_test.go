@@ -0,0 +1,92 @@
+package categories_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/categories"
+	"telegramshop_backend/internal/service/categories"
+)
+
+type fakeRepository struct {
+	repository.Repository
+	categories []models.Category
+	updated    bool
+	input      models.UpdateCategoryInput
+}
+
+func (r *fakeRepository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	return r.categories, nil
+}
+
+func (r *fakeRepository) UpdateCategory(ctx context.Context, id int64, input models.UpdateCategoryInput) error {
+	r.updated = true
+	r.input = input
+	return nil
+}
+
+func (r *fakeRepository) CreateCategory(ctx context.Context, category models.Category) (models.Category, error) {
+	category.ID = int64(len(r.categories) + 1)
+	return category, nil
+}
+
+func id(v int64) *int64 { return &v }
+
+func parent(v *int64) models.NullableID { return models.NullableID{Set: true, Value: v} }
+
+// Электроника → Телефоны → Смартфоны, Одежда
+func newRepository() *fakeRepository {
+	return &fakeRepository{categories: []models.Category{
+		{ID: 1, Name: "Электроника"},
+		{ID: 2, Name: "Телефоны", ParentID: id(1)},
+		{ID: 3, Name: "Смартфоны", ParentID: id(2)},
+		{ID: 4, Name: "Одежда"},
+	}}
+}
+
+func TestGetCategoryTree(t *testing.T) {
+	tree, err := categories.NewService(newRepository()).GetCategoryTree(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, tree, 2)
+	require.Equal(t, "Одежда", tree[0].Name)
+	require.Empty(t, tree[0].Children)
+	require.Equal(t, "Электроника", tree[1].Name)
+	require.Len(t, tree[1].Children, 1)
+	require.Equal(t, "Телефоны", tree[1].Children[0].Name)
+	require.Len(t, tree[1].Children[0].Children, 1)
+	require.Equal(t, int64(3), tree[1].Children[0].Children[0].ID)
+}
+
+func TestUpdateCategoryParent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Move", func(t *testing.T) {
+		repo := newRepository()
+		service := categories.NewService(repo)
+
+		require.NoError(t, service.UpdateCategory(ctx, 3, models.UpdateCategoryInput{Name: "Смартфоны", ParentID: parent(id(4))}))
+		require.NoError(t, service.UpdateCategory(ctx, 2, models.UpdateCategoryInput{Name: "Телефоны", ParentID: parent(nil)}))
+		require.True(t, repo.updated)
+		require.True(t, repo.input.ParentID.Set)
+		require.Nil(t, repo.input.ParentID.Value)
+	})
+
+	t.Run("RenameKeepsParent", func(t *testing.T) {
+		repo := newRepository()
+		service := categories.NewService(repo)
+
+		var input models.UpdateCategoryInput
+		require.NoError(t, json.Unmarshal([]byte(`{"name": "Телефоны и гаджеты"}`), &input))
+		require.NoError(t, service.UpdateCategory(ctx, 2, input))
+		require.False(t, repo.input.ParentID.Set)
+
+		require.NoError(t, json.Unmarshal([]byte(`{"name": "Телефоны", "parent_id": null}`), &input))
+		require.True(t, input.ParentID.Set)
+		require.Nil(t, input.ParentID.Value)
+	})
+}
@@ -6,13 +6,14 @@ import (
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/comment"
 	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/pagination"
 )
 
 type CommentService interface {
 	AddComment(ctx context.Context, id int64, productID int, commentText string) (models.Comment, error)
 	EditComment(ctx context.Context, id int64, productID int, newCommentText string) error
 	DeleteComment(ctx context.Context, id int64, productID int) error
-	GetCommentsByProduct(ctx context.Context, productID int) ([]models.Comment, error)
+	GetCommentsByProduct(ctx context.Context, productID int, page pagination.Page) ([]models.Comment, string, error)
 }
 
 type service struct {
@@ -84,6 +85,6 @@ func (s *service) DeleteComment(ctx context.Context, id int64, productID int) er
 	return s.repo.DeleteComment(ctx, commentID)
 }
 
-func (s *service) GetCommentsByProduct(ctx context.Context, productID int) ([]models.Comment, error) {
-	return s.repo.GetCommentsByProduct(ctx, productID)
+func (s *service) GetCommentsByProduct(ctx context.Context, productID int, page pagination.Page) ([]models.Comment, string, error) {
+	return s.repo.GetCommentsByProduct(ctx, productID, page)
 }
@@ -5,24 +5,33 @@ import (
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/favorites"
+	"telegramshop_backend/internal/service/pricing"
 	"telegramshop_backend/pkg/logger"
 )
 
+var (
+	ErrProductNotFound = favorites.ErrProductNotFound
+	ErrVariantNotFound = favorites.ErrVariantNotFound
+	ErrVariantRequired = favorites.ErrVariantRequired
+)
+
 type Service interface {
-	GetUserFavorites(ctx context.Context, userID int64) ([]int64, error)
+	GetUserFavorites(ctx context.Context, userID int64) ([]models.FavoriteLine, error)
 	AddToFavorites(ctx context.Context, input models.Favorite) (models.Favorite, error)
-	RemoveFromFavorites(ctx context.Context, userID int64, productID int) error
+	// RemoveFromFavorites removes the favorite of the given variant, or of the whole product when variantID is nil.
+	RemoveFromFavorites(ctx context.Context, userID int64, productID int, variantID *int) error
 }
 
 type service struct {
-	repo favorites.Repository
+	repo           favorites.Repository
+	pricingService pricing.Service
 }
 
-func NewService(repo favorites.Repository) Service {
-	return &service{repo: repo}
+func NewService(repo favorites.Repository, pricingService pricing.Service) Service {
+	return &service{repo: repo, pricingService: pricingService}
 }
 
-func (s *service) GetUserFavorites(ctx context.Context, userID int64) ([]int64, error) {
+func (s *service) GetUserFavorites(ctx context.Context, userID int64) ([]models.FavoriteLine, error) {
 	logger.Infof("[GetUserFavorites] Getting favorite products for user with id=%d", userID)
 
 	favs, err := s.repo.GetUserFavorites(ctx, userID)
@@ -31,11 +40,12 @@ func (s *service) GetUserFavorites(ctx context.Context, userID int64) ([]int64,
 		return nil, err
 	}
 
-	productIDs := make([]int64, len(favs))
-	for i, fav := range favs {
-		productIDs[i] = int64(fav.ProductID)
+	if err := s.pricingService.PriceFavorites(ctx, favs); err != nil {
+		logger.Errorf("[GetUserFavorites] Error pricing favorite products: %v", err)
+		return nil, err
 	}
-	return productIDs, nil
+
+	return favs, nil
 }
 
 func (s *service) AddToFavorites(ctx context.Context, input models.Favorite) (models.Favorite, error) {
@@ -44,6 +54,7 @@ func (s *service) AddToFavorites(ctx context.Context, input models.Favorite) (mo
 	err := s.repo.CreateFavorite(ctx, models.CreateFavorite{
 		UserID:    input.UserID,
 		ProductID: input.ProductID,
+		VariantID: input.VariantID,
 	})
 	if err != nil {
 		logger.Errorf("[AddToFavorites] Error adding to favorites: %v", err)
@@ -53,12 +64,13 @@ func (s *service) AddToFavorites(ctx context.Context, input models.Favorite) (mo
 	return input, nil
 }
 
-func (s *service) RemoveFromFavorites(ctx context.Context, userID int64, productID int) error {
+func (s *service) RemoveFromFavorites(ctx context.Context, userID int64, productID int, variantID *int) error {
 	logger.Infof("[RemoveFromFavorites] Removing product %d from favorites for user %d", productID, userID)
 
 	err := s.repo.DeleteFavorite(ctx, models.DeleteFavorite{
 		UserID:    userID,
 		ProductID: productID,
+		VariantID: variantID,
 	})
 	if err != nil {
 		logger.Errorf("[RemoveFromFavorites] Error removing from favorites: %v", err)
@@ -0,0 +1,196 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/images"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/storage"
+)
+
+// keyPrefix is the storage folder of uploaded images. Cleanup never touches other keys.
+const keyPrefix = "images/"
+
+// thumbnailSuffix is appended to the content hash to name the thumbnail of an image.
+const thumbnailSuffix = "_thumb.jpg"
+
+// extensions lists the accepted content types, as sniffed from the file itself.
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var (
+	ErrFileTooLarge    = errors.New("image file is too large")
+	ErrImageTooLarge   = errors.New("image dimensions are too large")
+	ErrUnsupportedType = errors.New("image must be jpeg, png or gif")
+	ErrInvalidImage    = errors.New("image cannot be decoded")
+)
+
+type Config struct {
+	// MaxSize is the largest accepted file in bytes.
+	MaxSize int64
+	// MaxPixels caps width*height, so small files cannot expand into huge bitmaps.
+	MaxPixels int
+	// ThumbnailWidth is the width thumbnails are scaled down to.
+	ThumbnailWidth int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxSize:        5 << 20,
+		MaxPixels:      25_000_000,
+		ThumbnailWidth: 320,
+	}
+}
+
+type Service interface {
+	// Upload validates an image, stores it under the hash of its content together with
+	// a JPEG thumbnail and calls attach with the image URL to reference it. No cleanup of the
+	// same file runs in between. Uploading the same file twice is harmless.
+	Upload(ctx context.Context, data []byte, attach func(url string) error) (models.UploadedImage, error)
+	// Cleanup deletes the stored files of urls that no product or category uses anymore.
+	// URLs outside the storage are ignored.
+	Cleanup(ctx context.Context, urls ...string) error
+	// IsStored reports whether url addresses an image uploaded to the storage, such as the
+	// relative URLs the local backend hands out.
+	IsStored(url string) bool
+}
+
+type service struct {
+	repo    images.Repository
+	storage storage.Storage
+	cfg     Config
+}
+
+func NewService(repo images.Repository, storage storage.Storage, cfg Config) Service {
+	return &service{repo: repo, storage: storage, cfg: cfg}
+}
+
+func (s *service) Upload(ctx context.Context, data []byte, attach func(url string) error) (models.UploadedImage, error) {
+	logger.Infof("[Upload] Uploading image of %d bytes", len(data))
+
+	img, ext, err := s.decode(data)
+	if err != nil {
+		logger.Errorf("[Upload] Error validating image: %v", err)
+		return models.UploadedImage{}, err
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, thumbnail(img, s.cfg.ThumbnailWidth), &jpeg.Options{Quality: 85}); err != nil {
+		logger.Errorf("[Upload] Error encoding thumbnail: %v", err)
+		return models.UploadedImage{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := keyPrefix + hash + ext
+	thumbKey := keyPrefix + hash + thumbnailSuffix
+
+	uploaded := models.UploadedImage{URL: s.storage.URL(key), ThumbnailURL: s.storage.URL(thumbKey)}
+	err = s.repo.WithLock(ctx, key, func() error {
+		if err := s.storage.Put(ctx, key, data, http.DetectContentType(data)); err != nil {
+			return fmt.Errorf("store image: %w", err)
+		}
+		if err := s.storage.Put(ctx, thumbKey, thumb.Bytes(), "image/jpeg"); err != nil {
+			return fmt.Errorf("store thumbnail: %w", err)
+		}
+		return attach(uploaded.URL)
+	})
+	if err != nil {
+		logger.Errorf("[Upload] Error storing image: %v", err)
+		return models.UploadedImage{}, err
+	}
+
+	return uploaded, nil
+}
+
+// decode checks the size, the sniffed content type and the dimensions of data before
+// decoding it, and returns the file extension matching the content.
+func (s *service) decode(data []byte) (image.Image, string, error) {
+	if int64(len(data)) > s.cfg.MaxSize {
+		return nil, "", fmt.Errorf("%w: %d bytes, at most %d allowed", ErrFileTooLarge, len(data), s.cfg.MaxSize)
+	}
+
+	ext, ok := extensions[http.DetectContentType(data)]
+	if !ok {
+		return nil, "", ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrInvalidImage
+	}
+	if config.Width*config.Height > s.cfg.MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return img, ext, nil
+}
+
+func (s *service) Cleanup(ctx context.Context, urls ...string) error {
+	var errs []error
+	for _, url := range urls {
+		key, ok := s.storage.Key(url)
+		if !ok || !strings.HasPrefix(key, keyPrefix) || strings.HasSuffix(key, thumbnailSuffix) {
+			continue
+		}
+
+		// Under the lock an upload of the same file cannot store it again and reference it
+		// between the check and the delete.
+		err := s.repo.WithLock(ctx, key, func() error {
+			referenced, err := s.repo.IsReferenced(ctx, url)
+			if err != nil {
+				return fmt.Errorf("check references of %s: %w", url, err)
+			}
+			if referenced {
+				return nil
+			}
+			return s.delete(ctx, key)
+		})
+		if err != nil {
+			logger.Errorf("[Cleanup] Error cleaning up %s: %v", url, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *service) IsStored(url string) bool {
+	key, ok := s.storage.Key(url)
+	return ok && strings.HasPrefix(key, keyPrefix)
+}
+
+// delete removes the image stored under key and its thumbnail.
+func (s *service) delete(ctx context.Context, key string) error {
+	logger.Infof("[Cleanup] Deleting orphaned image %s", key)
+	hash, _, _ := strings.Cut(strings.TrimPrefix(key, keyPrefix), ".")
+
+	var errs []error
+	for _, k := range []string{key, keyPrefix + hash + thumbnailSuffix} {
+		if err := s.storage.Delete(ctx, k); err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", k, err))
+		}
+	}
+	return errors.Join(errs...)
+}
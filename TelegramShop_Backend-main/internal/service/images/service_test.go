@@ -0,0 +1,132 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/repository/images"
+	"telegramshop_backend/pkg/storage"
+)
+
+type fakeRepo struct {
+	images.Repository
+	referenced map[string]bool
+}
+
+func (r *fakeRepo) IsReferenced(ctx context.Context, url string) (bool, error) {
+	return r.referenced[url], nil
+}
+
+func (r *fakeRepo) WithLock(ctx context.Context, key string, fn func() error) error {
+	return fn()
+}
+
+// attach references an uploaded image the way a handler stores it on a product.
+func (r *fakeRepo) attach(url string) error {
+	r.referenced[url] = true
+	return nil
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestUploadAndCleanup(t *testing.T) {
+	dir := t.TempDir()
+	repo := &fakeRepo{referenced: map[string]bool{}}
+	cfg := DefaultConfig()
+	cfg.ThumbnailWidth = 64
+	svc := NewService(repo, storage.NewLocal(dir, "http://localhost/uploads"), cfg)
+	ctx := context.Background()
+
+	data := encodePNG(t, 640, 480)
+	uploaded, err := svc.Upload(ctx, data, repo.attach)
+	require.NoError(t, err)
+	require.True(t, repo.referenced[uploaded.URL])
+	require.True(t, strings.HasPrefix(uploaded.URL, "http://localhost/uploads/images/"), uploaded.URL)
+	require.True(t, strings.HasSuffix(uploaded.URL, ".png"), uploaded.URL)
+	require.Equal(t, strings.TrimSuffix(uploaded.URL, ".png")+"_thumb.jpg", uploaded.ThumbnailURL)
+
+	again, err := svc.Upload(ctx, data, repo.attach)
+	require.NoError(t, err)
+	require.Equal(t, uploaded, again, "files are named by their content")
+
+	original := filepath.Join(dir, strings.TrimPrefix(uploaded.URL, "http://localhost/uploads/"))
+	stored, err := os.ReadFile(original)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+
+	thumbPath := filepath.Join(dir, strings.TrimPrefix(uploaded.ThumbnailURL, "http://localhost/uploads/"))
+	thumbFile, err := os.Open(thumbPath)
+	require.NoError(t, err)
+	thumb, err := jpeg.Decode(thumbFile)
+	thumbFile.Close()
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 64, 48), thumb.Bounds())
+	r, g, b, _ := thumb.At(32, 24).RGBA()
+	require.InDelta(t, 200, r>>8, 8)
+	require.InDelta(t, 0, g>>8, 8)
+	require.InDelta(t, 0, b>>8, 8)
+
+	require.True(t, svc.IsStored(uploaded.URL))
+	require.False(t, svc.IsStored("https://example.com/images/a.png"))
+
+	// A referenced image and foreign URLs are kept.
+	require.NoError(t, svc.Cleanup(ctx, uploaded.URL, "https://example.com/images/a.png", uploaded.ThumbnailURL))
+	require.FileExists(t, original)
+	require.FileExists(t, thumbPath)
+
+	repo.referenced[uploaded.URL] = false
+	require.NoError(t, svc.Cleanup(ctx, uploaded.URL))
+	require.NoFileExists(t, original)
+	require.NoFileExists(t, thumbPath)
+}
+
+func TestUploadRejectsInvalidFiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxSize = 1 << 20
+	cfg.MaxPixels = 1000 * 1000
+	repo := &fakeRepo{referenced: map[string]bool{}}
+	svc := NewService(repo, storage.NewLocal(t.TempDir(), "/uploads"), cfg)
+	ctx := context.Background()
+
+	_, err := svc.Upload(ctx, []byte("%PDF-1.4 not an image"), repo.attach)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = svc.Upload(ctx, make([]byte, cfg.MaxSize+1), repo.attach)
+	require.ErrorIs(t, err, ErrFileTooLarge)
+
+	_, err = svc.Upload(ctx, encodePNG(t, 2000, 600), repo.attach)
+	require.ErrorIs(t, err, ErrImageTooLarge)
+
+	truncated := encodePNG(t, 10, 10)
+	_, err = svc.Upload(ctx, truncated[:40], repo.attach)
+	require.ErrorIs(t, err, ErrInvalidImage)
+	require.Empty(t, repo.referenced, "rejected files are never attached")
+}
+
+func TestThumbnailKeepsSmallImages(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 105, 55))
+	thumb := thumbnail(img, 320)
+	require.Equal(t, image.Rect(0, 0, 100, 50), thumb.Bounds())
+	// Transparent pixels are flattened onto white.
+	require.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, thumb.RGBAAt(0, 0))
+}
@@ -2,38 +2,79 @@ package orders
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/orders"
+	"telegramshop_backend/internal/repository/promotions"
 	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/pagination"
+)
+
+var (
+	ErrEmptyOrder           = orders.ErrEmptyOrder
+	ErrInvalidQuantity      = orders.ErrInvalidQuantity
+	ErrProductNotFound      = orders.ErrProductNotFound
+	ErrPriceTierMissing     = orders.ErrPriceTierMissing
+	ErrStatusConflict       = orders.ErrStatusConflict
+	ErrEmptyBasket          = orders.ErrEmptyBasket
+	ErrVariantNotFound      = orders.ErrVariantNotFound
+	ErrVariantRequired      = orders.ErrVariantRequired
+	ErrIdempotencyKeyReused = orders.ErrIdempotencyKeyReused
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrUnknownStatus        = errors.New("unknown order status")
+	ErrInvalidTransition    = errors.New("order status transition is not allowed")
+	ErrNotOrderOwner        = errors.New("order belongs to another user")
+	ErrNotCancellable       = errors.New("order can no longer be cancelled")
+)
+
+type (
+	InsufficientStockError = orders.InsufficientStockError
+	UnavailableBasketError = orders.UnavailableBasketError
+	PromoCodeError         = promotions.PromoCodeError
 )
 
 type Service interface {
-	GetAll(ctx context.Context) ([]models.OrderWithProducts, error)
+	GetAll(ctx context.Context, page pagination.Page) ([]models.OrderWithProducts, string, error)
 	CreateOrder(ctx context.Context, input models.CreateOrder) (models.OrderWithProducts, error)
+	CreateOrderIdempotent(ctx context.Context, input models.CreateOrder, key string) (models.OrderWithProducts, bool, error)
+	PurgeIdempotencyKeys(ctx context.Context) error
+	Checkout(ctx context.Context, userID int64, promoCode string) (models.Checkout, error)
+	// PreviewBasket prices the user's basket with the promo code without placing an order.
+	PreviewBasket(ctx context.Context, userID int64, promoCode string) (models.OrderQuote, error)
 	GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error)
-	GetUserOrders(ctx context.Context, userID int64) ([]models.OrderWithProducts, error)
+	GetUserOrders(ctx context.Context, userID int64, page pagination.Page) ([]models.OrderWithProducts, string, error)
+	UpdateStatus(ctx context.Context, orderID int, status string, actorID int64, comment string) (models.OrderWithProducts, error)
+	CancelOrder(ctx context.Context, orderID int, actorID int64, reason string, override bool) (models.OrderWithProducts, error)
 }
 
 type service struct {
-	repo orders.Repository
+	repo           orders.Repository
+	idempotencyTTL time.Duration
 }
 
-func NewService(repo orders.Repository) Service {
-	return &service{repo: repo}
+// NewService creates the orders service. idempotencyTTL is how long an Idempotency-Key
+// keeps replaying the order it created.
+func NewService(repo orders.Repository, idempotencyTTL time.Duration) Service {
+	return &service{repo: repo, idempotencyTTL: idempotencyTTL}
 }
 
-func (s *service) GetAll(ctx context.Context) ([]models.OrderWithProducts, error) {
+func (s *service) GetAll(ctx context.Context, page pagination.Page) ([]models.OrderWithProducts, string, error) {
 
 	logger.Info("[GetAll] Getting all orders")
 
-	orders, err := s.repo.GetAll(ctx)
+	orders, next, err := s.repo.GetAll(ctx, page)
 	if err != nil {
 		logger.Errorf("[GetAll] Error getting orders: %v", err)
-		return nil, err
+		return nil, "", err
 	}
 
-	return orders, nil
+	return orders, next, nil
 }
 
 func (s *service) CreateOrder(ctx context.Context, input models.CreateOrder) (models.OrderWithProducts, error) {
@@ -49,6 +90,86 @@ func (s *service) CreateOrder(ctx context.Context, input models.CreateOrder) (mo
 	return createdOrder, nil
 }
 
+func (s *service) CreateOrderIdempotent(ctx context.Context, input models.CreateOrder, key string) (models.OrderWithProducts, bool, error) {
+	logger.Infof("[CreateOrderIdempotent] Creating order for user %d with idempotency key %q", input.UserID, key)
+
+	hash, err := RequestHash(input)
+	if err != nil {
+		return models.OrderWithProducts{}, false, err
+	}
+
+	order, replayed, err := s.repo.CreateOrderIdempotent(ctx, input, key, hash, s.idempotencyTTL)
+	if err != nil {
+		logger.Errorf("[CreateOrderIdempotent] Error creating order: %v", err)
+		return models.OrderWithProducts{}, false, err
+	}
+	if replayed {
+		logger.Infof("[CreateOrderIdempotent] Replaying order %d for idempotency key %q", order.ID, key)
+	}
+
+	return order, replayed, nil
+}
+
+func (s *service) PurgeIdempotencyKeys(ctx context.Context) error {
+	deleted, err := s.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		logger.Errorf("[PurgeIdempotencyKeys] Error deleting expired keys: %v", err)
+		return err
+	}
+	if deleted > 0 {
+		logger.Infof("[PurgeIdempotencyKeys] Deleted %d expired idempotency keys", deleted)
+	}
+
+	return nil
+}
+
+// RequestHash fingerprints the order payload so a reused Idempotency-Key with a different body can be detected.
+func RequestHash(input models.CreateOrder) (string, error) {
+	// Orders without a promo code keep hashing the items alone, as keys stored before codes existed did.
+	var body interface{} = input.Items
+	if input.PromoCode != "" {
+		body = struct {
+			Items     []models.CreateOrderItem `json:"items"`
+			PromoCode string                   `json:"promo_code"`
+		}{input.Items, promotions.NormalizeCode(input.PromoCode)}
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *service) Checkout(ctx context.Context, userID int64, promoCode string) (models.Checkout, error) {
+	logger.Infof("[Checkout] Checking out basket for user %d with promo code %q", userID, promoCode)
+
+	checkout, err := s.repo.Checkout(ctx, userID, promoCode)
+	if err != nil {
+		logger.Errorf("[Checkout] Error checking out basket: %v", err)
+		return models.Checkout{}, err
+	}
+	if len(checkout.Dropped) > 0 {
+		logger.Infof("[Checkout] Dropped %d unavailable basket items for user %d", len(checkout.Dropped), userID)
+	}
+
+	return checkout, nil
+}
+
+func (s *service) PreviewBasket(ctx context.Context, userID int64, promoCode string) (models.OrderQuote, error) {
+	logger.Infof("[PreviewBasket] Pricing basket of user %d with promo code %q", userID, promoCode)
+
+	quote, err := s.repo.PreviewBasket(ctx, userID, promoCode)
+	if err != nil {
+		logger.Errorf("[PreviewBasket] Error pricing basket: %v", err)
+		return models.OrderQuote{}, err
+	}
+
+	return quote, nil
+}
+
 func (s *service) GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error) {
 	logger.Infof("[GetOrderByID] Getting order with id=%d", id)
 
@@ -61,14 +182,87 @@ func (s *service) GetOrderByID(ctx context.Context, id int) (models.OrderWithPro
 	return order, nil
 }
 
-func (s *service) GetUserOrders(ctx context.Context, userID int64) ([]models.OrderWithProducts, error) {
+func (s *service) GetUserOrders(ctx context.Context, userID int64, page pagination.Page) ([]models.OrderWithProducts, string, error) {
 	logger.Infof("[GetUserOrders] Getting orders for user %d", userID)
 
-	orders, err := s.repo.GetUserOrders(ctx, userID)
+	orders, next, err := s.repo.GetUserOrders(ctx, userID, page)
 	if err != nil {
 		logger.Errorf("[GetUserOrders] Error getting user orders: %v", err)
-		return nil, err
+		return nil, "", err
+	}
+
+	return orders, next, nil
+}
+
+func (s *service) UpdateStatus(ctx context.Context, orderID int, status string, actorID int64, comment string) (models.OrderWithProducts, error) {
+	logger.Infof("[UpdateStatus] Moving order %d to status %s", orderID, status)
+
+	if !IsKnownStatus(status) {
+		return models.OrderWithProducts{}, fmt.Errorf("%w: %s", ErrUnknownStatus, status)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		logger.Errorf("[UpdateStatus] Error getting order: %v", err)
+		return models.OrderWithProducts{}, err
+	}
+	if order.ID == 0 {
+		return models.OrderWithProducts{}, ErrOrderNotFound
+	}
+
+	if !CanTransition(order.Status, status) {
+		return models.OrderWithProducts{}, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, order.Status, status)
+	}
+
+	// Cancelling must give the reserved stock back, so it goes through the same path as CancelOrder.
+	// Refunds restock too; the repository does that in the same transaction as the status change.
+	if status == models.OrderStatusCancelled {
+		return s.cancel(ctx, order, actorID, comment)
+	}
+
+	if err := s.repo.UpdateStatus(ctx, orderID, order.Status, status, actorID, comment); err != nil {
+		logger.Errorf("[UpdateStatus] Error updating order status: %v", err)
+		return models.OrderWithProducts{}, err
+	}
+
+	return s.repo.GetOrderByID(ctx, int(order.ID))
+}
+
+// CancelOrder cancels the order and returns its items to stock. Customers may only cancel their own
+// orders while they are pending or paid; override lifts both restrictions for admin tooling, leaving
+// only the lifecycle rules in place.
+func (s *service) CancelOrder(ctx context.Context, orderID int, actorID int64, reason string, override bool) (models.OrderWithProducts, error) {
+	logger.Infof("[CancelOrder] Cancelling order %d by user %d (override=%t)", orderID, actorID, override)
+
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		logger.Errorf("[CancelOrder] Error getting order: %v", err)
+		return models.OrderWithProducts{}, err
+	}
+	if order.ID == 0 {
+		return models.OrderWithProducts{}, ErrOrderNotFound
+	}
+
+	if !override {
+		if order.UserID != actorID {
+			return models.OrderWithProducts{}, ErrNotOrderOwner
+		}
+		if !CanCustomerCancel(order.Status) {
+			return models.OrderWithProducts{}, fmt.Errorf("%w: order is %s", ErrNotCancellable, order.Status)
+		}
+	}
+	if !CanTransition(order.Status, models.OrderStatusCancelled) {
+		return models.OrderWithProducts{}, fmt.Errorf("%w: order is %s", ErrNotCancellable, order.Status)
+	}
+
+	return s.cancel(ctx, order, actorID, reason)
+}
+
+func (s *service) cancel(ctx context.Context, order models.OrderWithProducts, actorID int64, reason string) (models.OrderWithProducts, error) {
+	if err := s.repo.CancelOrder(ctx, int(order.ID), order.Status, actorID, reason); err != nil {
+		logger.Errorf("[CancelOrder] Error cancelling order: %v", err)
+		return models.OrderWithProducts{}, err
 	}
 
-	return orders, nil
+	return s.repo.GetOrderByID(ctx, int(order.ID))
 }
@@ -0,0 +1,108 @@
+package orders_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/orders"
+	"telegramshop_backend/internal/service/orders"
+)
+
+// fakeRepository keeps orders in memory and records cancellations.
+type fakeRepository struct {
+	repository.Repository
+	orders    map[int]models.OrderWithProducts
+	cancelled []int
+}
+
+func (r *fakeRepository) GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error) {
+	return r.orders[id], nil
+}
+
+func (r *fakeRepository) CancelOrder(ctx context.Context, orderID int, from string, actorID int64, reason string) error {
+	order := r.orders[orderID]
+	order.Status = models.OrderStatusCancelled
+	order.CancelReason = &reason
+	r.orders[orderID] = order
+	r.cancelled = append(r.cancelled, orderID)
+	return nil
+}
+
+func (r *fakeRepository) UpdateStatus(ctx context.Context, orderID int, from, to string, actorID int64, comment string) error {
+	order := r.orders[orderID]
+	order.Status = to
+	r.orders[orderID] = order
+	return nil
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{orders: map[int]models.OrderWithProducts{
+		1: {ID: 1, UserID: 10, Status: models.OrderStatusPending},
+		2: {ID: 2, UserID: 10, Status: models.OrderStatusAssembling},
+		3: {ID: 3, UserID: 10, Status: models.OrderStatusPaid},
+	}}
+}
+
+func TestCancelOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Owner", func(t *testing.T) {
+		repo := newFakeRepository()
+		order, err := orders.NewService(repo, time.Hour).CancelOrder(ctx, 1, 10, "changed my mind", false)
+		require.NoError(t, err)
+		require.Equal(t, models.OrderStatusCancelled, order.Status)
+		require.Equal(t, "changed my mind", *order.CancelReason)
+	})
+
+	t.Run("AnotherUser", func(t *testing.T) {
+		_, err := orders.NewService(newFakeRepository(), time.Hour).CancelOrder(ctx, 1, 11, "", false)
+		require.ErrorIs(t, err, orders.ErrNotOrderOwner)
+	})
+
+	t.Run("AlreadyAssembling", func(t *testing.T) {
+		_, err := orders.NewService(newFakeRepository(), time.Hour).CancelOrder(ctx, 2, 10, "", false)
+		require.ErrorIs(t, err, orders.ErrNotCancellable)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		repo := newFakeRepository()
+		_, err := orders.NewService(repo, time.Hour).CancelOrder(ctx, 2, 99, "out of stock at warehouse", true)
+		require.NoError(t, err)
+		require.Equal(t, []int{2}, repo.cancelled)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := orders.NewService(newFakeRepository(), time.Hour).CancelOrder(ctx, 42, 10, "", false)
+		require.ErrorIs(t, err, orders.ErrOrderNotFound)
+	})
+
+	t.Run("StatusUpdateRestocks", func(t *testing.T) {
+		repo := newFakeRepository()
+		_, err := orders.NewService(repo, time.Hour).UpdateStatus(ctx, 3, models.OrderStatusCancelled, 99, "fraud")
+		require.NoError(t, err)
+		require.Equal(t, []int{3}, repo.cancelled)
+	})
+}
+
+func TestRequestHash(t *testing.T) {
+	a, err := orders.RequestHash(models.CreateOrder{UserID: 1, Items: []models.CreateOrderItem{{ProductID: 1, Quantity: 2}}})
+	require.NoError(t, err)
+	b, err := orders.RequestHash(models.CreateOrder{UserID: 1, Items: []models.CreateOrderItem{{ProductID: 1, Quantity: 2}}})
+	require.NoError(t, err)
+	c, err := orders.RequestHash(models.CreateOrder{UserID: 1, Items: []models.CreateOrderItem{{ProductID: 1, Quantity: 3}}})
+	require.NoError(t, err)
+
+	require.Equal(t, a, b)
+	require.NotEqual(t, a, c)
+
+	withCode, err := orders.RequestHash(models.CreateOrder{UserID: 1, Items: []models.CreateOrderItem{{ProductID: 1, Quantity: 2}}, PromoCode: "summer10"})
+	require.NoError(t, err)
+	sameCode, err := orders.RequestHash(models.CreateOrder{UserID: 1, Items: []models.CreateOrderItem{{ProductID: 1, Quantity: 2}}, PromoCode: "SUMMER10"})
+	require.NoError(t, err)
+	require.NotEqual(t, a, withCode)
+	require.Equal(t, withCode, sameCode)
+}
@@ -0,0 +1,40 @@
+package orders
+
+import "telegramshop_backend/internal/models"
+
+// transitions lists the statuses an order may move to from each status.
+// Delivered orders can only be refunded; cancelled and refunded orders are final.
+var transitions = map[string][]string{
+	models.OrderStatusPending:    {models.OrderStatusPaid, models.OrderStatusCancelled},
+	models.OrderStatusPaid:       {models.OrderStatusAssembling, models.OrderStatusCancelled, models.OrderStatusRefunded},
+	models.OrderStatusAssembling: {models.OrderStatusShipped, models.OrderStatusCancelled, models.OrderStatusRefunded},
+	models.OrderStatusShipped:    {models.OrderStatusDelivered, models.OrderStatusRefunded},
+	models.OrderStatusDelivered:  {models.OrderStatusRefunded},
+}
+
+// CanTransition reports whether an order in status from may be moved to status to.
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanCustomerCancel reports whether the owner may still cancel an order in status.
+// Once assembling has started only an admin can cancel it.
+func CanCustomerCancel(status string) bool {
+	return status == models.OrderStatusPending || status == models.OrderStatusPaid
+}
+
+// IsKnownStatus reports whether status is part of the order lifecycle.
+func IsKnownStatus(status string) bool {
+	switch status {
+	case models.OrderStatusPending, models.OrderStatusPaid, models.OrderStatusAssembling,
+		models.OrderStatusShipped, models.OrderStatusDelivered, models.OrderStatusCancelled,
+		models.OrderStatusRefunded:
+		return true
+	}
+	return false
+}
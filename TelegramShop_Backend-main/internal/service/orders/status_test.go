@@ -0,0 +1,45 @@
+package orders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/service/orders"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := [][2]string{
+		{models.OrderStatusPending, models.OrderStatusPaid},
+		{models.OrderStatusPaid, models.OrderStatusAssembling},
+		{models.OrderStatusAssembling, models.OrderStatusShipped},
+		{models.OrderStatusShipped, models.OrderStatusDelivered},
+		{models.OrderStatusPending, models.OrderStatusCancelled},
+		{models.OrderStatusPaid, models.OrderStatusRefunded},
+		{models.OrderStatusDelivered, models.OrderStatusRefunded},
+	}
+	for _, tr := range allowed {
+		require.True(t, orders.CanTransition(tr[0], tr[1]), "%s -> %s", tr[0], tr[1])
+	}
+
+	forbidden := [][2]string{
+		{models.OrderStatusPending, models.OrderStatusShipped},
+		{models.OrderStatusPaid, models.OrderStatusPending},
+		{models.OrderStatusDelivered, models.OrderStatusCancelled},
+		{models.OrderStatusCancelled, models.OrderStatusPaid},
+		{models.OrderStatusRefunded, models.OrderStatusPaid},
+		{models.OrderStatusPending, models.OrderStatusPending},
+		{models.OrderStatusPending, "lost"},
+	}
+	for _, tr := range forbidden {
+		require.False(t, orders.CanTransition(tr[0], tr[1]), "%s -> %s", tr[0], tr[1])
+	}
+}
+
+func TestCanCustomerCancel(t *testing.T) {
+	require.True(t, orders.CanCustomerCancel(models.OrderStatusPending))
+	require.True(t, orders.CanCustomerCancel(models.OrderStatusPaid))
+	require.False(t, orders.CanCustomerCancel(models.OrderStatusAssembling))
+	require.False(t, orders.CanCustomerCancel(models.OrderStatusCancelled))
+}
@@ -0,0 +1,159 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/retry"
+)
+
+var ErrEventNotFound = outbox.ErrEventNotFound
+
+// Handler processes one event. Returning an error schedules a retry.
+type Handler func(ctx context.Context, event models.OutboxEvent) error
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed event stays hidden from other dispatchers.
+	Lease time.Duration
+	// MaxAttempts is the number of deliveries after which an event is moved to the dead state.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:   time.Second,
+		BatchSize:      50,
+		Lease:          time.Minute,
+		MaxAttempts:    10,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     10 * time.Minute,
+	}
+}
+
+type Service interface {
+	// Register adds a handler for the event type. All handlers must be registered before Run.
+	Register(eventType string, handler Handler)
+	Run(ctx context.Context)
+	// DispatchOnce delivers one batch of due events and returns how many were claimed.
+	DispatchOnce(ctx context.Context) (int, error)
+	ListEvents(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error)
+	ReplayEvent(ctx context.Context, id int64) error
+}
+
+type service struct {
+	repo     outbox.Repository
+	cfg      Config
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewService(repo outbox.Repository, cfg Config) Service {
+	return &service{repo: repo, cfg: cfg, handlers: map[string][]Handler{}}
+}
+
+func (s *service) Register(eventType string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[eventType] = append(s.handlers[eventType], handler)
+}
+
+// Run polls the outbox until ctx is cancelled. Several instances may run at once;
+// each event is claimed by only one of them.
+func (s *service) Run(ctx context.Context) {
+	retry.Poll(ctx, s.cfg.PollInterval, s.cfg.BatchSize, s.DispatchOnce, func(err error) {
+		logger.Errorf("[Run] Error dispatching outbox events: %v", err)
+	})
+}
+
+func (s *service) DispatchOnce(ctx context.Context) (int, error) {
+	events, err := s.repo.Claim(ctx, s.cfg.BatchSize, s.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := s.deliver(ctx, event); err != nil {
+			s.fail(ctx, event, err)
+			continue
+		}
+		if err := s.repo.MarkDone(ctx, event.ID); err != nil {
+			logger.Errorf("[DispatchOnce] Error marking event %d as done: %v", event.ID, err)
+		}
+	}
+
+	return len(events), nil
+}
+
+// deliver runs every handler registered for the event type, even after one of them fails, so a
+// failing consumer does not hold back the others. Handlers must be idempotent: after a failure
+// the whole event is retried, including handlers that already succeeded.
+func (s *service) deliver(ctx context.Context, event models.OutboxEvent) error {
+	s.mu.RLock()
+	handlers := s.handlers[event.EventType]
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		errs = append(errs, s.run(ctx, handler, event))
+	}
+	return errors.Join(errs...)
+}
+
+// run calls handler and turns its panic into an error.
+func (s *service) run(ctx context.Context, handler Handler, event models.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
+
+func (s *service) fail(ctx context.Context, event models.OutboxEvent, cause error) {
+	dead := event.Attempts >= s.cfg.MaxAttempts
+	retryAfter := retry.Backoff(s.cfg.InitialBackoff, s.cfg.MaxBackoff, event.Attempts)
+
+	if dead {
+		logger.Errorf("[DispatchOnce] Event %d (%s) is dead after %d attempts: %v", event.ID, event.EventType, event.Attempts, cause)
+	} else {
+		logger.Errorf("[DispatchOnce] Event %d (%s) failed, retrying in %s: %v", event.ID, event.EventType, retryAfter, cause)
+	}
+
+	if err := s.repo.MarkFailed(ctx, event.ID, cause.Error(), retryAfter, dead); err != nil {
+		logger.Errorf("[DispatchOnce] Error marking event %d as failed: %v", event.ID, err)
+	}
+}
+
+func (s *service) ListEvents(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error) {
+	logger.Infof("[ListEvents] Listing outbox events with status %q", status)
+
+	events, err := s.repo.List(ctx, status, limit)
+	if err != nil {
+		logger.Errorf("[ListEvents] Error listing outbox events: %v", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *service) ReplayEvent(ctx context.Context, id int64) error {
+	logger.Infof("[ReplayEvent] Replaying outbox event %d", id)
+
+	if err := s.repo.Replay(ctx, id); err != nil {
+		logger.Errorf("[ReplayEvent] Error replaying outbox event: %v", err)
+		return err
+	}
+
+	return nil
+}
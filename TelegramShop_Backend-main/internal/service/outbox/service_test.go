@@ -0,0 +1,136 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/service/outbox"
+)
+
+// fakeRepository hands out every pending event on Claim, ignoring available_at.
+type fakeRepository struct {
+	repository.Repository
+	events map[int64]*models.OutboxEvent
+}
+
+func (r *fakeRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	for id := int64(1); id <= int64(len(r.events)); id++ {
+		event := r.events[id]
+		if event.Status != models.OutboxStatusPending || len(claimed) == limit {
+			continue
+		}
+		event.Attempts++
+		claimed = append(claimed, *event)
+	}
+	return claimed, nil
+}
+
+func (r *fakeRepository) MarkDone(ctx context.Context, id int64) error {
+	r.events[id].Status = models.OutboxStatusDone
+	return nil
+}
+
+func (r *fakeRepository) MarkFailed(ctx context.Context, id int64, lastError string, retryAfter time.Duration, dead bool) error {
+	r.events[id].LastError = lastError
+	r.events[id].AvailableAt = time.Now().Add(retryAfter)
+	if dead {
+		r.events[id].Status = models.OutboxStatusDead
+	}
+	return nil
+}
+
+func newFakeRepository(eventTypes ...string) *fakeRepository {
+	repo := &fakeRepository{events: map[int64]*models.OutboxEvent{}}
+	for i, eventType := range eventTypes {
+		id := int64(i + 1)
+		repo.events[id] = &models.OutboxEvent{ID: id, EventType: eventType, Status: models.OutboxStatusPending}
+	}
+	return repo
+}
+
+func testConfig() outbox.Config {
+	cfg := outbox.DefaultConfig()
+	cfg.MaxAttempts = 3
+	return cfg
+}
+
+func TestDispatchOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DeliversToRegisteredHandlers", func(t *testing.T) {
+		repo := newFakeRepository(models.EventOrderCreated, models.EventPriceChanged)
+		service := outbox.NewService(repo, testConfig())
+
+		var delivered []int64
+		service.Register(models.EventOrderCreated, func(ctx context.Context, event models.OutboxEvent) error {
+			delivered = append(delivered, event.ID)
+			return nil
+		})
+
+		claimed, err := service.DispatchOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, claimed)
+		require.Equal(t, []int64{1}, delivered)
+		// Events nobody listens to are still completed.
+		require.Equal(t, models.OutboxStatusDone, repo.events[2].Status)
+	})
+
+	t.Run("RetriesThenDeadLetters", func(t *testing.T) {
+		repo := newFakeRepository(models.EventOrderCreated)
+		service := outbox.NewService(repo, testConfig())
+		service.Register(models.EventOrderCreated, func(ctx context.Context, event models.OutboxEvent) error {
+			return errors.New("telegram is down")
+		})
+
+		for attempt := 1; attempt < 3; attempt++ {
+			_, err := service.DispatchOnce(ctx)
+			require.NoError(t, err)
+			require.Equal(t, models.OutboxStatusPending, repo.events[1].Status)
+			require.True(t, repo.events[1].AvailableAt.After(time.Now()))
+		}
+
+		_, err := service.DispatchOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, models.OutboxStatusDead, repo.events[1].Status)
+		require.Equal(t, "telegram is down", repo.events[1].LastError)
+	})
+
+	t.Run("FailingHandlerDoesNotBlockOthers", func(t *testing.T) {
+		repo := newFakeRepository(models.EventOrderCreated)
+		service := outbox.NewService(repo, testConfig())
+		var delivered int
+		service.Register(models.EventOrderCreated, func(ctx context.Context, event models.OutboxEvent) error {
+			return errors.New("telegram is down")
+		})
+		service.Register(models.EventOrderCreated, func(ctx context.Context, event models.OutboxEvent) error {
+			delivered++
+			return nil
+		})
+
+		_, err := service.DispatchOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, delivered)
+		require.Equal(t, models.OutboxStatusPending, repo.events[1].Status)
+		require.Equal(t, "telegram is down", repo.events[1].LastError)
+	})
+
+	t.Run("HandlerPanic", func(t *testing.T) {
+		repo := newFakeRepository(models.EventOrderCreated)
+		service := outbox.NewService(repo, testConfig())
+		service.Register(models.EventOrderCreated, func(ctx context.Context, event models.OutboxEvent) error {
+			panic("boom")
+		})
+
+		_, err := service.DispatchOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, models.OutboxStatusPending, repo.events[1].Status)
+		require.Contains(t, repo.events[1].LastError, "boom")
+	})
+}
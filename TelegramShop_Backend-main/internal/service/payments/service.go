@@ -0,0 +1,346 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/payments"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/service/orders"
+	"telegramshop_backend/internal/service/pricing"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/money"
+	"telegramshop_backend/pkg/telegram"
+)
+
+const payloadPrefix = "order:"
+
+var (
+	ErrNotOrderOwner   = orders.ErrNotOrderOwner
+	ErrOrderNotFound   = orders.ErrOrderNotFound
+	ErrOrderNotPayable = errors.New("order is not awaiting payment")
+	ErrInvalidPayload  = errors.New("invoice payload does not reference an order")
+)
+
+type Config struct {
+	ProviderToken string
+	// Currency is a three-letter ISO 4217 code. Amounts are sent in its minor units (kopecks for RUB).
+	Currency string
+}
+
+type Service interface {
+	CreateInvoiceLink(ctx context.Context, orderID int, userID int64) (string, error)
+	SendInvoice(ctx context.Context, orderID int, user models.User) error
+	HandleUpdate(ctx context.Context, update telegram.Update) error
+}
+
+type service struct {
+	repo           payments.Repository
+	orderService   orders.Service
+	productsRepo   products.Repository
+	pricingService pricing.Service
+	bot            telegram.BotAPI
+	cfg            Config
+}
+
+func NewService(repo payments.Repository, orderService orders.Service, productsRepo products.Repository, pricingService pricing.Service, bot telegram.BotAPI, cfg Config) Service {
+	return &service{
+		repo:           repo,
+		orderService:   orderService,
+		productsRepo:   productsRepo,
+		pricingService: pricingService,
+		bot:            bot,
+		cfg:            cfg,
+	}
+}
+
+func (s *service) CreateInvoiceLink(ctx context.Context, orderID int, userID int64) (string, error) {
+	logger.Infof("[CreateInvoiceLink] Creating invoice link for order %d", orderID)
+
+	invoice, err := s.payableInvoice(ctx, orderID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	link, err := s.bot.CreateInvoiceLink(ctx, invoice)
+	if err != nil {
+		logger.Errorf("[CreateInvoiceLink] Error creating invoice link: %v", err)
+		return "", err
+	}
+
+	return link, nil
+}
+
+func (s *service) SendInvoice(ctx context.Context, orderID int, user models.User) error {
+	logger.Infof("[SendInvoice] Sending invoice for order %d to chat %d", orderID, user.TelegramID)
+
+	invoice, err := s.payableInvoice(ctx, orderID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.bot.SendInvoice(ctx, user.TelegramID, invoice); err != nil {
+		logger.Errorf("[SendInvoice] Error sending invoice: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// payableInvoice loads the user's pending order and builds its invoice.
+func (s *service) payableInvoice(ctx context.Context, orderID int, userID int64) (telegram.Invoice, error) {
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return telegram.Invoice{}, err
+	}
+	if order.ID == 0 {
+		return telegram.Invoice{}, ErrOrderNotFound
+	}
+	if order.UserID != userID {
+		return telegram.Invoice{}, ErrNotOrderOwner
+	}
+	if order.Status != models.OrderStatusPending {
+		return telegram.Invoice{}, fmt.Errorf("%w: order is %s", ErrOrderNotPayable, order.Status)
+	}
+
+	names := make(map[int]string, len(order.Products))
+	for _, line := range order.Products {
+		product, err := s.productsRepo.GetProductByID(ctx, int64(line.ProductID))
+		if err != nil {
+			return telegram.Invoice{}, err
+		}
+		names[line.ProductID] = product.Name
+	}
+
+	return NewInvoice(order, names, s.cfg), nil
+}
+
+// NewInvoice builds the Telegram invoice for an order, one price line per product
+// followed by a negative line per promo code discount.
+func NewInvoice(order models.OrderWithProducts, productNames map[int]string, cfg Config) telegram.Invoice {
+	labels := make([]telegram.LabeledPrice, 0, len(order.Products)+len(order.Discounts))
+	for _, line := range order.Products {
+		name := productNames[line.ProductID]
+		if name == "" {
+			name = "Product #" + strconv.Itoa(line.ProductID)
+		}
+		labels = append(labels, telegram.LabeledPrice{
+			Label:  fmt.Sprintf("%s × %d", name, line.Quantity),
+			Amount: lineAmount(line),
+		})
+	}
+	for _, discount := range order.Discounts {
+		label := "Promo code " + discount.Code
+		if discount.ProductID != nil {
+			if name := productNames[*discount.ProductID]; name != "" {
+				label += ": " + name
+			}
+		}
+		labels = append(labels, telegram.LabeledPrice{
+			Label:  label,
+			Amount: -money.MinorUnits(discount.Amount),
+		})
+	}
+
+	return telegram.Invoice{
+		Title:         fmt.Sprintf("Order #%d", order.ID),
+		Description:   fmt.Sprintf("Payment for order #%d", order.ID),
+		Payload:       payloadPrefix + strconv.FormatInt(order.ID, 10),
+		ProviderToken: cfg.ProviderToken,
+		Currency:      cfg.Currency,
+		Prices:        labels,
+	}
+}
+
+// HandleUpdate reacts to the payment-related parts of a webhook update and ignores the rest.
+func (s *service) HandleUpdate(ctx context.Context, update telegram.Update) error {
+	switch {
+	case update.PreCheckoutQuery != nil:
+		return s.handlePreCheckout(ctx, *update.PreCheckoutQuery)
+	case update.Message != nil && update.Message.SuccessfulPayment != nil:
+		return s.handleSuccessfulPayment(ctx, *update.Message.SuccessfulPayment)
+	default:
+		return nil
+	}
+}
+
+// handlePreCheckout answers the query only after re-checking the order against current stock and prices.
+func (s *service) handlePreCheckout(ctx context.Context, query telegram.PreCheckoutQuery) error {
+	logger.Infof("[handlePreCheckout] Pre-checkout query %s for %s", query.ID, query.InvoicePayload)
+
+	reason, err := s.validatePreCheckout(ctx, query)
+	if err != nil {
+		logger.Errorf("[handlePreCheckout] Error validating order: %v", err)
+		reason = "Order cannot be paid right now, please try again later"
+	}
+	if reason != "" {
+		logger.Infof("[handlePreCheckout] Rejecting pre-checkout query %s: %s", query.ID, reason)
+	}
+
+	if err := s.bot.AnswerPreCheckoutQuery(ctx, query.ID, reason == "", reason); err != nil {
+		logger.Errorf("[handlePreCheckout] Error answering pre-checkout query: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// validatePreCheckout returns a user-facing reason to reject the payment, or "" if it may proceed.
+func (s *service) validatePreCheckout(ctx context.Context, query telegram.PreCheckoutQuery) (string, error) {
+	orderID, err := parsePayload(query.InvoicePayload)
+	if err != nil {
+		return "Unknown order", nil
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+	if order.ID == 0 {
+		return "Order not found", nil
+	}
+	if order.Status != models.OrderStatusPending {
+		return "Order is no longer awaiting payment", nil
+	}
+	if query.Currency != s.cfg.Currency || query.TotalAmount != InvoiceTotal(order) {
+		return "Order total has changed, please open the invoice again", nil
+	}
+
+	for _, line := range order.Products {
+		// Stock was reserved when the order was created; a negative value means it was
+		// corrected by hand afterwards and the reservation can no longer be honoured.
+		product, err := s.productsRepo.GetProductByID(ctx, int64(line.ProductID))
+		if err != nil {
+			return "", err
+		}
+		if product.ID == 0 || product.Stock < 0 {
+			return "Some products are out of stock", nil
+		}
+
+		if line.VariantID != nil {
+			variant, err := s.productsRepo.GetVariantByID(ctx, int64(*line.VariantID))
+			if errors.Is(err, products.ErrVariantNotFound) {
+				return "Some products are out of stock", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			if variant.Stock < 0 {
+				return "Some products are out of stock", nil
+			}
+		}
+	}
+
+	current, err := s.pricingService.OrderPricesCurrent(ctx, order.Products)
+	if err != nil {
+		return "", err
+	}
+	if !current {
+		return "Prices have changed, please place the order again", nil
+	}
+
+	return "", nil
+}
+
+func (s *service) handleSuccessfulPayment(ctx context.Context, payment telegram.SuccessfulPayment) error {
+	logger.Infof("[handleSuccessfulPayment] Payment %s for %s", payment.TelegramPaymentChargeID, payment.InvoicePayload)
+
+	orderID, err := parsePayload(payment.InvoicePayload)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	// The money is taken either way, so the charge is kept for manual review instead of failing
+	// the update, which Telegram would redeliver forever.
+	if order.ID == 0 {
+		logger.Errorf("[handleSuccessfulPayment] Payment %s of %d %s is for missing order %d, manual review required",
+			payment.TelegramPaymentChargeID, payment.TotalAmount, payment.Currency, orderID)
+		_, err := s.repo.CreatePayment(ctx, models.Payment{
+			Currency:                payment.Currency,
+			TotalAmount:             payment.TotalAmount,
+			TelegramPaymentChargeID: payment.TelegramPaymentChargeID,
+			ProviderPaymentChargeID: payment.ProviderPaymentChargeID,
+			Mismatch:                true,
+		})
+		if err != nil {
+			logger.Errorf("[handleSuccessfulPayment] Error storing payment: %v", err)
+		}
+		return err
+	}
+	// Telegram charges what the invoice said, so a different amount means the invoice was not ours.
+	mismatch := payment.Currency != s.cfg.Currency || payment.TotalAmount != InvoiceTotal(order)
+
+	inserted, err := s.repo.CreatePayment(ctx, models.Payment{
+		OrderID:                 int64(orderID),
+		Currency:                payment.Currency,
+		TotalAmount:             payment.TotalAmount,
+		TelegramPaymentChargeID: payment.TelegramPaymentChargeID,
+		ProviderPaymentChargeID: payment.ProviderPaymentChargeID,
+		Mismatch:                mismatch,
+	})
+	if err != nil {
+		logger.Errorf("[handleSuccessfulPayment] Error storing payment: %v", err)
+		return err
+	}
+	if !inserted {
+		logger.Infof("[handleSuccessfulPayment] Payment %s was already stored", payment.TelegramPaymentChargeID)
+	}
+
+	if mismatch {
+		logger.Errorf("[handleSuccessfulPayment] Payment %s of %d %s does not match order %d invoice of %d %s, manual review required",
+			payment.TelegramPaymentChargeID, payment.TotalAmount, payment.Currency, orderID, InvoiceTotal(order), s.cfg.Currency)
+		return nil
+	}
+
+	// Telegram redelivers the update if we fail below, so the status change is retried
+	// even when the payment row already exists.
+	if order.Status != models.OrderStatusPending {
+		if inserted {
+			logger.Errorf("[handleSuccessfulPayment] Order %d was paid while %s, manual refund required", orderID, order.Status)
+		}
+		return nil
+	}
+
+	_, err = s.orderService.UpdateStatus(ctx, orderID, models.OrderStatusPaid, 0, "Telegram payment "+payment.TelegramPaymentChargeID)
+	if err != nil && !errors.Is(err, orders.ErrStatusConflict) {
+		logger.Errorf("[handleSuccessfulPayment] Error marking order %d as paid: %v", orderID, err)
+		return err
+	}
+
+	return nil
+}
+
+// InvoiceTotal is the amount Telegram will charge for the order's invoice, in minor units.
+func InvoiceTotal(order models.OrderWithProducts) int64 {
+	var total int64
+	for _, line := range order.Products {
+		total += lineAmount(line)
+	}
+	for _, discount := range order.Discounts {
+		total -= money.MinorUnits(discount.Amount)
+	}
+	return total
+}
+
+func lineAmount(line models.OrderProduct) int64 {
+	return money.MinorUnits(line.Price * float64(line.Quantity))
+}
+
+func parsePayload(payload string) (int, error) {
+	if !strings.HasPrefix(payload, payloadPrefix) {
+		return 0, ErrInvalidPayload
+	}
+	orderID, err := strconv.Atoi(strings.TrimPrefix(payload, payloadPrefix))
+	if err != nil {
+		return 0, ErrInvalidPayload
+	}
+	return orderID, nil
+}
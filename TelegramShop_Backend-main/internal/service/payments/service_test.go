@@ -0,0 +1,287 @@
+package payments_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	paymentsRepo "telegramshop_backend/internal/repository/payments"
+	pricesRepo "telegramshop_backend/internal/repository/prices"
+	productsRepo "telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/service/orders"
+	"telegramshop_backend/internal/service/payments"
+	"telegramshop_backend/internal/service/pricing"
+	"telegramshop_backend/pkg/telegram"
+)
+
+const testBotToken = "123456:TEST-bot-token"
+
+// fakeTelegram is an in-process Bot API that records every call.
+type fakeTelegram struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls map[string][]map[string]interface{}
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	f := &fakeTelegram{calls: map[string][]map[string]interface{}{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+
+		method := strings.TrimPrefix(r.URL.Path, "/bot"+testBotToken+"/")
+		f.mu.Lock()
+		f.calls[method] = append(f.calls[method], params)
+		f.mu.Unlock()
+
+		if method == "createInvoiceLink" {
+			w.Write([]byte(`{"ok":true,"result":"https://t.me/$test"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeTelegram) lastCall(method string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := f.calls[method]
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls[len(calls)-1]
+}
+
+type fakeOrderService struct {
+	orders.Service
+	orders map[int]models.OrderWithProducts
+}
+
+func (s *fakeOrderService) GetOrderByID(ctx context.Context, id int) (models.OrderWithProducts, error) {
+	return s.orders[id], nil
+}
+
+func (s *fakeOrderService) UpdateStatus(ctx context.Context, orderID int, status string, actorID int64, comment string) (models.OrderWithProducts, error) {
+	order := s.orders[orderID]
+	order.Status = status
+	s.orders[orderID] = order
+	return order, nil
+}
+
+type fakeProductsRepo struct {
+	productsRepo.Repository
+	products map[int64]models.Product
+}
+
+func (r *fakeProductsRepo) GetProductByID(ctx context.Context, id int64) (models.Product, error) {
+	return r.products[id], nil
+}
+
+func (r *fakeProductsRepo) GetVariantsByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductVariant, error) {
+	return nil, nil
+}
+
+type fakePricesRepo struct {
+	pricesRepo.Repository
+	prices map[int64][]models.Price
+}
+
+func (r *fakePricesRepo) GetPricesByProductIDs(ctx context.Context, productIDs []int64) ([]models.Price, error) {
+	var found []models.Price
+	for _, id := range productIDs {
+		found = append(found, r.prices[id]...)
+	}
+	return found, nil
+}
+
+type fakePaymentsRepo struct {
+	paymentsRepo.Repository
+	payments map[string]models.Payment
+}
+
+func (r *fakePaymentsRepo) CreatePayment(ctx context.Context, payment models.Payment) (bool, error) {
+	if _, ok := r.payments[payment.TelegramPaymentChargeID]; ok {
+		return false, nil
+	}
+	r.payments[payment.TelegramPaymentChargeID] = payment
+	return true, nil
+}
+
+type fixture struct {
+	service  payments.Service
+	telegram *fakeTelegram
+	orders   *fakeOrderService
+	prices   *fakePricesRepo
+	payments *fakePaymentsRepo
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := &fixture{
+		telegram: newFakeTelegram(t),
+		orders: &fakeOrderService{orders: map[int]models.OrderWithProducts{
+			7: {ID: 7, UserID: 1, Status: models.OrderStatusPending, TotalAmount: 270.5, Products: []models.OrderProduct{
+				{ProductID: 3, Quantity: 2, Price: 90.25},
+				{ProductID: 4, Quantity: 1, Price: 90},
+			}},
+		}},
+		prices: &fakePricesRepo{prices: map[int64][]models.Price{
+			3: {{ProductID: 3, Count: 1, Price: 90.25}, {ProductID: 3, Count: 10, Price: 80}},
+			4: {{ProductID: 4, Count: 1, Price: 90}},
+		}},
+		payments: &fakePaymentsRepo{payments: map[string]models.Payment{}},
+	}
+	products := &fakeProductsRepo{products: map[int64]models.Product{
+		3: {ID: 3, Name: "Green tea", Stock: 5},
+		4: {ID: 4, Name: "Teapot", Stock: 1},
+	}}
+
+	bot := telegram.NewBotClient(testBotToken, f.telegram.URL)
+	f.service = payments.NewService(f.payments, f.orders, products, pricing.NewService(f.prices, products), bot, payments.Config{
+		ProviderToken: "provider-token",
+		Currency:      "RUB",
+	})
+	return f
+}
+
+func preCheckout(total int64) telegram.Update {
+	return telegram.Update{PreCheckoutQuery: &telegram.PreCheckoutQuery{
+		ID:             "query-1",
+		Currency:       "RUB",
+		TotalAmount:    total,
+		InvoicePayload: "order:7",
+	}}
+}
+
+func TestCreateInvoiceLink(t *testing.T) {
+	f := newFixture(t)
+
+	link, err := f.service.CreateInvoiceLink(context.Background(), 7, 1)
+	require.NoError(t, err)
+	require.Equal(t, "https://t.me/$test", link)
+
+	call := f.telegram.lastCall("createInvoiceLink")
+	require.Equal(t, "order:7", call["payload"])
+	require.Equal(t, "provider-token", call["provider_token"])
+	require.Len(t, call["prices"], 2)
+
+	_, err = f.service.CreateInvoiceLink(context.Background(), 7, 2)
+	require.ErrorIs(t, err, payments.ErrNotOrderOwner)
+}
+
+func TestInvoiceWithDiscounts(t *testing.T) {
+	productID := 3
+	order := models.OrderWithProducts{ID: 8, Products: []models.OrderProduct{
+		{ProductID: 3, Quantity: 2, Price: 90.25},
+		{ProductID: 4, Quantity: 1, Price: 90},
+	}, Discounts: []models.OrderDiscount{
+		{Code: "TEA10", ProductID: &productID, Amount: 18.05},
+		{Code: "MINUS50", Amount: 50},
+	}}
+
+	invoice := payments.NewInvoice(order, map[int]string{3: "Green tea"}, payments.Config{Currency: "RUB"})
+	require.Len(t, invoice.Prices, 4)
+	require.Equal(t, "Promo code TEA10: Green tea", invoice.Prices[2].Label)
+	require.Equal(t, int64(-1805), invoice.Prices[2].Amount)
+	require.Equal(t, "Promo code MINUS50", invoice.Prices[3].Label)
+	require.Equal(t, int64(-5000), invoice.Prices[3].Amount)
+	require.Equal(t, int64(20245), payments.InvoiceTotal(order))
+}
+
+func TestPreCheckout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Accepted", func(t *testing.T) {
+		f := newFixture(t)
+		require.NoError(t, f.service.HandleUpdate(ctx, preCheckout(27050)))
+		require.Equal(t, true, f.telegram.lastCall("answerPreCheckoutQuery")["ok"])
+	})
+
+	t.Run("TotalMismatch", func(t *testing.T) {
+		f := newFixture(t)
+		require.NoError(t, f.service.HandleUpdate(ctx, preCheckout(100)))
+		require.Equal(t, false, f.telegram.lastCall("answerPreCheckoutQuery")["ok"])
+	})
+
+	t.Run("PriceChanged", func(t *testing.T) {
+		f := newFixture(t)
+		f.prices.prices[4] = []models.Price{{ProductID: 4, Count: 1, Price: 120}}
+
+		require.NoError(t, f.service.HandleUpdate(ctx, preCheckout(27050)))
+		call := f.telegram.lastCall("answerPreCheckoutQuery")
+		require.Equal(t, false, call["ok"])
+		require.NotEmpty(t, call["error_message"])
+	})
+
+	t.Run("AlreadyPaid", func(t *testing.T) {
+		f := newFixture(t)
+		order := f.orders.orders[7]
+		order.Status = models.OrderStatusPaid
+		f.orders.orders[7] = order
+
+		require.NoError(t, f.service.HandleUpdate(ctx, preCheckout(27050)))
+		require.Equal(t, false, f.telegram.lastCall("answerPreCheckoutQuery")["ok"])
+	})
+}
+
+func TestSuccessfulPayment(t *testing.T) {
+	f := newFixture(t)
+	update := telegram.Update{Message: &telegram.Message{SuccessfulPayment: &telegram.SuccessfulPayment{
+		Currency:                "RUB",
+		TotalAmount:             27050,
+		InvoicePayload:          "order:7",
+		TelegramPaymentChargeID: "tg-charge",
+		ProviderPaymentChargeID: "provider-charge",
+	}}}
+
+	require.NoError(t, f.service.HandleUpdate(context.Background(), update))
+	require.Equal(t, models.OrderStatusPaid, f.orders.orders[7].Status)
+	require.Equal(t, "provider-charge", f.payments.payments["tg-charge"].ProviderPaymentChargeID)
+
+	// Telegram may deliver the same update twice.
+	require.NoError(t, f.service.HandleUpdate(context.Background(), update))
+	require.Len(t, f.payments.payments, 1)
+}
+
+func TestSuccessfulPaymentMismatch(t *testing.T) {
+	for name, payment := range map[string]telegram.SuccessfulPayment{
+		"Amount":   {Currency: "RUB", TotalAmount: 100},
+		"Currency": {Currency: "USD", TotalAmount: 27050},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			payment.InvoicePayload = "order:7"
+			payment.TelegramPaymentChargeID = "tg-charge"
+
+			update := telegram.Update{Message: &telegram.Message{SuccessfulPayment: &payment}}
+			require.NoError(t, f.service.HandleUpdate(context.Background(), update))
+			require.Equal(t, models.OrderStatusPending, f.orders.orders[7].Status)
+			require.True(t, f.payments.payments["tg-charge"].Mismatch)
+		})
+	}
+}
+
+func TestSuccessfulPaymentForMissingOrder(t *testing.T) {
+	f := newFixture(t)
+	update := telegram.Update{Message: &telegram.Message{SuccessfulPayment: &telegram.SuccessfulPayment{
+		Currency:                "RUB",
+		TotalAmount:             27050,
+		InvoicePayload:          "order:99",
+		TelegramPaymentChargeID: "tg-charge",
+		ProviderPaymentChargeID: "provider-charge",
+	}}}
+
+	require.NoError(t, f.service.HandleUpdate(context.Background(), update))
+	stored := f.payments.payments["tg-charge"]
+	require.Zero(t, stored.OrderID, "the payment is kept without an order")
+	require.True(t, stored.Mismatch)
+	require.Equal(t, int64(27050), stored.TotalAmount)
+}
@@ -0,0 +1,161 @@
+package pricing
+
+import (
+	"context"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/pricing"
+	"telegramshop_backend/internal/repository/prices"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/money"
+)
+
+// Service prices basket, favorites and order lines with pricing.Quote, the rule order creation charges by.
+type Service interface {
+	// PriceBasket prices every line and totals the ones without a warning.
+	PriceBasket(ctx context.Context, lines []models.BasketLine) (models.Basket, error)
+	// PriceFavorites sets the single-item price of every favorite that can be bought alone.
+	PriceFavorites(ctx context.Context, favorites []models.FavoriteLine) error
+	// OrderPricesCurrent reports whether every order line would still be charged the unit
+	// price the order stored.
+	OrderPricesCurrent(ctx context.Context, lines []models.OrderProduct) (bool, error)
+}
+
+type service struct {
+	pricesRepo   prices.Repository
+	productsRepo products.Repository
+}
+
+func NewService(pricesRepo prices.Repository, productsRepo products.Repository) Service {
+	return &service{
+		pricesRepo:   pricesRepo,
+		productsRepo: productsRepo,
+	}
+}
+
+func (s *service) PriceBasket(ctx context.Context, lines []models.BasketLine) (models.Basket, error) {
+	basket := models.Basket{Items: lines}
+
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, int64(line.ProductID))
+	}
+	catalog, err := s.loadCatalog(ctx, productIDs)
+	if err != nil {
+		logger.Errorf("[PriceBasket] Error loading prices: %v", err)
+		return models.Basket{}, err
+	}
+
+	for i := range basket.Items {
+		line := &basket.Items[i]
+		price, priced := catalog.quote(line.ProductID, line.VariantID, line.Quantity)
+		line.LinePrice, line.Priced = price, priced
+		if priced && line.Warning == "" {
+			basket.Subtotal += price.LineTotal
+			basket.Savings += price.Savings
+		}
+	}
+
+	basket.Subtotal = money.Round(basket.Subtotal)
+	basket.Savings = money.Round(basket.Savings)
+	return basket, nil
+}
+
+func (s *service) PriceFavorites(ctx context.Context, favorites []models.FavoriteLine) error {
+	productIDs := make([]int64, 0, len(favorites))
+	for _, favorite := range favorites {
+		productIDs = append(productIDs, int64(favorite.ProductID))
+	}
+	catalog, err := s.loadCatalog(ctx, productIDs)
+	if err != nil {
+		logger.Errorf("[PriceFavorites] Error loading prices: %v", err)
+		return err
+	}
+
+	for i := range favorites {
+		favorite := &favorites[i]
+		if price, priced := catalog.quote(favorite.ProductID, favorite.VariantID, 1); priced {
+			favorite.Price = &price.UnitPrice
+		}
+	}
+
+	return nil
+}
+
+func (s *service) OrderPricesCurrent(ctx context.Context, lines []models.OrderProduct) (bool, error) {
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, int64(line.ProductID))
+	}
+	catalog, err := s.loadCatalog(ctx, productIDs)
+	if err != nil {
+		logger.Errorf("[OrderPricesCurrent] Error loading prices: %v", err)
+		return false, err
+	}
+
+	for _, line := range lines {
+		price, priced := catalog.quote(line.ProductID, line.VariantID, line.Quantity)
+		if !priced || money.MinorUnits(price.UnitPrice) != money.MinorUnits(line.Price) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// catalog holds the price tiers and variants of the products being priced, keyed by product id.
+type catalog struct {
+	tiers    map[int][]models.Price
+	variants map[int][]models.ProductVariant
+}
+
+// loadCatalog reads the tiers and variants of all the products with one query each.
+func (s *service) loadCatalog(ctx context.Context, productIDs []int64) (catalog, error) {
+	c := catalog{tiers: map[int][]models.Price{}, variants: map[int][]models.ProductVariant{}}
+	if len(productIDs) == 0 {
+		return c, nil
+	}
+
+	tiers, err := s.pricesRepo.GetPricesByProductIDs(ctx, productIDs)
+	if err != nil {
+		return catalog{}, err
+	}
+	for _, tier := range tiers {
+		c.tiers[int(tier.ProductID)] = append(c.tiers[int(tier.ProductID)], tier)
+	}
+
+	variants, err := s.productsRepo.GetVariantsByProductIDs(ctx, productIDs)
+	if err != nil {
+		return catalog{}, err
+	}
+	for _, variant := range variants {
+		c.variants[int(variant.ProductID)] = append(c.variants[int(variant.ProductID)], variant)
+	}
+
+	return c, nil
+}
+
+// quote prices quantity units of the product or its variant the way checkout would.
+// priced is false when checkout would drop the line: an unknown variant, a missing
+// variant for a product sold in variants, or no tier for the quantity.
+func (c catalog) quote(productID int, variantID *int, quantity int) (models.LinePrice, bool) {
+	variants := c.variants[productID]
+	var variantPrice *float64
+	if variantID != nil {
+		found := false
+		for _, variant := range variants {
+			if variant.ID == int64(*variantID) {
+				variantPrice, found = variant.Price, true
+				break
+			}
+		}
+		if !found {
+			return models.LinePrice{}, false
+		}
+	} else if len(variants) > 0 {
+		return models.LinePrice{}, false
+	}
+
+	return pricing.Quote(c.tiers[productID], variantPrice, quantity)
+}
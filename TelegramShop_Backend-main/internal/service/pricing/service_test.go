@@ -0,0 +1,138 @@
+package pricing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/prices"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/service/pricing"
+)
+
+type fakePricesRepo struct {
+	prices.Repository
+	prices map[int64][]models.Price
+	calls  int
+}
+
+func (r *fakePricesRepo) GetPricesByProductIDs(ctx context.Context, productIDs []int64) ([]models.Price, error) {
+	r.calls++
+	var found []models.Price
+	for productID, tiers := range r.prices {
+		for _, id := range productIDs {
+			if productID == id {
+				found = append(found, tiers...)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+type fakeProductsRepo struct {
+	products.Repository
+	variants map[int64]models.ProductVariant
+	calls    int
+}
+
+func (r *fakeProductsRepo) GetVariantsByProductIDs(ctx context.Context, productIDs []int64) ([]models.ProductVariant, error) {
+	r.calls++
+	var variants []models.ProductVariant
+	for _, variant := range r.variants {
+		for _, id := range productIDs {
+			if variant.ProductID == id {
+				variants = append(variants, variant)
+				break
+			}
+		}
+	}
+	return variants, nil
+}
+
+func TestPriceBasket(t *testing.T) {
+	pricesRepo := &fakePricesRepo{prices: map[int64][]models.Price{
+		3: {{ProductID: 3, Count: 1, Price: 90.25}, {ProductID: 3, Count: 10, Price: 80}},
+		4: {{ProductID: 4, Count: 5, Price: 40}},
+		5: {{ProductID: 5, Count: 1, Price: 300}},
+	}}
+	variantPrice := 350.0
+	productsRepo := &fakeProductsRepo{variants: map[int64]models.ProductVariant{
+		70: {ID: 70, ProductID: 5, Price: &variantPrice},
+		71: {ID: 71, ProductID: 5},
+	}}
+	v70, v71, v99 := 70, 71, 99
+
+	line := func(productID int, variantID *int, quantity int) models.BasketLine {
+		return models.BasketLine{BasketItem: models.BasketItem{ProductID: productID, VariantID: variantID, Quantity: quantity}}
+	}
+	outOfStock := line(3, nil, 1)
+	outOfStock.Warning = models.BasketWarningInsufficientStock
+
+	basket, err := pricing.NewService(pricesRepo, productsRepo).PriceBasket(context.Background(), []models.BasketLine{
+		line(3, nil, 10),
+		line(3, nil, 2),
+		line(4, nil, 1),
+		line(5, &v70, 1),
+		line(5, &v71, 2),
+		line(5, nil, 1),
+		line(5, &v99, 1),
+		outOfStock,
+	})
+	require.NoError(t, err)
+	require.Len(t, basket.Items, 8)
+
+	require.True(t, basket.Items[0].Priced)
+	require.Equal(t, 80.0, basket.Items[0].UnitPrice)
+	require.Equal(t, 102.5, basket.Items[0].Savings)
+	require.Equal(t, 180.5, basket.Items[1].LineTotal)
+	require.False(t, basket.Items[2].Priced, "no tier for a single unit")
+	require.Equal(t, 350.0, basket.Items[3].UnitPrice)
+	require.Equal(t, 600.0, basket.Items[4].LineTotal, "variant without a price uses the product tiers")
+	require.False(t, basket.Items[5].Priced, "product with variants needs variant_id")
+	require.False(t, basket.Items[6].Priced, "unknown variant")
+	require.True(t, basket.Items[7].Priced)
+
+	require.Equal(t, 800+180.5+350+600, basket.Subtotal, "lines with a warning are not counted")
+	require.Equal(t, 102.5, basket.Savings)
+	require.Equal(t, 1, pricesRepo.calls, "tiers are loaded once for the whole basket")
+	require.Equal(t, 1, productsRepo.calls, "variants are loaded once for the whole basket")
+}
+
+func TestPriceFavorites(t *testing.T) {
+	pricesRepo := &fakePricesRepo{prices: map[int64][]models.Price{
+		3: {{ProductID: 3, Count: 1, Price: 90.25}, {ProductID: 3, Count: 10, Price: 80}},
+		4: {{ProductID: 4, Count: 5, Price: 40}},
+	}}
+	favorites := []models.FavoriteLine{
+		{Favorite: models.Favorite{ProductID: 3}},
+		{Favorite: models.Favorite{ProductID: 4}},
+	}
+
+	err := pricing.NewService(pricesRepo, &fakeProductsRepo{}).PriceFavorites(context.Background(), favorites)
+	require.NoError(t, err)
+	require.Equal(t, 90.25, *favorites[0].Price)
+	require.Nil(t, favorites[1].Price)
+}
+
+func TestOrderPricesCurrent(t *testing.T) {
+	ctx := context.Background()
+	pricesRepo := &fakePricesRepo{prices: map[int64][]models.Price{
+		3: {{ProductID: 3, Count: 1, Price: 90.25}, {ProductID: 3, Count: 10, Price: 80}},
+	}}
+	service := pricing.NewService(pricesRepo, &fakeProductsRepo{})
+
+	current, err := service.OrderPricesCurrent(ctx, []models.OrderProduct{{ProductID: 3, Quantity: 10, Price: 80}})
+	require.NoError(t, err)
+	require.True(t, current)
+
+	current, err = service.OrderPricesCurrent(ctx, []models.OrderProduct{{ProductID: 3, Quantity: 2, Price: 80}})
+	require.NoError(t, err)
+	require.False(t, current, "two units are charged the single-unit tier")
+
+	current, err = service.OrderPricesCurrent(ctx, []models.OrderProduct{{ProductID: 4, Quantity: 1, Price: 10}})
+	require.NoError(t, err)
+	require.False(t, current, "no tier left for the product")
+}
@@ -2,16 +2,39 @@ package products
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/products"
 	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/pagination"
 )
 
+var (
+	ErrUnknownSort       = products.ErrUnknownSort
+	ErrInvalidPriceRange = errors.New("min_price is greater than max_price")
+	ErrEmptyQuery        = errors.New("search query is empty")
+	ErrQueryTooLong      = fmt.Errorf("search query is longer than %d characters", maxSearchQueryLength)
+	ErrVariantNotFound   = products.ErrVariantNotFound
+	ErrDuplicateSKU      = products.ErrDuplicateSKU
+	ErrStockFromVariants = products.ErrStockFromVariants
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInvalidVariant    = errors.New("variant needs a sku, non-negative stock and a positive price")
+)
+
+const maxSearchQueryLength = 200
+
 type Service interface {
 	CreateProduct(ctx context.Context, input models.Product) (models.Product, error)
 	GetProductByID(ctx context.Context, id int64) (models.Product, error)
-	GetAllProducts(ctx context.Context) ([]models.Product, error)
+	ListProducts(ctx context.Context, filter models.ProductFilter, page pagination.Page) ([]models.Product, string, error)
+	GetFacets(ctx context.Context, categoryID *int64) ([]models.ProductFacet, error)
+	// Search ranks products by full-text match and falls back to fuzzy name matching
+	// when nothing matches, so a typo still finds something.
+	Search(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error)
 	UpdateProduct(ctx context.Context, id int64, input models.UpdateProductInput) error
 	DeleteProduct(ctx context.Context, id int64) error
 	AddProductImage(ctx context.Context, id int64, imageURL string) error
@@ -19,6 +42,11 @@ type Service interface {
 	SetProductImages(ctx context.Context, id int64, images []string) error
 	IncrementSellCount(ctx context.Context, productID int64, count int) error
 	UpdateStock(ctx context.Context, productID int64, stock int) error
+
+	GetVariants(ctx context.Context, productID int64) ([]models.ProductVariant, error)
+	CreateVariant(ctx context.Context, productID int64, input models.ProductVariantInput) (models.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, id int64, input models.ProductVariantInput) (models.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, id int64) error
 }
 
 type service struct {
@@ -53,16 +81,61 @@ func (s *service) GetProductByID(ctx context.Context, id int64) (models.Product,
 	return product, nil
 }
 
-func (s *service) GetAllProducts(ctx context.Context) ([]models.Product, error) {
-	logger.Info("[GetAllProducts] Getting all products")
+func (s *service) ListProducts(ctx context.Context, filter models.ProductFilter, page pagination.Page) ([]models.Product, string, error) {
+	logger.Infof("[ListProducts] Listing products sorted by %q", filter.Sort)
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, "", ErrInvalidPriceRange
+	}
+
+	products, next, err := s.repo.ListProducts(ctx, filter, page)
+	if err != nil {
+		logger.Errorf("[ListProducts] Error listing products: %v", err)
+		return nil, "", err
+	}
+
+	return products, next, nil
+}
+
+func (s *service) GetFacets(ctx context.Context, categoryID *int64) ([]models.ProductFacet, error) {
+	logger.Info("[GetFacets] Getting product attribute facets")
+
+	facets, err := s.repo.GetFacets(ctx, categoryID)
+	if err != nil {
+		logger.Errorf("[GetFacets] Error getting facets: %v", err)
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func (s *service) Search(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error) {
+	text = strings.TrimSpace(text)
+	logger.Infof("[Search] Searching products for %q", text)
+
+	if text == "" {
+		return nil, ErrEmptyQuery
+	}
+	if utf8.RuneCountInString(text) > maxSearchQueryLength {
+		return nil, ErrQueryTooLong
+	}
+
+	results, err := s.repo.SearchFullText(ctx, text, limit)
+	if err != nil {
+		logger.Errorf("[Search] Error searching products: %v", err)
+		return nil, err
+	}
+	if len(results) > 0 {
+		return results, nil
+	}
 
-	products, err := s.repo.GetAllProducts(ctx)
+	results, err = s.repo.SearchFuzzy(ctx, text, limit)
 	if err != nil {
-		logger.Errorf("[GetAllProducts] Error getting products: %v", err)
+		logger.Errorf("[Search] Error searching products by similarity: %v", err)
 		return nil, err
 	}
 
-	return products, nil
+	return results, nil
 }
 
 func (s *service) UpdateProduct(ctx context.Context, id int64, input models.UpdateProductInput) error {
@@ -148,3 +221,74 @@ func (s *service) UpdateStock(ctx context.Context, productID int64, stock int) e
 
 	return nil
 }
+
+func (s *service) GetVariants(ctx context.Context, productID int64) ([]models.ProductVariant, error) {
+	logger.Infof("[GetVariants] Getting variants of product with id=%d", productID)
+
+	variants, err := s.repo.GetVariants(ctx, productID)
+	if err != nil {
+		logger.Errorf("[GetVariants] Error getting variants: %v", err)
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+func (s *service) CreateVariant(ctx context.Context, productID int64, input models.ProductVariantInput) (models.ProductVariant, error) {
+	logger.Infof("[CreateVariant] Creating variant sku=%s for product with id=%d", input.SKU, productID)
+
+	if err := validateVariant(&input); err != nil {
+		return models.ProductVariant{}, err
+	}
+	product, err := s.repo.GetProductByID(ctx, productID)
+	if err != nil {
+		logger.Errorf("[CreateVariant] Error getting product: %v", err)
+		return models.ProductVariant{}, err
+	}
+	if product.ID == 0 {
+		return models.ProductVariant{}, ErrProductNotFound
+	}
+
+	variant, err := s.repo.CreateVariant(ctx, productID, input)
+	if err != nil {
+		logger.Errorf("[CreateVariant] Error creating variant: %v", err)
+		return models.ProductVariant{}, err
+	}
+
+	return variant, nil
+}
+
+func (s *service) UpdateVariant(ctx context.Context, productID, id int64, input models.ProductVariantInput) (models.ProductVariant, error) {
+	logger.Infof("[UpdateVariant] Updating variant with id=%d of product with id=%d", id, productID)
+
+	if err := validateVariant(&input); err != nil {
+		return models.ProductVariant{}, err
+	}
+
+	variant, err := s.repo.UpdateVariant(ctx, productID, id, input)
+	if err != nil {
+		logger.Errorf("[UpdateVariant] Error updating variant: %v", err)
+		return models.ProductVariant{}, err
+	}
+
+	return variant, nil
+}
+
+func (s *service) DeleteVariant(ctx context.Context, productID, id int64) error {
+	logger.Infof("[DeleteVariant] Deleting variant with id=%d of product with id=%d", id, productID)
+
+	if err := s.repo.DeleteVariant(ctx, productID, id); err != nil {
+		logger.Errorf("[DeleteVariant] Error deleting variant: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func validateVariant(input *models.ProductVariantInput) error {
+	input.SKU = strings.TrimSpace(input.SKU)
+	if input.SKU == "" || input.Stock < 0 || (input.Price != nil && *input.Price <= 0) {
+		return ErrInvalidVariant
+	}
+	return nil
+}
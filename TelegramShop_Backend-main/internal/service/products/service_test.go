@@ -0,0 +1,103 @@
+package products_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/service/products"
+)
+
+type fakeRepository struct {
+	repository.Repository
+	fullText []models.ProductSearchResult
+	fuzzy    []models.ProductSearchResult
+	queries  []string
+	products map[int64]models.Product
+	variants []models.ProductVariantInput
+}
+
+func (r *fakeRepository) SearchFullText(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error) {
+	r.queries = append(r.queries, "fulltext:"+text)
+	return r.fullText, nil
+}
+
+func (r *fakeRepository) SearchFuzzy(ctx context.Context, text string, limit int) ([]models.ProductSearchResult, error) {
+	r.queries = append(r.queries, "fuzzy:"+text)
+	return r.fuzzy, nil
+}
+
+func (r *fakeRepository) GetProductByID(ctx context.Context, id int64) (models.Product, error) {
+	return r.products[id], nil
+}
+
+func (r *fakeRepository) CreateVariant(ctx context.Context, productID int64, input models.ProductVariantInput) (models.ProductVariant, error) {
+	r.variants = append(r.variants, input)
+	return models.ProductVariant{ID: int64(len(r.variants)), ProductID: productID, SKU: input.SKU, Stock: input.Stock, Price: input.Price}, nil
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	found := []models.ProductSearchResult{{Product: models.Product{ID: 1, Name: "iPhone 13"}}}
+
+	t.Run("FullTextMatch", func(t *testing.T) {
+		repo := &fakeRepository{fullText: found}
+		results, err := products.NewService(repo).Search(ctx, "  смартфоны ", 20)
+		require.NoError(t, err)
+		require.Equal(t, found, results)
+		require.Equal(t, []string{"fulltext:смартфоны"}, repo.queries)
+	})
+
+	t.Run("FallsBackToFuzzy", func(t *testing.T) {
+		repo := &fakeRepository{fuzzy: found}
+		results, err := products.NewService(repo).Search(ctx, "кросовки", 20)
+		require.NoError(t, err)
+		require.Equal(t, found, results)
+		require.Equal(t, []string{"fulltext:кросовки", "fuzzy:кросовки"}, repo.queries)
+	})
+
+	t.Run("InvalidQuery", func(t *testing.T) {
+		service := products.NewService(&fakeRepository{})
+
+		_, err := service.Search(ctx, "   ", 20)
+		require.ErrorIs(t, err, products.ErrEmptyQuery)
+
+		_, err = service.Search(ctx, strings.Repeat("я", 201), 20)
+		require.ErrorIs(t, err, products.ErrQueryTooLong)
+	})
+}
+
+func TestCreateVariant(t *testing.T) {
+	ctx := context.Background()
+	negative := -1.0
+
+	t.Run("Valid", func(t *testing.T) {
+		repo := &fakeRepository{products: map[int64]models.Product{1: {ID: 1}}}
+		variant, err := products.NewService(repo).CreateVariant(ctx, 1, models.ProductVariantInput{SKU: " AIRMAX-42 ", Stock: 3})
+		require.NoError(t, err)
+		require.Equal(t, "AIRMAX-42", variant.SKU)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		repo := &fakeRepository{products: map[int64]models.Product{1: {ID: 1}}}
+		service := products.NewService(repo)
+		for _, input := range []models.ProductVariantInput{
+			{SKU: "  ", Stock: 1},
+			{SKU: "A", Stock: -1},
+			{SKU: "A", Price: &negative},
+		} {
+			_, err := service.CreateVariant(ctx, 1, input)
+			require.ErrorIs(t, err, products.ErrInvalidVariant)
+		}
+		require.Empty(t, repo.variants)
+	})
+
+	t.Run("UnknownProduct", func(t *testing.T) {
+		_, err := products.NewService(&fakeRepository{}).CreateVariant(ctx, 7, models.ProductVariantInput{SKU: "A"})
+		require.ErrorIs(t, err, products.ErrProductNotFound)
+	})
+}
@@ -0,0 +1,151 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/promotions"
+	"telegramshop_backend/pkg/logger"
+)
+
+var (
+	ErrPromoCodeNotFound = promotions.ErrPromoCodeNotFound
+	ErrDuplicateCode     = promotions.ErrDuplicateCode
+	ErrUnknownReference  = promotions.ErrUnknownReference
+	ErrInvalidPromoCode  = errors.New("invalid promo code")
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+type Service interface {
+	CreatePromoCode(ctx context.Context, input models.PromoCodeInput) (models.PromoCode, error)
+	GetPromoCodes(ctx context.Context) ([]models.PromoCode, error)
+	GetPromoCodeByID(ctx context.Context, id int64) (models.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, id int64, input models.PromoCodeInput) (models.PromoCode, error)
+	DeletePromoCode(ctx context.Context, id int64) error
+}
+
+type service struct {
+	repo promotions.Repository
+}
+
+func NewService(repo promotions.Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) CreatePromoCode(ctx context.Context, input models.PromoCodeInput) (models.PromoCode, error) {
+	logger.Infof("[CreatePromoCode] Creating promo code %q", input.Code)
+
+	if err := validate(&input); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	code, err := s.repo.CreatePromoCode(ctx, input)
+	if err != nil {
+		logger.Errorf("[CreatePromoCode] Error creating promo code: %v", err)
+		return models.PromoCode{}, err
+	}
+
+	return code, nil
+}
+
+func (s *service) GetPromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	logger.Info("[GetPromoCodes] Getting promo codes")
+
+	codes, err := s.repo.GetPromoCodes(ctx)
+	if err != nil {
+		logger.Errorf("[GetPromoCodes] Error getting promo codes: %v", err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *service) GetPromoCodeByID(ctx context.Context, id int64) (models.PromoCode, error) {
+	logger.Infof("[GetPromoCodeByID] Getting promo code with id=%d", id)
+
+	code, err := s.repo.GetPromoCodeByID(ctx, id)
+	if err != nil {
+		logger.Errorf("[GetPromoCodeByID] Error getting promo code: %v", err)
+		return models.PromoCode{}, err
+	}
+
+	return code, nil
+}
+
+func (s *service) UpdatePromoCode(ctx context.Context, id int64, input models.PromoCodeInput) (models.PromoCode, error) {
+	logger.Infof("[UpdatePromoCode] Updating promo code with id=%d", id)
+
+	if err := validate(&input); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	code, err := s.repo.UpdatePromoCode(ctx, id, input)
+	if err != nil {
+		logger.Errorf("[UpdatePromoCode] Error updating promo code: %v", err)
+		return models.PromoCode{}, err
+	}
+
+	return code, nil
+}
+
+func (s *service) DeletePromoCode(ctx context.Context, id int64) error {
+	logger.Infof("[DeletePromoCode] Deleting promo code with id=%d", id)
+
+	if err := s.repo.DeletePromoCode(ctx, id); err != nil {
+		logger.Errorf("[DeletePromoCode] Error deleting promo code: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// validate normalizes the code and checks that the fields make sense for its kind.
+func validate(input *models.PromoCodeInput) error {
+	input.Code = promotions.NormalizeCode(input.Code)
+	if !codePattern.MatchString(input.Code) {
+		return fmt.Errorf("%w: code must be 3-32 letters, digits, '-' or '_'", ErrInvalidPromoCode)
+	}
+
+	switch input.Kind {
+	case models.PromoKindPercent:
+		if input.Value <= 0 || input.Value > 100 {
+			return fmt.Errorf("%w: percent value must be in (0, 100]", ErrInvalidPromoCode)
+		}
+	case models.PromoKindFixed:
+		if input.Value <= 0 {
+			return fmt.Errorf("%w: fixed value must be positive", ErrInvalidPromoCode)
+		}
+	case models.PromoKindFreeItem:
+		if input.ProductID == nil {
+			return fmt.Errorf("%w: free_item requires product_id", ErrInvalidPromoCode)
+		}
+		if input.Value != 0 || input.CategoryID != nil || input.FirmID != nil {
+			return fmt.Errorf("%w: free_item takes no value, category_id or firm_id", ErrInvalidPromoCode)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be percent, fixed or free_item", ErrInvalidPromoCode)
+	}
+	if input.Kind != models.PromoKindFreeItem && input.ProductID != nil {
+		return fmt.Errorf("%w: product_id is only used by free_item", ErrInvalidPromoCode)
+	}
+
+	if input.MinOrderTotal < 0 {
+		return fmt.Errorf("%w: min_order_total cannot be negative", ErrInvalidPromoCode)
+	}
+	if (input.MaxUses != nil && *input.MaxUses <= 0) || (input.MaxUsesPerUser != nil && *input.MaxUsesPerUser <= 0) {
+		return fmt.Errorf("%w: usage limits must be positive", ErrInvalidPromoCode)
+	}
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromoCode)
+	}
+
+	if input.Active == nil {
+		active := true
+		input.Active = &active
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package promotions_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/promotions"
+	"telegramshop_backend/internal/service/promotions"
+)
+
+// fakeRepository records the input that reached the repository.
+type fakeRepository struct {
+	repository.Repository
+	created []models.PromoCodeInput
+}
+
+func (r *fakeRepository) CreatePromoCode(ctx context.Context, input models.PromoCodeInput) (models.PromoCode, error) {
+	r.created = append(r.created, input)
+	return models.PromoCode{ID: int64(len(r.created)), Code: input.Code, Kind: input.Kind, Active: *input.Active}, nil
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	ctx := context.Background()
+	productID := int64(5)
+	categoryID := int64(2)
+	zero := 0
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	t.Run("NormalizesCodeAndActivates", func(t *testing.T) {
+		repo := &fakeRepository{}
+		code, err := promotions.NewService(repo).CreatePromoCode(ctx, models.PromoCodeInput{
+			Code:       "  summer10 ",
+			Kind:       models.PromoKindPercent,
+			Value:      10,
+			CategoryID: &categoryID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "SUMMER10", code.Code)
+		require.True(t, code.Active)
+	})
+
+	for name, input := range map[string]models.PromoCodeInput{
+		"ShortCode":            {Code: "AB", Kind: models.PromoKindFixed, Value: 100},
+		"CodeWithSpaces":       {Code: "SUMMER 10", Kind: models.PromoKindFixed, Value: 100},
+		"UnknownKind":          {Code: "SUMMER10", Kind: "bogo", Value: 10},
+		"PercentOver100":       {Code: "SUMMER10", Kind: models.PromoKindPercent, Value: 150},
+		"ZeroFixed":            {Code: "SUMMER10", Kind: models.PromoKindFixed},
+		"FreeItemNoProduct":    {Code: "GIFT", Kind: models.PromoKindFreeItem},
+		"FreeItemWithCategory": {Code: "GIFT", Kind: models.PromoKindFreeItem, ProductID: &productID, CategoryID: &categoryID},
+		"PercentWithProduct":   {Code: "SUMMER10", Kind: models.PromoKindPercent, Value: 10, ProductID: &productID},
+		"NegativeMinTotal":     {Code: "SUMMER10", Kind: models.PromoKindFixed, Value: 100, MinOrderTotal: -1},
+		"ZeroUsageLimit":       {Code: "SUMMER10", Kind: models.PromoKindFixed, Value: 100, MaxUsesPerUser: &zero},
+		"EndsBeforeStart":      {Code: "SUMMER10", Kind: models.PromoKindFixed, Value: 100, StartsAt: &now, EndsAt: &earlier},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeRepository{}
+			_, err := promotions.NewService(repo).CreatePromoCode(ctx, input)
+			require.ErrorIs(t, err, promotions.ErrInvalidPromoCode)
+			require.Empty(t, repo.created)
+		})
+	}
+}
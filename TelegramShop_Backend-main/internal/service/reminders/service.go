@@ -0,0 +1,128 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/reminders"
+	"telegramshop_backend/pkg/logger"
+)
+
+type Config struct {
+	// IdleAfter is how long a basket must stay untouched before its owner is reminded.
+	IdleAfter    time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		IdleAfter:    24 * time.Hour,
+		PollInterval: 10 * time.Minute,
+		BatchSize:    100,
+	}
+}
+
+// ErrUndeliverable is wrapped by Notifier errors that retrying cannot fix, such as a user who
+// blocked the bot. The reminder is then kept as claimed and not sent again for that basket.
+var ErrUndeliverable = errors.New("reminder cannot be delivered")
+
+// Notifier delivers a reminder about an abandoned basket to its owner.
+type Notifier interface {
+	RemindBasket(ctx context.Context, basket models.IdleBasket) error
+}
+
+// LogNotifier only logs reminders. It is meant for development and tests.
+type LogNotifier struct{}
+
+func (LogNotifier) RemindBasket(ctx context.Context, basket models.IdleBasket) error {
+	logger.Infof("[LogNotifier] Basket of user %d idle since %s: %d lines, %d items", basket.UserID, basket.UpdatedAt.Format(time.RFC3339), basket.Lines, basket.Quantity)
+	return nil
+}
+
+type Service interface {
+	// Run sends reminders until ctx is cancelled. Several instances may run at once.
+	Run(ctx context.Context)
+	// RemindOnce sends one batch of reminders and returns the number sent.
+	RemindOnce(ctx context.Context) (int, error)
+	SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error
+}
+
+type service struct {
+	repo     reminders.Repository
+	notifier Notifier
+	cfg      Config
+}
+
+func NewService(repo reminders.Repository, notifier Notifier, cfg Config) Service {
+	return &service{
+		repo:     repo,
+		notifier: notifier,
+		cfg:      cfg,
+	}
+}
+
+func (s *service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RemindOnce(ctx); err != nil {
+			logger.Errorf("[Run] Error sending basket reminders: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) RemindOnce(ctx context.Context) (int, error) {
+	baskets, err := s.repo.FindIdleBaskets(ctx, s.cfg.IdleAfter, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, basket := range baskets {
+		// The reminder is recorded before it is sent, so a concurrent job skips the basket.
+		claimed, err := s.repo.ClaimReminder(ctx, basket.UserID, basket.UpdatedAt)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.notifier.RemindBasket(ctx, basket); err != nil {
+			logger.Errorf("[RemindOnce] Error reminding user %d about basket: %v", basket.UserID, err)
+			if errors.Is(err, ErrUndeliverable) {
+				continue
+			}
+			if err := s.repo.ReleaseReminder(ctx, basket.UserID, basket.UpdatedAt); err != nil {
+				logger.Errorf("[RemindOnce] Error releasing reminder for user %d: %v", basket.UserID, err)
+			}
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Infof("[RemindOnce] Sent %d basket reminders", sent)
+	}
+	return sent, nil
+}
+
+func (s *service) SetRemindersEnabled(ctx context.Context, userID int64, enabled bool) error {
+	logger.Infof("[SetRemindersEnabled] Setting basket reminders of user %d to %t", userID, enabled)
+
+	if err := s.repo.SetRemindersEnabled(ctx, userID, enabled); err != nil {
+		logger.Errorf("[SetRemindersEnabled] Error updating user %d: %v", userID, err)
+		return err
+	}
+	return nil
+}
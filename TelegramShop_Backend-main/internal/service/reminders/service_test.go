@@ -0,0 +1,120 @@
+package reminders_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/reminders"
+	"telegramshop_backend/internal/service/reminders"
+)
+
+type reminderKey struct {
+	userID    int64
+	updatedAt time.Time
+}
+
+// fakeRepository returns the same idle baskets until a reminder is claimed for them.
+type fakeRepository struct {
+	repository.Repository
+	baskets []models.IdleBasket
+	sent    map[reminderKey]bool
+}
+
+func (r *fakeRepository) FindIdleBaskets(ctx context.Context, idleFor time.Duration, limit int) ([]models.IdleBasket, error) {
+	var result []models.IdleBasket
+	for _, basket := range r.baskets {
+		if !r.sent[reminderKey{basket.UserID, basket.UpdatedAt}] && len(result) < limit {
+			result = append(result, basket)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeRepository) ClaimReminder(ctx context.Context, userID int64, basketUpdatedAt time.Time) (bool, error) {
+	key := reminderKey{userID, basketUpdatedAt}
+	if r.sent[key] {
+		return false, nil
+	}
+	r.sent[key] = true
+	return true, nil
+}
+
+func (r *fakeRepository) ReleaseReminder(ctx context.Context, userID int64, basketUpdatedAt time.Time) error {
+	delete(r.sent, reminderKey{userID, basketUpdatedAt})
+	return nil
+}
+
+// recordingNotifier remembers who was reminded, fails for the users in fail and cannot
+// reach the users in blocked.
+type recordingNotifier struct {
+	reminded []int64
+	fail     map[int64]bool
+	blocked  map[int64]bool
+}
+
+func (n *recordingNotifier) RemindBasket(ctx context.Context, basket models.IdleBasket) error {
+	if n.blocked[basket.UserID] {
+		return fmt.Errorf("%w: bot was blocked by the user", reminders.ErrUndeliverable)
+	}
+	if n.fail[basket.UserID] {
+		return errors.New("connection reset")
+	}
+	n.reminded = append(n.reminded, basket.UserID)
+	return nil
+}
+
+func TestRemindOnce(t *testing.T) {
+	ctx := context.Background()
+	idleSince := time.Now().Add(-48 * time.Hour)
+	repo := &fakeRepository{
+		baskets: []models.IdleBasket{
+			{UserID: 1, TelegramID: 101, UpdatedAt: idleSince, Lines: 1, Quantity: 2},
+			{UserID: 2, TelegramID: 102, UpdatedAt: idleSince, Lines: 3, Quantity: 3},
+			{UserID: 3, TelegramID: 103, UpdatedAt: idleSince, Lines: 1, Quantity: 1},
+		},
+		sent: map[reminderKey]bool{},
+	}
+	notifier := &recordingNotifier{fail: map[int64]bool{2: true}, blocked: map[int64]bool{3: true}}
+	service := reminders.NewService(repo, notifier, reminders.DefaultConfig())
+
+	sent, err := service.RemindOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.Equal(t, []int64{1}, notifier.reminded)
+
+	t.Run("NotRemindedTwice", func(t *testing.T) {
+		sent, err := service.RemindOnce(ctx)
+		require.NoError(t, err)
+		require.Zero(t, sent)
+		require.Equal(t, []int64{1}, notifier.reminded)
+	})
+
+	t.Run("FailedReminderIsRetried", func(t *testing.T) {
+		require.False(t, repo.sent[reminderKey{2, idleSince}], "a transient failure releases the claim")
+		require.True(t, repo.sent[reminderKey{3, idleSince}], "an undeliverable reminder keeps the claim")
+
+		delete(notifier.fail, 2)
+		sent, err := service.RemindOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+		require.Equal(t, []int64{1, 2}, notifier.reminded)
+	})
+
+	t.Run("RemindedAgainAfterBasketChanges", func(t *testing.T) {
+		repo.baskets[0].UpdatedAt = idleSince.Add(time.Hour)
+		sent, err := service.RemindOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+		require.Equal(t, []int64{1, 2, 1}, notifier.reminded)
+	})
+}
+
+func TestLogNotifier(t *testing.T) {
+	require.NoError(t, reminders.LogNotifier{}.RemindBasket(context.Background(), models.IdleBasket{UserID: 1}))
+}
@@ -0,0 +1,85 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"telegramshop_backend/internal/models"
+)
+
+// Index answers prefix queries over suggestion names. It is immutable once built,
+// so lookups need no locking; the service swaps in a new index on rebuild.
+type Index struct {
+	suggestions []models.Suggestion
+	// keys holds, for every suggestion, its normalized name starting at each word,
+	// so "air m" finds "Кроссовки Air Max". Sorted by key for binary search.
+	keys []indexKey
+}
+
+type indexKey struct {
+	key string
+	ref int
+}
+
+func NewIndex(suggestions []models.Suggestion) *Index {
+	index := &Index{suggestions: suggestions}
+	for ref, suggestion := range suggestions {
+		words := strings.Fields(normalize(suggestion.Name))
+		for i := range words {
+			index.keys = append(index.keys, indexKey{key: strings.Join(words[i:], " "), ref: ref})
+		}
+	}
+	sort.Slice(index.keys, func(i, j int) bool { return index.keys[i].key < index.keys[j].key })
+	return index
+}
+
+// Lookup returns up to limit suggestions with a word starting with prefix,
+// best sellers first.
+func (idx *Index) Lookup(prefix string, limit int) []models.Suggestion {
+	prefix = strings.Join(strings.Fields(normalize(prefix)), " ")
+	if prefix == "" || limit <= 0 {
+		return nil
+	}
+
+	start := sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i].key >= prefix })
+	seen := map[int]bool{}
+	var refs []int
+	for i := start; i < len(idx.keys) && strings.HasPrefix(idx.keys[i].key, prefix); i++ {
+		if ref := idx.keys[i].ref; !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := idx.suggestions[refs[i]], idx.suggestions[refs[j]]
+		if a.SellCount != b.SellCount {
+			return a.SellCount > b.SellCount
+		}
+		return a.Name < b.Name
+	})
+	if len(refs) > limit {
+		refs = refs[:limit]
+	}
+
+	result := make([]models.Suggestion, 0, len(refs))
+	for _, ref := range refs {
+		result = append(result, idx.suggestions[ref])
+	}
+	return result
+}
+
+// normalize lower-cases the text, folds ё into е and turns punctuation into spaces.
+func normalize(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == 'ё' || r == 'Ё':
+			return 'е'
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return ' '
+		}
+	}, text)
+}
@@ -0,0 +1,161 @@
+package suggest
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/categories"
+	"telegramshop_backend/internal/repository/firms"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/pkg/logger"
+)
+
+type Config struct {
+	// PollInterval is how often the catalog version is checked for changes made through any instance.
+	PollInterval time.Duration
+	// Debounce delays a rebuild after a catalog change, so a burst of changes causes one rebuild.
+	Debounce time.Duration
+	// RefreshInterval rebuilds the index periodically to pick up sell_count changes.
+	RefreshInterval time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:    5 * time.Second,
+		Debounce:        2 * time.Second,
+		RefreshInterval: 10 * time.Minute,
+	}
+}
+
+type Service interface {
+	// Suggest answers from memory; it returns nothing until the first Rebuild.
+	Suggest(prefix string, limit int) []models.Suggestion
+	Rebuild(ctx context.Context) error
+	// Run rebuilds the index after catalog changes and periodically until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type service struct {
+	products   products.Repository
+	firms      firms.Repository
+	categories categories.Repository
+	outbox     outbox.Repository
+	cfg        Config
+	index      atomic.Pointer[Index]
+	// version is the catalog version the current index was built from.
+	version atomic.Int64
+}
+
+func NewService(productsRepo products.Repository, firmsRepo firms.Repository, categoriesRepo categories.Repository, outboxRepo outbox.Repository, cfg Config) Service {
+	return &service{
+		products:   productsRepo,
+		firms:      firmsRepo,
+		categories: categoriesRepo,
+		outbox:     outboxRepo,
+		cfg:        cfg,
+	}
+}
+
+func (s *service) Suggest(prefix string, limit int) []models.Suggestion {
+	index := s.index.Load()
+	if index == nil {
+		return nil
+	}
+	return index.Lookup(prefix, limit)
+}
+
+func (s *service) Rebuild(ctx context.Context) error {
+	// The version is read first, so a change made during the rebuild triggers another one.
+	version, err := s.outbox.CatalogVersion(ctx)
+	if err != nil {
+		logger.Errorf("[Rebuild] Error getting catalog version: %v", err)
+		return err
+	}
+	sales, err := s.products.GetProductSales(ctx)
+	if err != nil {
+		logger.Errorf("[Rebuild] Error getting products: %v", err)
+		return err
+	}
+	allFirms, err := s.firms.GetAllFirms(ctx)
+	if err != nil {
+		logger.Errorf("[Rebuild] Error getting firms: %v", err)
+		return err
+	}
+	allCategories, err := s.categories.GetAllCategories(ctx)
+	if err != nil {
+		logger.Errorf("[Rebuild] Error getting categories: %v", err)
+		return err
+	}
+
+	firmSales := map[int64]int{}
+	categorySales := map[int64]int{}
+	suggestions := make([]models.Suggestion, 0, len(sales)+len(allFirms)+len(allCategories))
+	for _, product := range sales {
+		firmSales[product.FirmID] += product.SellCount
+		categorySales[product.CategoryID] += product.SellCount
+		suggestions = append(suggestions, models.Suggestion{
+			Type:      models.SuggestionProduct,
+			ID:        product.ID,
+			Name:      product.Name,
+			SellCount: product.SellCount,
+		})
+	}
+	for _, firm := range allFirms {
+		suggestions = append(suggestions, models.Suggestion{
+			Type:      models.SuggestionFirm,
+			ID:        firm.ID,
+			Name:      firm.Name,
+			SellCount: firmSales[firm.ID],
+		})
+	}
+	for _, category := range allCategories {
+		suggestions = append(suggestions, models.Suggestion{
+			Type:      models.SuggestionCategory,
+			ID:        category.ID,
+			Name:      category.Name,
+			SellCount: categorySales[category.ID],
+		})
+	}
+
+	s.index.Store(NewIndex(suggestions))
+	s.version.Store(version)
+	logger.Infof("[Rebuild] Suggestion index rebuilt with %d entries", len(suggestions))
+	return nil
+}
+
+// Run polls the catalog version rather than handling outbox events, because the outbox
+// delivers each event to one instance while every instance keeps its own index.
+func (s *service) Run(ctx context.Context) {
+	refresh := time.NewTicker(s.cfg.RefreshInterval)
+	defer refresh.Stop()
+	poll := time.NewTicker(s.cfg.PollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+		case <-poll.C:
+			version, err := s.outbox.CatalogVersion(ctx)
+			if err != nil {
+				logger.Errorf("[Run] Error getting catalog version: %v", err)
+				continue
+			}
+			if version == s.version.Load() {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.cfg.Debounce):
+			}
+		}
+
+		// On failure the previous index keeps serving until the next attempt.
+		_ = s.Rebuild(ctx)
+	}
+}
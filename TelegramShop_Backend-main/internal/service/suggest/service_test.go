@@ -0,0 +1,129 @@
+package suggest_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/categories"
+	"telegramshop_backend/internal/repository/firms"
+	"telegramshop_backend/internal/repository/outbox"
+	"telegramshop_backend/internal/repository/products"
+	"telegramshop_backend/internal/service/suggest"
+)
+
+type fakeProducts struct {
+	products.Repository
+	sales []models.ProductSales
+}
+
+func (r *fakeProducts) GetProductSales(ctx context.Context) ([]models.ProductSales, error) {
+	return r.sales, nil
+}
+
+type fakeFirms struct {
+	firms.Repository
+	firms []models.Firm
+}
+
+func (r *fakeFirms) GetAllFirms(ctx context.Context) ([]models.Firm, error) {
+	return r.firms, nil
+}
+
+type fakeCategories struct {
+	categories.Repository
+	categories []models.Category
+}
+
+func (r *fakeCategories) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	return r.categories, nil
+}
+
+// fakeOutbox reports a catalog version that tests bump to simulate a change made elsewhere.
+type fakeOutbox struct {
+	outbox.Repository
+	version atomic.Int64
+}
+
+func (r *fakeOutbox) CatalogVersion(ctx context.Context) (int64, error) {
+	return r.version.Load(), nil
+}
+
+func newService(productsRepo *fakeProducts, outboxRepo *fakeOutbox) suggest.Service {
+	firmsRepo := &fakeFirms{firms: []models.Firm{{ID: 1, Name: "Apple"}, {ID: 2, Name: "Nike"}}}
+	categoriesRepo := &fakeCategories{categories: []models.Category{{ID: 1, Name: "Смартфоны"}, {ID: 2, Name: "Кроссовки"}}}
+	return suggest.NewService(productsRepo, firmsRepo, categoriesRepo, outboxRepo, suggest.Config{
+		PollInterval:    5 * time.Millisecond,
+		Debounce:        10 * time.Millisecond,
+		RefreshInterval: time.Hour,
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	ctx := context.Background()
+	productsRepo := &fakeProducts{sales: []models.ProductSales{
+		{ID: 1, Name: "iPhone 13", FirmID: 1, CategoryID: 1, SellCount: 10},
+		{ID: 2, Name: "iPhone 15 Pro", FirmID: 1, CategoryID: 1, SellCount: 30},
+		{ID: 3, Name: "Кроссовки Air Max", FirmID: 2, CategoryID: 2, SellCount: 5},
+		{ID: 4, Name: "Ёлочная игрушка", FirmID: 2, CategoryID: 2, SellCount: 1},
+	}}
+	service := newService(productsRepo, &fakeOutbox{})
+	require.Empty(t, service.Suggest("iph", 10))
+	require.NoError(t, service.Rebuild(ctx))
+
+	t.Run("RankedBySellCount", func(t *testing.T) {
+		require.Equal(t, []models.Suggestion{
+			{Type: models.SuggestionProduct, ID: 2, Name: "iPhone 15 Pro", SellCount: 30},
+			{Type: models.SuggestionProduct, ID: 1, Name: "iPhone 13", SellCount: 10},
+		}, service.Suggest("IPH", 10))
+	})
+
+	t.Run("MixedTypes", func(t *testing.T) {
+		require.Equal(t, []models.Suggestion{
+			{Type: models.SuggestionCategory, ID: 2, Name: "Кроссовки", SellCount: 6},
+			{Type: models.SuggestionProduct, ID: 3, Name: "Кроссовки Air Max", SellCount: 5},
+		}, service.Suggest("кросс", 10))
+		require.Equal(t, []models.Suggestion{
+			{Type: models.SuggestionFirm, ID: 1, Name: "Apple", SellCount: 40},
+		}, service.Suggest("app", 10))
+	})
+
+	t.Run("MatchesWordInsideName", func(t *testing.T) {
+		require.Len(t, service.Suggest("air m", 10), 1)
+		require.Len(t, service.Suggest("елоч", 10), 1)
+		require.Empty(t, service.Suggest("ir max", 10))
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		require.Len(t, service.Suggest("i", 1), 1)
+		require.Empty(t, service.Suggest("  ", 10))
+	})
+}
+
+func TestRunRebuildsAfterCatalogChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	productsRepo := &fakeProducts{}
+	outboxRepo := &fakeOutbox{}
+	service := newService(productsRepo, outboxRepo)
+	require.NoError(t, service.Rebuild(ctx))
+	require.Empty(t, service.Suggest("galaxy", 10))
+
+	productsRepo.sales = []models.ProductSales{{ID: 5, Name: "Galaxy S24", FirmID: 3, CategoryID: 1}}
+	go service.Run(ctx)
+	// An unchanged version must not trigger a rebuild.
+	time.Sleep(30 * time.Millisecond)
+	require.Empty(t, service.Suggest("galaxy", 10))
+
+	// The change may have been published through another instance.
+	outboxRepo.version.Store(1)
+
+	require.Eventually(t, func() bool {
+		return len(service.Suggest("galaxy", 10)) == 1
+	}, time.Second, 5*time.Millisecond)
+}
@@ -2,17 +2,25 @@ package users
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 
 	"telegramshop_backend/internal/models"
 	"telegramshop_backend/internal/repository/users"
 	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/pagination"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type Service interface {
+	// GetUserByID looks a user up by users.id, the ID the API exposes.
 	GetUserByID(ctx context.Context, id int64) (models.User, error)
 	CreateUser(ctx context.Context, input models.CreateUser) (models.User, error)
-	GetAll(ctx context.Context) ([]models.User, error)
+	GetAll(ctx context.Context, page pagination.Page) ([]models.User, string, error)
 	DeleteUser(ctx context.Context, id int64) error
+	EnsureUser(ctx context.Context, input models.CreateUser) (models.User, error)
 }
 
 type service struct {
@@ -27,11 +35,14 @@ func (s *service) GetUserByID(ctx context.Context, id int64) (models.User, error
 
 	logger.Infof("[GetUserByID] Getting user with id=%d", id)
 
-	user, err := s.repo.GetUserByID(ctx, id)
+	user, err := s.repo.GetUserByInternalID(ctx, id)
 	if err != nil {
 		logger.Errorf("[GetUserByID] Error getting user: %v", err)
 		return models.User{}, err
 	}
+	if user.ID == 0 {
+		return models.User{}, ErrUserNotFound
+	}
 
 	return user, nil
 }
@@ -54,17 +65,17 @@ func (s *service) CreateUser(ctx context.Context, input models.CreateUser) (mode
 	return createdUser, nil
 }
 
-func (s *service) GetAll(ctx context.Context) ([]models.User, error) {
+func (s *service) GetAll(ctx context.Context, page pagination.Page) ([]models.User, string, error) {
 
 	logger.Info("[GetAll] Getting all users")
 
-	users, err := s.repo.GetAll(ctx)
+	users, next, err := s.repo.GetAll(ctx, page)
 	if err != nil {
 		logger.Errorf("[GetAll] Error getting users: %v", err)
-		return nil, err
+		return nil, "", err
 	}
 
-	return users, nil
+	return users, next, nil
 }
 
 func (s *service) DeleteUser(ctx context.Context, id int64) error {
@@ -78,4 +89,30 @@ func (s *service) DeleteUser(ctx context.Context, id int64) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// EnsureUser returns the user with the given telegram id, registering it on first sight.
+func (s *service) EnsureUser(ctx context.Context, input models.CreateUser) (models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, input.TelegramID)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf("[EnsureUser] Error getting user: %v", err)
+		return models.User{}, err
+	}
+
+	if input.Username == "" {
+		input.Username = fmt.Sprintf("tg%d", input.TelegramID)
+	}
+
+	logger.Infof("[EnsureUser] Registering user with id=%d, username=%s", input.TelegramID, input.Username)
+
+	user, err = s.repo.CreateUser(ctx, input)
+	if err != nil {
+		logger.Errorf("[EnsureUser] Error creating user: %v", err)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,390 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"telegramshop_backend/internal/models"
+	"telegramshop_backend/internal/repository/webhooks"
+	"telegramshop_backend/pkg/logger"
+	"telegramshop_backend/pkg/retry"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var (
+	ErrSubscriptionNotFound = webhooks.ErrSubscriptionNotFound
+	ErrInvalidURL           = errors.New("webhook url must be an absolute http or https url")
+	ErrEmptySecret          = errors.New("webhook secret is required")
+	ErrNoEventTypes         = errors.New("at least one event type is required")
+	ErrUnknownEventType     = errors.New("unknown webhook event type")
+)
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed delivery stays hidden from other workers; it must exceed Timeout.
+	Lease   time.Duration
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts after which a delivery is marked as failed.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// LowStockThreshold is the stock level at or below which product.stock_low is sent.
+	LowStockThreshold int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:      time.Second,
+		BatchSize:         20,
+		Lease:             time.Minute,
+		Timeout:           10 * time.Second,
+		MaxAttempts:       8,
+		InitialBackoff:    10 * time.Second,
+		MaxBackoff:        time.Hour,
+		LowStockThreshold: 5,
+	}
+}
+
+// Envelope is the JSON body of a delivery.
+type Envelope struct {
+	EventID   int64           `json:"event_id"`
+	EventType string          `json:"event_type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type Service interface {
+	CreateSubscription(ctx context.Context, input models.CreateWebhookSubscription) (models.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id int64, input models.UpdateWebhookSubscription) (models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error)
+
+	// HandleEvent is the outbox handler turning shop events into deliveries.
+	HandleEvent(ctx context.Context, event models.OutboxEvent) error
+	Run(ctx context.Context)
+	// DeliverOnce sends one batch of due deliveries and returns how many were claimed.
+	DeliverOnce(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo   webhooks.Repository
+	client *http.Client
+	cfg    Config
+}
+
+func NewService(repo webhooks.Repository, client *http.Client, cfg Config) Service {
+	return &service{repo: repo, client: client, cfg: cfg}
+}
+
+// Sign returns the X-Webhook-Signature value: the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// normalizeEventTypes rejects unknown types and drops duplicates.
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, ErrNoEventTypes
+	}
+
+	seen := map[string]bool{}
+	var result []string
+	for _, eventType := range eventTypes {
+		known := false
+		for _, allowed := range models.WebhookEventTypes {
+			if eventType == allowed {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			result = append(result, eventType)
+		}
+	}
+	return result, nil
+}
+
+func (s *service) CreateSubscription(ctx context.Context, input models.CreateWebhookSubscription) (models.WebhookSubscription, error) {
+	logger.Infof("[CreateSubscription] Creating webhook subscription for %s", input.URL)
+
+	if err := validateURL(input.URL); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if input.Secret == "" {
+		return models.WebhookSubscription{}, ErrEmptySecret
+	}
+	eventTypes, err := normalizeEventTypes(input.EventTypes)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	input.EventTypes = eventTypes
+
+	subscription, err := s.repo.CreateSubscription(ctx, input)
+	if err != nil {
+		logger.Errorf("[CreateSubscription] Error creating webhook subscription: %v", err)
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s *service) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	logger.Infof("[GetSubscriptions] Getting webhook subscriptions")
+
+	subscriptions, err := s.repo.GetSubscriptions(ctx)
+	if err != nil {
+		logger.Errorf("[GetSubscriptions] Error getting webhook subscriptions: %v", err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *service) UpdateSubscription(ctx context.Context, id int64, input models.UpdateWebhookSubscription) (models.WebhookSubscription, error) {
+	logger.Infof("[UpdateSubscription] Updating webhook subscription %d", id)
+
+	subscription, err := s.repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if input.URL != nil {
+		if err := validateURL(*input.URL); err != nil {
+			return models.WebhookSubscription{}, err
+		}
+		subscription.URL = *input.URL
+	}
+	if input.Secret != nil {
+		if *input.Secret == "" {
+			return models.WebhookSubscription{}, ErrEmptySecret
+		}
+		subscription.Secret = *input.Secret
+	}
+	if input.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(input.EventTypes)
+		if err != nil {
+			return models.WebhookSubscription{}, err
+		}
+		subscription.EventTypes = eventTypes
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, subscription); err != nil {
+		logger.Errorf("[UpdateSubscription] Error updating webhook subscription: %v", err)
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s *service) DeleteSubscription(ctx context.Context, id int64) error {
+	logger.Infof("[DeleteSubscription] Deleting webhook subscription %d", id)
+
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		logger.Errorf("[DeleteSubscription] Error deleting webhook subscription: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error) {
+	logger.Infof("[GetDeliveries] Getting deliveries of webhook subscription %d", subscriptionID)
+
+	if _, err := s.repo.GetSubscriptionByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.GetDeliveries(ctx, subscriptionID, limit)
+	if err != nil {
+		logger.Errorf("[GetDeliveries] Error getting webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *service) HandleEvent(ctx context.Context, event models.OutboxEvent) error {
+	switch event.EventType {
+	case models.EventOrderCreated, models.EventOrderStatusChanged:
+		return s.enqueue(ctx, event.ID, event.EventType, event.Payload)
+
+	case models.EventProductStockChanged, models.EventProductUpdated:
+		var payload models.ProductEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("decode %s payload: %w", event.EventType, err)
+		}
+		// Only a drop to the threshold is reported, not every later change below it.
+		if payload.Stock == nil || payload.PrevStock == nil ||
+			*payload.Stock > s.cfg.LowStockThreshold || *payload.PrevStock <= s.cfg.LowStockThreshold {
+			return nil
+		}
+		data, err := json.Marshal(models.StockLowEvent{
+			ProductID: payload.ProductID,
+			Stock:     *payload.Stock,
+			Threshold: s.cfg.LowStockThreshold,
+		})
+		if err != nil {
+			return err
+		}
+		return s.enqueue(ctx, event.ID, models.WebhookProductStockLow, data)
+
+	case models.EventReviewChanged:
+		var payload models.ReviewEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("decode %s payload: %w", event.EventType, err)
+		}
+		if payload.Action != models.ReviewCreated {
+			return nil
+		}
+		return s.enqueue(ctx, event.ID, models.WebhookReviewCreated, event.Payload)
+	}
+
+	return nil
+}
+
+func (s *service) enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) error {
+	count, err := s.repo.Enqueue(ctx, eventID, eventType, payload)
+	if err != nil {
+		logger.Errorf("[HandleEvent] Error enqueueing %s deliveries for event %d: %v", eventType, eventID, err)
+		return err
+	}
+	if count > 0 {
+		logger.Infof("[HandleEvent] Enqueued %d %s deliveries for event %d", count, eventType, eventID)
+	}
+	return nil
+}
+
+// Run sends due deliveries until ctx is cancelled. Several instances may run at once.
+func (s *service) Run(ctx context.Context) {
+	retry.Poll(ctx, s.cfg.PollInterval, s.cfg.BatchSize, s.DeliverOnce, func(err error) {
+		logger.Errorf("[Run] Error delivering webhooks: %v", err)
+	})
+}
+
+func (s *service) DeliverOnce(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.Claim(ctx, s.cfg.BatchSize, s.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// Deliveries go to different endpoints, so one slow receiver must not hold up the others.
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery webhooks.PendingDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (s *service) deliver(ctx context.Context, delivery webhooks.PendingDelivery) {
+	responseStatus, err := s.post(ctx, delivery)
+	if err == nil {
+		if err := s.repo.MarkDelivered(ctx, delivery.ID, responseStatus); err != nil {
+			logger.Errorf("[DeliverOnce] Error marking delivery %d as delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	var status *int
+	if responseStatus != 0 {
+		status = &responseStatus
+	}
+	final := delivery.Attempts >= s.cfg.MaxAttempts
+	retryAfter := retry.Backoff(s.cfg.InitialBackoff, s.cfg.MaxBackoff, delivery.Attempts)
+
+	if final {
+		logger.Errorf("[DeliverOnce] Giving up on delivery %d to %s after %d attempts: %v", delivery.ID, delivery.URL, delivery.Attempts, err)
+	} else {
+		logger.Errorf("[DeliverOnce] Delivery %d to %s failed, retrying in %s: %v", delivery.ID, delivery.URL, retryAfter, err)
+	}
+
+	if err := s.repo.MarkFailed(ctx, delivery.ID, status, err.Error(), retryAfter, final); err != nil {
+		logger.Errorf("[DeliverOnce] Error marking delivery %d as failed: %v", delivery.ID, err)
+	}
+}
+
+// post sends the signed delivery and returns the response status, 0 if no response was received.
+func (s *service) post(ctx context.Context, delivery webhooks.PendingDelivery) (int, error) {
+	body, err := json.Marshal(Envelope{
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, snippet)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
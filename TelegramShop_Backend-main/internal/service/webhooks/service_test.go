@@ -0,0 +1,226 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/internal/models"
+	repository "telegramshop_backend/internal/repository/webhooks"
+	"telegramshop_backend/internal/service/webhooks"
+)
+
+// fakeRepository keeps subscriptions and deliveries in memory and claims every pending delivery.
+type fakeRepository struct {
+	repository.Repository
+	mu            sync.Mutex
+	subscriptions []models.WebhookSubscription
+	deliveries    []*models.WebhookDelivery
+}
+
+func (r *fakeRepository) Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, subscription := range r.subscriptions {
+		listens := false
+		for _, t := range subscription.EventTypes {
+			listens = listens || t == eventType
+		}
+		if !subscription.Active || !listens || r.find(subscription.ID, eventID, eventType) != nil {
+			continue
+		}
+		r.deliveries = append(r.deliveries, &models.WebhookDelivery{
+			ID:             int64(len(r.deliveries) + 1),
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.WebhookDeliveryPending,
+		})
+		count++
+	}
+	return count, nil
+}
+
+func (r *fakeRepository) find(subscriptionID, eventID int64, eventType string) *models.WebhookDelivery {
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID && delivery.EventID == eventID && delivery.EventType == eventType {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]repository.PendingDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []repository.PendingDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status != models.WebhookDeliveryPending {
+			continue
+		}
+		delivery.Attempts++
+		subscription := r.subscriptions[delivery.SubscriptionID-1]
+		claimed = append(claimed, repository.PendingDelivery{WebhookDelivery: *delivery, URL: subscription.URL, Secret: subscription.Secret})
+	}
+	return claimed, nil
+}
+
+func (r *fakeRepository) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[id-1].Status = models.WebhookDeliveryDelivered
+	r.deliveries[id-1].ResponseStatus = &responseStatus
+	return nil
+}
+
+func (r *fakeRepository) MarkFailed(ctx context.Context, id int64, responseStatus *int, lastError string, retryAfter time.Duration, final bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery := r.deliveries[id-1]
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = lastError
+	delivery.NextAttemptAt = time.Now().Add(retryAfter)
+	if final {
+		delivery.Status = models.WebhookDeliveryFailed
+	}
+	return nil
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, status int) (*httptest.Server, chan receivedRequest) {
+	requests := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- receivedRequest{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func testConfig() webhooks.Config {
+	cfg := webhooks.DefaultConfig()
+	cfg.MaxAttempts = 2
+	return cfg
+}
+
+func TestDeliverOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SignedDelivery", func(t *testing.T) {
+		server, requests := newReceiver(t, http.StatusNoContent)
+		repo := &fakeRepository{subscriptions: []models.WebhookSubscription{
+			{ID: 1, URL: server.URL, Secret: "s3cr3t", EventTypes: []string{models.WebhookOrderCreated}, Active: true},
+			{ID: 2, URL: server.URL, Secret: "other", EventTypes: []string{models.WebhookReviewCreated}, Active: true},
+		}}
+		service := webhooks.NewService(repo, server.Client(), testConfig())
+
+		payload := `{"order_id":7,"user_id":3,"to":"pending"}`
+		require.NoError(t, service.HandleEvent(ctx, models.OutboxEvent{ID: 42, EventType: models.EventOrderCreated, Payload: json.RawMessage(payload)}))
+		// An outbox retry of the same event must not duplicate deliveries.
+		require.NoError(t, service.HandleEvent(ctx, models.OutboxEvent{ID: 42, EventType: models.EventOrderCreated, Payload: json.RawMessage(payload)}))
+		require.Len(t, repo.deliveries, 1)
+
+		claimed, err := service.DeliverOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, claimed)
+
+		req := <-requests
+		require.Equal(t, models.WebhookOrderCreated, req.header.Get(webhooks.HeaderEvent))
+		require.Equal(t, "1", req.header.Get(webhooks.HeaderDelivery))
+		timestamp := req.header.Get(webhooks.HeaderTimestamp)
+		require.Equal(t, webhooks.Sign("s3cr3t", timestamp, req.body), req.header.Get(webhooks.HeaderSignature))
+
+		var envelope webhooks.Envelope
+		require.NoError(t, json.Unmarshal(req.body, &envelope))
+		require.Equal(t, int64(42), envelope.EventID)
+		require.Equal(t, models.WebhookOrderCreated, envelope.EventType)
+		require.JSONEq(t, payload, string(envelope.Data))
+
+		require.Equal(t, models.WebhookDeliveryDelivered, repo.deliveries[0].Status)
+		require.Equal(t, http.StatusNoContent, *repo.deliveries[0].ResponseStatus)
+	})
+
+	t.Run("RetriesThenFails", func(t *testing.T) {
+		server, requests := newReceiver(t, http.StatusInternalServerError)
+		repo := &fakeRepository{subscriptions: []models.WebhookSubscription{
+			{ID: 1, URL: server.URL, Secret: "s3cr3t", EventTypes: []string{models.WebhookProductStockLow}, Active: true},
+		}}
+		service := webhooks.NewService(repo, server.Client(), testConfig())
+
+		require.NoError(t, service.HandleEvent(ctx, models.OutboxEvent{ID: 1, EventType: models.EventProductStockChanged, Payload: json.RawMessage(`{"product_id":5,"prev_stock":6,"stock":2}`)}))
+		require.Len(t, repo.deliveries, 1)
+
+		_, err := service.DeliverOnce(ctx)
+		require.NoError(t, err)
+		<-requests
+		delivery := repo.deliveries[0]
+		require.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		require.Equal(t, http.StatusInternalServerError, *delivery.ResponseStatus)
+		require.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+		_, err = service.DeliverOnce(ctx)
+		require.NoError(t, err)
+		req := <-requests
+		require.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+		require.Contains(t, delivery.LastError, "unexpected status 500")
+
+		var envelope webhooks.Envelope
+		require.NoError(t, json.Unmarshal(req.body, &envelope))
+		require.JSONEq(t, `{"product_id":5,"stock":2,"threshold":5}`, string(envelope.Data))
+	})
+}
+
+func TestHandleEventFilters(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepository{subscriptions: []models.WebhookSubscription{
+		{ID: 1, URL: "http://example.com", Secret: "s", EventTypes: []string{models.WebhookProductStockLow, models.WebhookReviewCreated}, Active: true},
+	}}
+	service := webhooks.NewService(repo, http.DefaultClient, testConfig())
+
+	events := []models.OutboxEvent{
+		{ID: 1, EventType: models.EventProductStockChanged, Payload: json.RawMessage(`{"product_id":5,"prev_stock":41,"stock":40}`)},
+		// Stock already below the threshold was reported when it got there.
+		{ID: 5, EventType: models.EventProductStockChanged, Payload: json.RawMessage(`{"product_id":5,"prev_stock":3,"stock":2}`)},
+		{ID: 6, EventType: models.EventProductUpdated, Payload: json.RawMessage(`{"product_id":5,"prev_stock":2,"stock":4}`)},
+		{ID: 2, EventType: models.EventReviewChanged, Payload: json.RawMessage(`{"product_id":5,"user_id":1,"kind":"mark","action":"updated"}`)},
+		{ID: 3, EventType: models.EventOrderCreated, Payload: json.RawMessage(`{"order_id":1}`)},
+		{ID: 4, EventType: models.EventReviewChanged, Payload: json.RawMessage(`{"product_id":5,"user_id":1,"kind":"comment","action":"created"}`)},
+	}
+	for _, event := range events {
+		require.NoError(t, service.HandleEvent(ctx, event))
+	}
+
+	require.Len(t, repo.deliveries, 1)
+	require.Equal(t, int64(4), repo.deliveries[0].EventID)
+	require.Equal(t, models.WebhookReviewCreated, repo.deliveries[0].EventType)
+}
+
+func TestCreateSubscriptionValidation(t *testing.T) {
+	service := webhooks.NewService(&fakeRepository{}, http.DefaultClient, testConfig())
+	ctx := context.Background()
+
+	_, err := service.CreateSubscription(ctx, models.CreateWebhookSubscription{URL: "ftp://example.com", Secret: "s", EventTypes: []string{models.WebhookOrderCreated}})
+	require.ErrorIs(t, err, webhooks.ErrInvalidURL)
+
+	_, err = service.CreateSubscription(ctx, models.CreateWebhookSubscription{URL: "https://example.com", EventTypes: []string{models.WebhookOrderCreated}})
+	require.ErrorIs(t, err, webhooks.ErrEmptySecret)
+
+	_, err = service.CreateSubscription(ctx, models.CreateWebhookSubscription{URL: "https://example.com", Secret: "s", EventTypes: []string{"order.deleted"}})
+	require.ErrorIs(t, err, webhooks.ErrUnknownEventType)
+}
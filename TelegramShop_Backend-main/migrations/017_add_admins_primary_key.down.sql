@@ -0,0 +1 @@
+ALTER TABLE "admins" DROP CONSTRAINT IF EXISTS "admins_pkey";
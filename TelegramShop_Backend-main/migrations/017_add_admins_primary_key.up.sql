@@ -0,0 +1,7 @@
+DELETE FROM "admins" a
+USING "admins" b
+WHERE a.ctid < b.ctid AND a.user_id = b.user_id;
+
+DELETE FROM "admins" WHERE "user_id" IS NULL;
+
+ALTER TABLE "admins" ADD PRIMARY KEY ("user_id");
@@ -0,0 +1,10 @@
+ALTER TABLE "orders" ADD COLUMN "total_amount" numeric(10,2) NOT NULL DEFAULT 0;
+
+UPDATE "orders" o
+SET "total_amount" = t.total
+FROM (
+    SELECT "order_id", SUM("price" * "quantity") AS total
+    FROM "order_products"
+    GROUP BY "order_id"
+) t
+WHERE t.order_id = o.id;
@@ -0,0 +1,15 @@
+CREATE TABLE "order_status_history" (
+                                        "id" SERIAL PRIMARY KEY,
+                                        "order_id" integer NOT NULL REFERENCES "orders" ("id") ON DELETE CASCADE,
+                                        "from_status" varchar(50),
+                                        "to_status" varchar(50) NOT NULL,
+                                        "actor_id" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+                                        "comment" text NOT NULL DEFAULT '',
+                                        "created_at" timestamp DEFAULT (current_timestamp)
+);
+
+CREATE INDEX ON "order_status_history" ("order_id", "id");
+
+INSERT INTO "order_status_history" ("order_id", "from_status", "to_status", "actor_id", "created_at")
+SELECT "id", NULL, "status", NULL, "created_at"
+FROM "orders";
@@ -0,0 +1 @@
+ALTER TABLE "orders" ADD COLUMN "cancel_reason" text;
@@ -0,0 +1,12 @@
+CREATE TABLE "idempotency_keys" (
+                                    "user_id" integer NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+                                    "key" varchar(255) NOT NULL,
+                                    "request_hash" varchar(64) NOT NULL,
+                                    "order_id" integer REFERENCES "orders" ("id") ON DELETE CASCADE,
+                                    "response" jsonb,
+                                    "created_at" timestamp DEFAULT (current_timestamp),
+                                    "expires_at" timestamp NOT NULL,
+                                    PRIMARY KEY ("user_id", "key")
+);
+
+CREATE INDEX "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
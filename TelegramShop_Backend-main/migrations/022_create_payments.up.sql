@@ -0,0 +1,11 @@
+CREATE TABLE "payments" (
+                            "id" SERIAL PRIMARY KEY,
+                            "order_id" integer NOT NULL REFERENCES "orders" ("id") ON DELETE CASCADE,
+                            "currency" varchar(3) NOT NULL,
+                            "total_amount" bigint NOT NULL,
+                            "telegram_payment_charge_id" varchar(255) NOT NULL UNIQUE,
+                            "provider_payment_charge_id" varchar(255) NOT NULL,
+                            "created_at" timestamp DEFAULT (current_timestamp)
+);
+
+CREATE INDEX ON "payments" ("order_id");
@@ -0,0 +1,14 @@
+CREATE TABLE "outbox" (
+                          "id" BIGSERIAL PRIMARY KEY,
+                          "event_type" varchar(100) NOT NULL,
+                          "payload" jsonb NOT NULL,
+                          "status" varchar(20) NOT NULL DEFAULT 'pending',
+                          "attempts" integer NOT NULL DEFAULT 0,
+                          "last_error" text NOT NULL DEFAULT '',
+                          "available_at" timestamp NOT NULL DEFAULT (current_timestamp),
+                          "created_at" timestamp NOT NULL DEFAULT (current_timestamp),
+                          "processed_at" timestamp
+);
+
+CREATE INDEX "idx_outbox_pending" ON "outbox" ("available_at", "id") WHERE "status" = 'pending';
+CREATE INDEX "idx_outbox_status" ON "outbox" ("status", "id");
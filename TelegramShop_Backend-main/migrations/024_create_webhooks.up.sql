@@ -0,0 +1,26 @@
+CREATE TABLE "webhook_subscriptions" (
+                                         "id" SERIAL PRIMARY KEY,
+                                         "url" text NOT NULL,
+                                         "secret" text NOT NULL,
+                                         "event_types" text[] NOT NULL,
+                                         "active" boolean NOT NULL DEFAULT true,
+                                         "created_at" timestamp NOT NULL DEFAULT (current_timestamp)
+);
+
+CREATE TABLE "webhook_deliveries" (
+                                      "id" BIGSERIAL PRIMARY KEY,
+                                      "subscription_id" integer NOT NULL REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE,
+                                      "event_id" bigint NOT NULL,
+                                      "event_type" varchar(100) NOT NULL,
+                                      "payload" jsonb NOT NULL,
+                                      "status" varchar(20) NOT NULL DEFAULT 'pending',
+                                      "attempts" integer NOT NULL DEFAULT 0,
+                                      "response_status" integer,
+                                      "last_error" text NOT NULL DEFAULT '',
+                                      "next_attempt_at" timestamp NOT NULL DEFAULT (current_timestamp),
+                                      "created_at" timestamp NOT NULL DEFAULT (current_timestamp),
+                                      "delivered_at" timestamp,
+                                      UNIQUE ("subscription_id", "event_id", "event_type")
+);
+
+CREATE INDEX "idx_webhook_deliveries_pending" ON "webhook_deliveries" ("next_attempt_at", "id") WHERE "status" = 'pending';
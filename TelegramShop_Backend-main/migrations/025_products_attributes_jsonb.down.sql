@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "idx_products_attributes";
+
+ALTER TABLE "products"
+    ALTER COLUMN "attributes" DROP NOT NULL,
+    ALTER COLUMN "attributes" DROP DEFAULT,
+    ALTER COLUMN "attributes" TYPE text USING "attributes"::text;
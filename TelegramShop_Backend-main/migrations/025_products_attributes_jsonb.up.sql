@@ -0,0 +1,8 @@
+UPDATE "products" SET "attributes" = '{}' WHERE "attributes" IS NULL OR btrim("attributes") IN ('', 'null');
+
+ALTER TABLE "products"
+    ALTER COLUMN "attributes" TYPE jsonb USING "attributes"::jsonb,
+    ALTER COLUMN "attributes" SET DEFAULT '{}'::jsonb,
+    ALTER COLUMN "attributes" SET NOT NULL;
+
+CREATE INDEX "idx_products_attributes" ON "products" USING GIN ("attributes" jsonb_path_ops);
@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE "products" ADD COLUMN "search_vector" tsvector NOT NULL DEFAULT ''::tsvector;
+
+UPDATE "products" p SET "search_vector" =
+    setweight(to_tsvector('russian', coalesce(p.name, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce((SELECT name FROM firms WHERE id = p.firm_id), '')), 'B') ||
+    setweight(to_tsvector('russian', coalesce((SELECT name FROM categories WHERE id = p.category_id), '')), 'B') ||
+    setweight(to_tsvector('russian', coalesce(p.description, '')), 'C');
+
+CREATE INDEX "idx_products_search_vector" ON "products" USING GIN ("search_vector");
+CREATE INDEX "idx_products_name_trgm" ON "products" USING GIN ("name" gin_trgm_ops);
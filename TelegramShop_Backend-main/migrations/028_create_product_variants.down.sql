@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS "idx_favorites_user_product_variant";
+DELETE FROM "favorites" f
+USING "favorites" d
+WHERE f."user_id" = d."user_id" AND f."product_id" = d."product_id" AND f."id" > d."id";
+CREATE UNIQUE INDEX ON "favorites" ("user_id", "product_id");
+
+DROP INDEX IF EXISTS "idx_basket_user_product_variant";
+DELETE FROM "basket" b
+USING "basket" d
+WHERE b."user_id" = d."user_id" AND b."product_id" = d."product_id" AND b."id" > d."id";
+CREATE UNIQUE INDEX ON "basket" ("user_id", "product_id");
+
+ALTER TABLE "order_products" DROP COLUMN IF EXISTS "variant_id";
+ALTER TABLE "favorites" DROP COLUMN IF EXISTS "variant_id";
+ALTER TABLE "basket" DROP COLUMN IF EXISTS "variant_id";
+
+DROP TABLE IF EXISTS "product_variants";
@@ -0,0 +1,21 @@
+CREATE TABLE "product_variants" (
+    "id" SERIAL PRIMARY KEY,
+    "product_id" integer NOT NULL REFERENCES "products" ("id") ON DELETE CASCADE,
+    "sku" text UNIQUE NOT NULL,
+    "attributes" jsonb NOT NULL DEFAULT '{}'::jsonb,
+    "stock" integer NOT NULL DEFAULT 0,
+    "price" numeric(10,2),
+    "created_at" timestamp NOT NULL DEFAULT (current_timestamp)
+);
+
+CREATE INDEX "idx_product_variants_product_id" ON "product_variants" ("product_id");
+
+ALTER TABLE "basket" ADD COLUMN "variant_id" integer REFERENCES "product_variants" ("id") ON DELETE CASCADE;
+ALTER TABLE "favorites" ADD COLUMN "variant_id" integer REFERENCES "product_variants" ("id") ON DELETE CASCADE;
+ALTER TABLE "order_products" ADD COLUMN "variant_id" integer REFERENCES "product_variants" ("id") ON DELETE SET NULL;
+
+-- A user may keep several variants of one product, so lines are unique per variant.
+DROP INDEX IF EXISTS "basket_user_id_product_id_idx";
+CREATE UNIQUE INDEX "idx_basket_user_product_variant" ON "basket" ("user_id", "product_id", COALESCE("variant_id", 0));
+DROP INDEX IF EXISTS "favorites_user_id_product_id_idx";
+CREATE UNIQUE INDEX "idx_favorites_user_product_variant" ON "favorites" ("user_id", "product_id", COALESCE("variant_id", 0));
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "order_discounts";
+
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "discount_amount";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "promo_code";
+
+DROP TABLE IF EXISTS "promo_code_redemptions";
+DROP TABLE IF EXISTS "promo_codes";
@@ -0,0 +1,41 @@
+CREATE TABLE "promo_codes" (
+    "id" SERIAL PRIMARY KEY,
+    "code" text UNIQUE NOT NULL CHECK ("code" = upper("code")),
+    "kind" text NOT NULL CHECK ("kind" IN ('percent', 'fixed', 'free_item')),
+    "value" numeric(10,2) NOT NULL DEFAULT 0,
+    "product_id" integer REFERENCES "products" ("id") ON DELETE CASCADE,
+    "category_id" integer REFERENCES "categories" ("id") ON DELETE CASCADE,
+    "firm_id" integer REFERENCES "firms" ("id") ON DELETE CASCADE,
+    "min_order_total" numeric(10,2) NOT NULL DEFAULT 0,
+    "max_uses" integer,
+    "max_uses_per_user" integer,
+    "starts_at" timestamp,
+    "ends_at" timestamp,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamp NOT NULL DEFAULT (current_timestamp)
+);
+
+CREATE TABLE "promo_code_redemptions" (
+    "id" SERIAL PRIMARY KEY,
+    "promo_code_id" integer NOT NULL REFERENCES "promo_codes" ("id") ON DELETE CASCADE,
+    "order_id" integer UNIQUE NOT NULL REFERENCES "orders" ("id") ON DELETE CASCADE,
+    "user_id" bigint NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT (current_timestamp)
+);
+
+CREATE INDEX "idx_promo_code_redemptions_code_user" ON "promo_code_redemptions" ("promo_code_id", "user_id");
+
+ALTER TABLE "orders" ADD COLUMN "promo_code" text;
+ALTER TABLE "orders" ADD COLUMN "discount_amount" numeric(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE "order_discounts" (
+    "id" SERIAL PRIMARY KEY,
+    "order_id" integer NOT NULL REFERENCES "orders" ("id") ON DELETE CASCADE,
+    "promo_code_id" integer REFERENCES "promo_codes" ("id") ON DELETE SET NULL,
+    "code" text NOT NULL,
+    "product_id" integer,
+    "variant_id" integer,
+    "amount" numeric(10,2) NOT NULL
+);
+
+CREATE INDEX "idx_order_discounts_order_id" ON "order_discounts" ("order_id");
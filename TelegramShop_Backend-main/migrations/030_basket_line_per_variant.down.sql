@@ -0,0 +1 @@
+ALTER TABLE "basket" DROP CONSTRAINT IF EXISTS "basket_quantity_positive";
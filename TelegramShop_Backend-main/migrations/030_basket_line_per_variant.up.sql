@@ -0,0 +1,2 @@
+DELETE FROM "basket" WHERE "quantity" <= 0;
+ALTER TABLE "basket" ADD CONSTRAINT "basket_quantity_positive" CHECK ("quantity" > 0);
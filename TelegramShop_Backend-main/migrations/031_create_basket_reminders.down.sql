@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "basket_reminders";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "basket_reminders_enabled";
+
+ALTER TABLE "basket" DROP COLUMN IF EXISTS "updated_at";
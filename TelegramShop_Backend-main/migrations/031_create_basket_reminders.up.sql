@@ -0,0 +1,14 @@
+ALTER TABLE "basket" ADD COLUMN "updated_at" timestamp;
+UPDATE "basket" SET "updated_at" = COALESCE("added_at", current_timestamp);
+ALTER TABLE "basket" ALTER COLUMN "updated_at" SET NOT NULL;
+ALTER TABLE "basket" ALTER COLUMN "updated_at" SET DEFAULT (current_timestamp);
+
+ALTER TABLE "users" ADD COLUMN "basket_reminders_enabled" boolean NOT NULL DEFAULT true;
+
+CREATE TABLE "basket_reminders" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" integer NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+    "basket_updated_at" timestamp NOT NULL,
+    "sent_at" timestamp NOT NULL DEFAULT (current_timestamp),
+    UNIQUE ("user_id", "basket_updated_at")
+);
@@ -0,0 +1 @@
+ALTER TABLE "payments" ADD COLUMN "mismatch" boolean NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+CREATE INDEX "idx_outbox_catalog_version" ON "outbox" ("id") WHERE "event_type" IN ('catalog.changed', 'product.updated');
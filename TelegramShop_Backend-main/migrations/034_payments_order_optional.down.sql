@@ -0,0 +1,2 @@
+DELETE FROM "payments" WHERE "order_id" IS NULL;
+ALTER TABLE "payments" ALTER COLUMN "order_id" SET NOT NULL;
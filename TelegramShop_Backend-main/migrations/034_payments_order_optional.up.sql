@@ -0,0 +1,2 @@
+-- Charges for orders that do not exist are kept for manual review without an order.
+ALTER TABLE "payments" ALTER COLUMN "order_id" DROP NOT NULL;
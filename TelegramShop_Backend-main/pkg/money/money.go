@@ -0,0 +1,15 @@
+// Package money holds the rounding every price, total and discount goes through, so the
+// shop, the stored orders and the Telegram invoices agree to the kopeck.
+package money
+
+import "math"
+
+// Round rounds amount to whole minor units (kopecks).
+func Round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// MinorUnits converts amount to whole minor units, the form Telegram invoices use.
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,20 @@
+package money_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/pkg/money"
+)
+
+func TestRound(t *testing.T) {
+	require.Equal(t, 270.75, money.Round(90.25*3))
+	require.Equal(t, 0.3, money.Round(0.1+0.2))
+}
+
+func TestMinorUnits(t *testing.T) {
+	require.Equal(t, int64(27050), money.MinorUnits(270.5))
+	require.Equal(t, int64(-1805), money.MinorUnits(-18.05))
+	require.Equal(t, int64(30), money.MinorUnits(0.1+0.2))
+}
@@ -0,0 +1,71 @@
+// Package pagination implements keyset pagination with opaque cursors.
+//
+// A cursor is the base64url-encoded JSON of whatever a repository needs to resume after the
+// last returned row, usually the sort value and the id. Clients must treat it as opaque.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("limit must be positive")
+)
+
+// Page asks for up to Limit items following Cursor; an empty Cursor starts from the beginning.
+type Page struct {
+	Cursor string
+	Limit  int
+}
+
+// NewPage validates the requested limit, using DefaultLimit for 0 and capping it at MaxLimit.
+func NewPage(cursor string, limit int) (Page, error) {
+	switch {
+	case limit < 0:
+		return Page{}, ErrInvalidLimit
+	case limit == 0:
+		limit = DefaultLimit
+	case limit > MaxLimit:
+		limit = MaxLimit
+	}
+	return Page{Cursor: cursor, Limit: limit}, nil
+}
+
+// Encode turns a position into a cursor.
+func Encode(position interface{}) string {
+	data, err := json.Marshal(position)
+	if err != nil {
+		// Positions are plain structs of numbers and strings.
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode reads the cursor into position. It reports false for an empty cursor.
+func Decode(cursor string, position interface{}) (bool, error) {
+	if cursor == "" {
+		return false, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return false, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, position); err != nil {
+		return false, ErrInvalidCursor
+	}
+	return true, nil
+}
+
+// IDPosition is the cursor position of listings ordered by id alone.
+type IDPosition struct {
+	ID int64 `json:"id"`
+}
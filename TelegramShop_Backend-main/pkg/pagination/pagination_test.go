@@ -0,0 +1,43 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/pkg/pagination"
+)
+
+func TestNewPage(t *testing.T) {
+	page, err := pagination.NewPage("", 0)
+	require.NoError(t, err)
+	require.Equal(t, pagination.DefaultLimit, page.Limit)
+
+	page, err = pagination.NewPage("abc", 1000)
+	require.NoError(t, err)
+	require.Equal(t, pagination.MaxLimit, page.Limit)
+	require.Equal(t, "abc", page.Cursor)
+
+	_, err = pagination.NewPage("", -1)
+	require.ErrorIs(t, err, pagination.ErrInvalidLimit)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := pagination.Encode(pagination.IDPosition{ID: 42})
+
+	var position pagination.IDPosition
+	ok, err := pagination.Decode(cursor, &position)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(42), position.ID)
+
+	ok, err = pagination.Decode("", &position)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = pagination.Decode("not a cursor!", &position)
+	require.ErrorIs(t, err, pagination.ErrInvalidCursor)
+
+	_, err = pagination.Decode(pagination.Encode("text"), &position)
+	require.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
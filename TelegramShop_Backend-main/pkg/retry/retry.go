@@ -0,0 +1,45 @@
+// Package retry holds the polling loop and the backoff shared by the workers that deliver
+// stored work and retry failed attempts later, such as the outbox and webhooks.
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff returns the delay before the next try after attempts tries. It starts at initial,
+// doubles with every attempt and is capped at max.
+func Backoff(initial, max time.Duration, attempts int) time.Duration {
+	delay := initial
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// Poll calls once until ctx is cancelled. While once claims a full batch it is called again
+// right away to drain the backlog; otherwise Poll waits for the next tick of interval.
+// Errors are passed to onError and the loop carries on.
+func Poll(ctx context.Context, interval time.Duration, batchSize int, once func(context.Context) (int, error), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := once(ctx)
+		if err != nil {
+			onError(err)
+		}
+		if err == nil && claimed == batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
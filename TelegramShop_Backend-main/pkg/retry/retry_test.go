@@ -0,0 +1,51 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/pkg/retry"
+)
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 5*time.Second, retry.Backoff(5*time.Second, time.Minute, 0))
+	require.Equal(t, 5*time.Second, retry.Backoff(5*time.Second, time.Minute, 1))
+	require.Equal(t, 10*time.Second, retry.Backoff(5*time.Second, time.Minute, 2))
+	require.Equal(t, 40*time.Second, retry.Backoff(5*time.Second, time.Minute, 4))
+	require.Equal(t, time.Minute, retry.Backoff(5*time.Second, time.Minute, 5))
+	require.Equal(t, time.Minute, retry.Backoff(5*time.Second, time.Minute, 1000))
+}
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Full batches are drained without waiting for the hour-long tick; the third call
+	// fails and the fourth, after the wait, must never come.
+	var calls int
+	var errs []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		retry.Poll(ctx, time.Hour, 2, func(context.Context) (int, error) {
+			calls++
+			if calls == 3 {
+				cancel()
+				return 0, errors.New("boom")
+			}
+			return 2, nil
+		}, func(err error) { errs = append(errs, err) })
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Poll did not stop after ctx was cancelled")
+	}
+	require.Equal(t, 3, calls)
+	require.Len(t, errs, 1)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Config addresses a bucket of any S3-compatible service (AWS, MinIO, Yandex Object Storage...).
+type S3Config struct {
+	// Endpoint is the service address, e.g. https://storage.yandexcloud.net.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is the address objects are served from. Defaults to Endpoint/Bucket.
+	PublicURL string
+}
+
+// S3 stores files in a bucket using path-style requests signed with Signature Version 4.
+type S3 struct {
+	publicURL
+	client *http.Client
+	cfg    S3Config
+	now    func() time.Time
+}
+
+func NewS3(client *http.Client, cfg S3Config) *S3 {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.Endpoint + "/" + cfg.Bucket
+	}
+	return &S3{publicURL: newPublicURL(cfg.PublicURL), client: client, cfg: cfg, now: time.Now}
+}
+
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.do(ctx, http.MethodPut, key, data, func(req *http.Request) {
+		req.Header.Set("Content-Type", contentType)
+		// Keys are content hashes, so an object never changes once written.
+		req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	})
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	// S3 answers 204 for missing keys as well.
+	return s.do(ctx, http.MethodDelete, key, nil, nil)
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body []byte, prepare func(*http.Request)) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Endpoint+"/"+s.cfg.Bucket+"/"+key, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+
+	sum := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(sum[:]), s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.cfg.Region, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && !(method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package storage keeps uploaded files behind one interface, so the shop can move
+// between a local directory and an S3-compatible bucket through configuration.
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidKey is returned for keys that could escape the storage root.
+var ErrInvalidKey = errors.New("invalid storage key")
+
+type Storage interface {
+	// Put stores data under key, replacing an existing object.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public address of key.
+	URL(key string) string
+	// Key returns the key behind a URL returned by URL, or false for any other URL.
+	Key(url string) (string, bool)
+}
+
+// publicURL maps keys to URLs under a base address and back.
+type publicURL struct {
+	base string
+}
+
+func newPublicURL(base string) publicURL {
+	return publicURL{base: strings.TrimRight(base, "/")}
+}
+
+func (p publicURL) URL(key string) string {
+	return p.base + "/" + key
+}
+
+func (p publicURL) Key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, p.base+"/")
+	if !ok || !ValidKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// ValidKey reports whether key is a relative slash-separated path made of lowercase
+// letters, digits, '.', '_' and '-' without empty, "." or ".." segments.
+func ValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+		for _, r := range segment {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
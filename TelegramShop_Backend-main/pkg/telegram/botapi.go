@@ -0,0 +1,196 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultAPIURL = "https://api.telegram.org"
+
+// BotAPI is the subset of the Telegram Bot API used by the shop.
+type BotAPI interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+	SendInvoice(ctx context.Context, chatID int64, invoice Invoice) error
+	CreateInvoiceLink(ctx context.Context, invoice Invoice) (string, error)
+	AnswerPreCheckoutQuery(ctx context.Context, queryID string, ok bool, errorMessage string) error
+}
+
+// LabeledPrice is a price portion in the smallest units of the currency.
+type LabeledPrice struct {
+	Label  string `json:"label"`
+	Amount int64  `json:"amount"`
+}
+
+// Invoice holds the fields shared by sendInvoice and createInvoiceLink.
+type Invoice struct {
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	Payload       string         `json:"payload"`
+	ProviderToken string         `json:"provider_token"`
+	Currency      string         `json:"currency"`
+	Prices        []LabeledPrice `json:"prices"`
+}
+
+type User struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username"`
+}
+
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+type SuccessfulPayment struct {
+	Currency                string `json:"currency"`
+	TotalAmount             int64  `json:"total_amount"`
+	InvoicePayload          string `json:"invoice_payload"`
+	TelegramPaymentChargeID string `json:"telegram_payment_charge_id"`
+	ProviderPaymentChargeID string `json:"provider_payment_charge_id"`
+}
+
+type Message struct {
+	MessageID         int64              `json:"message_id"`
+	From              *User              `json:"from"`
+	Chat              Chat               `json:"chat"`
+	Text              string             `json:"text"`
+	SuccessfulPayment *SuccessfulPayment `json:"successful_payment"`
+}
+
+type PreCheckoutQuery struct {
+	ID             string `json:"id"`
+	From           User   `json:"from"`
+	Currency       string `json:"currency"`
+	TotalAmount    int64  `json:"total_amount"`
+	InvoicePayload string `json:"invoice_payload"`
+}
+
+// Update is an incoming webhook update. Only the fields the shop reacts to are decoded.
+type Update struct {
+	UpdateID         int64             `json:"update_id"`
+	Message          *Message          `json:"message"`
+	PreCheckoutQuery *PreCheckoutQuery `json:"pre_checkout_query"`
+}
+
+// APIError is returned when the Bot API answers with ok=false.
+type APIError struct {
+	Code        int
+	Description string
+	// RetryAfter is set when the request was rate limited (error 429).
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+// Permanent reports whether sending the same request again cannot succeed, for example
+// because the user blocked the bot (403) or the chat does not exist (400).
+func (e *APIError) Permanent() bool {
+	return e.Code == http.StatusBadRequest || e.Code == http.StatusForbidden
+}
+
+type BotClient struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewBotClient creates a Bot API client. An empty baseURL means DefaultAPIURL;
+// tests point it at an httptest server.
+func NewBotClient(token, baseURL string) *BotClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &BotClient{
+		token:      token,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendMessage sends an HTML-formatted text message.
+func (c *BotClient) SendMessage(ctx context.Context, chatID int64, text string) error {
+	params := struct {
+		ChatID    int64  `json:"chat_id"`
+		Text      string `json:"text"`
+		ParseMode string `json:"parse_mode"`
+	}{ChatID: chatID, Text: text, ParseMode: "HTML"}
+
+	return c.call(ctx, "sendMessage", params, nil)
+}
+
+func (c *BotClient) SendInvoice(ctx context.Context, chatID int64, invoice Invoice) error {
+	params := struct {
+		ChatID int64 `json:"chat_id"`
+		Invoice
+	}{ChatID: chatID, Invoice: invoice}
+
+	return c.call(ctx, "sendInvoice", params, nil)
+}
+
+func (c *BotClient) CreateInvoiceLink(ctx context.Context, invoice Invoice) (string, error) {
+	var link string
+	err := c.call(ctx, "createInvoiceLink", invoice, &link)
+	return link, err
+}
+
+func (c *BotClient) AnswerPreCheckoutQuery(ctx context.Context, queryID string, ok bool, errorMessage string) error {
+	params := struct {
+		PreCheckoutQueryID string `json:"pre_checkout_query_id"`
+		OK                 bool   `json:"ok"`
+		ErrorMessage       string `json:"error_message,omitempty"`
+	}{PreCheckoutQueryID: queryID, OK: ok, ErrorMessage: errorMessage}
+
+	return c.call(ctx, "answerPreCheckoutQuery", params, nil)
+}
+
+// call posts params as JSON to the given method and decodes the "result" field into result, if set.
+func (c *BotClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var apiResp struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("%s: decode response: %w", method, err)
+	}
+	if !apiResp.OK {
+		return &APIError{
+			Code:        apiResp.ErrorCode,
+			Description: apiResp.Description,
+			RetryAfter:  time.Duration(apiResp.Parameters.RetryAfter) * time.Second,
+		}
+	}
+
+	if result != nil {
+		return json.Unmarshal(apiResp.Result, result)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package telegram_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/pkg/telegram"
+)
+
+func TestBotClient(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+
+		switch r.URL.Path {
+		case "/bot" + testBotToken + "/createInvoiceLink":
+			w.Write([]byte(`{"ok":true,"result":"https://t.me/$invoice"}`))
+		case "/bot" + testBotToken + "/answerPreCheckoutQuery":
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: query is too old"}`))
+		default:
+			w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	}))
+	defer server.Close()
+
+	client := telegram.NewBotClient(testBotToken, server.URL)
+	ctx := context.Background()
+	invoice := telegram.Invoice{
+		Title:    "Order #1",
+		Payload:  "order:1",
+		Currency: "RUB",
+		Prices:   []telegram.LabeledPrice{{Label: "Tea", Amount: 12050}},
+	}
+
+	t.Run("CreateInvoiceLink", func(t *testing.T) {
+		link, err := client.CreateInvoiceLink(ctx, invoice)
+		require.NoError(t, err)
+		require.Equal(t, "https://t.me/$invoice", link)
+		require.Equal(t, "order:1", gotBody["payload"])
+	})
+
+	t.Run("SendInvoice", func(t *testing.T) {
+		require.NoError(t, client.SendInvoice(ctx, 42, invoice))
+		require.Equal(t, "/bot"+testBotToken+"/sendInvoice", gotPath)
+		require.Equal(t, float64(42), gotBody["chat_id"])
+		require.Equal(t, "RUB", gotBody["currency"])
+	})
+
+	t.Run("APIError", func(t *testing.T) {
+		err := client.AnswerPreCheckoutQuery(ctx, "q1", true, "")
+		var apiErr *telegram.APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, 400, apiErr.Code)
+		require.Equal(t, "q1", gotBody["pre_checkout_query_id"])
+	})
+}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInitDataEmpty       = errors.New("init data is empty")
+	ErrInitDataHashMissing = errors.New("init data hash is missing")
+	ErrInitDataHashInvalid = errors.New("init data hash is invalid")
+	ErrInitDataExpired     = errors.New("init data is expired")
+	ErrInitDataNoUser      = errors.New("init data has no user")
+)
+
+// WebAppUser is the user object passed by Telegram in Telegram.WebApp.initData.
+type WebAppUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+type InitData struct {
+	QueryID  string
+	User     WebAppUser
+	AuthDate time.Time
+}
+
+// ValidateInitData checks the initData signature against the bot token and,
+// when maxAge is positive, rejects data older than maxAge.
+// See https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func ValidateInitData(raw string, botToken string, maxAge time.Duration) (InitData, error) {
+	if raw == "" {
+		return InitData{}, ErrInitDataEmpty
+	}
+
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return InitData{}, err
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return InitData{}, ErrInitDataHashMissing
+	}
+
+	expected := SignInitData(values, botToken)
+	if !hmac.Equal([]byte(hash), []byte(expected)) {
+		return InitData{}, ErrInitDataHashInvalid
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return InitData{}, ErrInitDataHashInvalid
+	}
+
+	data := InitData{
+		QueryID:  values.Get("query_id"),
+		AuthDate: time.Unix(authDate, 0),
+	}
+
+	if maxAge > 0 && time.Since(data.AuthDate) > maxAge {
+		return InitData{}, ErrInitDataExpired
+	}
+
+	rawUser := values.Get("user")
+	if rawUser == "" {
+		return InitData{}, ErrInitDataNoUser
+	}
+	if err := json.Unmarshal([]byte(rawUser), &data.User); err != nil {
+		return InitData{}, err
+	}
+	if data.User.ID == 0 {
+		return InitData{}, ErrInitDataNoUser
+	}
+
+	return data, nil
+}
+
+// SignInitData computes the hash Telegram puts into initData for the given values.
+// The "hash" key itself is ignored.
+func SignInitData(values url.Values, botToken string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k == "hash" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+values.Get(k))
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package telegram_test
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"telegramshop_backend/pkg/telegram"
+)
+
+const testBotToken = "123456:TEST-bot-token"
+
+func signedInitData(t *testing.T, token string, authDate time.Time, user string) string {
+	t.Helper()
+	values := url.Values{}
+	values.Set("query_id", "AAHdF6IQAAAAAN0XohDhrOrc")
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	if user != "" {
+		values.Set("user", user)
+	}
+	values.Set("hash", telegram.SignInitData(values, token))
+	return values.Encode()
+}
+
+func TestValidateInitData(t *testing.T) {
+	user := `{"id":42,"first_name":"Ivan","username":"ivan"}`
+
+	t.Run("Valid", func(t *testing.T) {
+		raw := signedInitData(t, testBotToken, time.Now(), user)
+
+		data, err := telegram.ValidateInitData(raw, testBotToken, time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, int64(42), data.User.ID)
+		require.Equal(t, "ivan", data.User.Username)
+	})
+
+	t.Run("WrongToken", func(t *testing.T) {
+		raw := signedInitData(t, "other:token", time.Now(), user)
+
+		_, err := telegram.ValidateInitData(raw, testBotToken, time.Hour)
+		require.ErrorIs(t, err, telegram.ErrInitDataHashInvalid)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		values, err := url.ParseQuery(signedInitData(t, testBotToken, time.Now(), user))
+		require.NoError(t, err)
+		values.Set("user", `{"id":43,"first_name":"Eve"}`)
+
+		_, err = telegram.ValidateInitData(values.Encode(), testBotToken, time.Hour)
+		require.ErrorIs(t, err, telegram.ErrInitDataHashInvalid)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		raw := signedInitData(t, testBotToken, time.Now().Add(-2*time.Hour), user)
+
+		_, err := telegram.ValidateInitData(raw, testBotToken, time.Hour)
+		require.ErrorIs(t, err, telegram.ErrInitDataExpired)
+	})
+
+	t.Run("MissingHash", func(t *testing.T) {
+		_, err := telegram.ValidateInitData("auth_date=1&user=%7B%7D", testBotToken, time.Hour)
+		require.ErrorIs(t, err, telegram.ErrInitDataHashMissing)
+	})
+
+	t.Run("NoUser", func(t *testing.T) {
+		raw := signedInitData(t, testBotToken, time.Now(), "")
+
+		_, err := telegram.ValidateInitData(raw, testBotToken, time.Hour)
+		require.ErrorIs(t, err, telegram.ErrInitDataNoUser)
+	})
+}
@@ -3,6 +3,8 @@ package web
 type Response struct {
 	Status string      `json:"status"`
 	Data   interface{} `json:"data,omitempty"`
+	// NextCursor is set on paginated listings that have more items.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func OkResp(status string, data interface{}) Response {
@@ -12,6 +14,15 @@ func OkResp(status string, data interface{}) Response {
 	}
 }
 
+// PageResp is OkResp for one page of a listing.
+func PageResp(status string, data interface{}, nextCursor string) Response {
+	return Response{
+		Status:     status,
+		Data:       data,
+		NextCursor: nextCursor,
+	}
+}
+
 func ErrorResp(status string, data interface{}) Response {
 	return Response{
 		Status: status,